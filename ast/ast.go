@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
 	"strings"
 
 	"github.com/joshuahenriques/cixac/token"
@@ -52,6 +53,7 @@ func (p *Program) String() string {
 type LetStatement struct {
 	Token token.Token // the token.LET token
 	Name  *Identifier
+	Type  TypeExpr // nil when not annotated
 	Value Expression
 }
 
@@ -62,6 +64,9 @@ func (ls *LetStatement) String() string {
 
 	out.WriteString(ls.TokenLiteral() + " ")
 	out.WriteString(ls.Name.String())
+	if ls.Type != nil {
+		out.WriteString(": " + ls.Type.String())
+	}
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
@@ -128,6 +133,15 @@ func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
+type BigIntegerLiteral struct {
+	Token token.Token
+	Value *big.Int
+}
+
+func (bl *BigIntegerLiteral) expressionNode()      {}
+func (bl *BigIntegerLiteral) TokenLiteral() string { return bl.Token.Literal }
+func (bl *BigIntegerLiteral) String() string       { return bl.Token.Literal }
+
 type FloatLiteral struct {
 	Token token.Token
 	Value float64
@@ -137,6 +151,15 @@ func (fl *FloatLiteral) expressionNode()      {}
 func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
 func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
 
+type DecimalLiteral struct {
+	Token token.Token
+	Value string // the digits without the d suffix
+}
+
+func (dl *DecimalLiteral) expressionNode()      {}
+func (dl *DecimalLiteral) TokenLiteral() string { return dl.Token.Literal }
+func (dl *DecimalLiteral) String() string       { return dl.Token.Literal }
+
 type PrefixExpression struct {
 	Token    token.Token // The prefix token, e.g. !
 	Operator string
@@ -196,6 +219,18 @@ func (ie *InfixExpression) String() string {
 	return out.String()
 }
 
+type IsExpression struct {
+	Token token.Token // the is token
+	Left  Expression
+	Type  TypeExpr
+}
+
+func (ie *IsExpression) expressionNode()      {}
+func (ie *IsExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IsExpression) String() string {
+	return "(" + ie.Left.String() + " is " + ie.Type.String() + ")"
+}
+
 type Null struct {
 	Token token.Token
 	Value any
@@ -356,9 +391,31 @@ func (cs *ContinueStatement) String() string {
 	return out.String()
 }
 
+type DeferStatement struct {
+	Token token.Token // the defer token
+	Call  Expression  // *CallExpression | *BuiltinExpression
+}
+
+func (ds *DeferStatement) statementNode()       {}
+func (ds *DeferStatement) TokenLiteral() string { return ds.Token.Literal }
+func (ds *DeferStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ds.TokenLiteral() + " ")
+
+	if ds.Call != nil {
+		out.WriteString(ds.Call.String())
+	}
+
+	out.WriteString(";")
+
+	return out.String()
+}
+
 type BlockStatement struct {
 	Token      token.Token // the { token
 	Statements []Statement
+	End        token.Token // the } token
 }
 
 func (bs *BlockStatement) statementNode()       {}
@@ -376,7 +433,11 @@ func (bs *BlockStatement) String() string {
 type FunctionLiteral struct {
 	Token      token.Token // The 'fn' token
 	Parameters []*Identifier
-	Body       *BlockStatement
+	// the annotations of the parameters, nil when none is annotated and
+	// otherwise one per parameter with nil for those that aren't
+	ParameterTypes []TypeExpr
+	ReturnType     TypeExpr
+	Body           *BlockStatement
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
@@ -384,20 +445,33 @@ func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
-	params := []string{}
-	for _, p := range fl.Parameters {
-		params = append(params, p.String())
-	}
-
 	out.WriteString(fl.TokenLiteral())
 	out.WriteString("(")
-	out.WriteString(strings.Join(params, ", "))
-	out.WriteString(") ")
+	out.WriteString(fl.signature())
 	out.WriteString(fl.Body.String())
 
 	return out.String()
 }
 
+// signature is the annotated parameter list after the ( up to the body
+func (fl *FunctionLiteral) signature() string {
+	params := []string{}
+	for i, p := range fl.Parameters {
+		param := p.String()
+		if i < len(fl.ParameterTypes) && fl.ParameterTypes[i] != nil {
+			param += ": " + fl.ParameterTypes[i].String()
+		}
+		params = append(params, param)
+	}
+
+	out := strings.Join(params, ", ") + ") "
+	if fl.ReturnType != nil {
+		out += "-> " + fl.ReturnType.String() + " "
+	}
+
+	return out
+}
+
 type CallExpression struct {
 	Token     token.Token // The '(' token
 	Function  Expression  // Identifier or FunctionLiteral
@@ -476,6 +550,26 @@ func (ie *BuiltinExpression) String() string {
 	return out.String()
 }
 
+type PropertyExpression struct {
+	Token    token.Token // the . token
+	Left     Expression
+	Property *Identifier
+}
+
+func (pe *PropertyExpression) expressionNode()      {}
+func (pe *PropertyExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PropertyExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(pe.Left.String())
+	out.WriteString(".")
+	out.WriteString(pe.Property.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
 type IndexExpression struct {
 	Token token.Token // the [ token
 	Left  Expression
@@ -553,15 +647,9 @@ func (fd *FunctionDeclaration) TokenLiteral() string { return fd.Token.Literal }
 func (fd *FunctionDeclaration) String() string {
 	var out bytes.Buffer
 
-	params := []string{}
-	for _, p := range fd.Function.Parameters {
-		params = append(params, p.String())
-	}
-
 	out.WriteString(fd.Function.TokenLiteral())
 	out.WriteString("(")
-	out.WriteString(strings.Join(params, ", "))
-	out.WriteString(") ")
+	out.WriteString(fd.Function.signature())
 	out.WriteString(fd.Function.Body.String())
 
 	return out.String()
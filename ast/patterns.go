@@ -0,0 +1,127 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/joshuahenriques/cixac/token"
+)
+
+// Pattern is what a match arm compares a value with: a literal, `_`, a name
+// that binds the value, `[first, ...rest]` or `{"key": pattern}`.
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+// LiteralPattern matches values equal to a number, string, bool or null
+type LiteralPattern struct {
+	Token token.Token // the first token of the literal
+	Value Expression  // a literal, or a prefix expression for a negative number
+}
+
+func (lp *LiteralPattern) patternNode()         {}
+func (lp *LiteralPattern) TokenLiteral() string { return lp.Token.Literal }
+func (lp *LiteralPattern) String() string       { return lp.Value.String() }
+
+// WildcardPattern is `_`, it matches anything and binds nothing
+type WildcardPattern struct {
+	Token token.Token // the _ token
+}
+
+func (wp *WildcardPattern) patternNode()         {}
+func (wp *WildcardPattern) TokenLiteral() string { return wp.Token.Literal }
+func (wp *WildcardPattern) String() string       { return "_" }
+
+// BindingPattern matches anything and binds it to a name in the arm
+type BindingPattern struct {
+	Token token.Token // the name token
+	Name  *Identifier
+}
+
+func (bp *BindingPattern) patternNode()         {}
+func (bp *BindingPattern) TokenLiteral() string { return bp.Token.Literal }
+func (bp *BindingPattern) String() string       { return bp.Name.String() }
+
+// ArrayPattern matches arrays whose elements match Elements. Without a rest
+// element the array must have exactly as many elements, with one it may have
+// more, which the rest element matches as an array.
+type ArrayPattern struct {
+	Token    token.Token // the [ token
+	Elements []Pattern
+	Rest     Pattern // a BindingPattern or, for `...` and `..._`, a WildcardPattern; nil without a rest element
+}
+
+func (ap *ArrayPattern) patternNode()         {}
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *ArrayPattern) String() string {
+	elements := []string{}
+	for _, e := range ap.Elements {
+		elements = append(elements, e.String())
+	}
+	if ap.Rest != nil {
+		rest := "..."
+		if ap.Rest.TokenLiteral() != rest {
+			rest += ap.Rest.String()
+		}
+		elements = append(elements, rest)
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// HashPattern matches hashes that have each of Keys with a value matching
+// the pattern at the same index of Values. Other keys are ignored.
+type HashPattern struct {
+	Token  token.Token // the { token
+	Keys   []Expression
+	Values []Pattern
+}
+
+func (hp *HashPattern) patternNode()         {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) String() string {
+	pairs := []string{}
+	for i, key := range hp.Keys {
+		pairs = append(pairs, key.String()+": "+hp.Values[i].String())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+type MatchArm struct {
+	Pattern Pattern
+	Guard   Expression // the condition after if, nil without one
+	Body    *BlockStatement
+}
+
+// MatchExpression evaluates to the body of the first arm whose pattern
+// matches Value and whose guard, if any, is true
+type MatchExpression struct {
+	Token token.Token // the 'match' token
+	Value Expression
+	Arms  []MatchArm
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+
+	arms := []string{}
+	for _, arm := range me.Arms {
+		s := arm.Pattern.String()
+		if arm.Guard != nil {
+			s += " if " + arm.Guard.String()
+		}
+		arms = append(arms, s+" => "+arm.Body.String())
+	}
+
+	out.WriteString("match ")
+	out.WriteString(me.Value.String())
+	if len(arms) == 0 {
+		out.WriteString(" {}")
+	} else {
+		out.WriteString(" { " + strings.Join(arms, ", ") + " }")
+	}
+
+	return out.String()
+}
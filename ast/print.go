@@ -0,0 +1,140 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/joshuahenriques/cixac/token"
+)
+
+var (
+	nodeType  = reflect.TypeOf((*Node)(nil)).Elem()
+	tokenType = reflect.TypeOf(token.Token{})
+	bigType   = reflect.TypeOf(&big.Int{})
+)
+
+// Fprint writes the tree of node to w, a node per line indented under its
+// parent. A line names the field holding the node, its type, the plain
+// values it holds and the position of its token.
+func Fprint(w io.Writer, node Node) {
+	printNode(w, "", reflect.ValueOf(node), 0)
+}
+
+func printNode(w io.Writer, label string, v reflect.Value, depth int) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return
+	}
+
+	indent := strings.Repeat("  ", depth)
+	if label != "" {
+		label += ": "
+	}
+
+	s := v
+	if s.Kind() == reflect.Pointer {
+		s = s.Elem()
+	}
+
+	header := s.Type().Name()
+	var children []func()
+
+	for i := 0; i < s.NumField(); i++ {
+		field, value := s.Type().Field(i), s.Field(i)
+
+		switch {
+		case field.Type == tokenType:
+			tok := value.Interface().(token.Token)
+			// the operator of a reassignment is only in its token
+			if _, ok := v.Interface().(*ReassignStatement); ok && field.Name == "Token" {
+				header += fmt.Sprintf(" Operator=%q", tok.Literal)
+			}
+		case field.Type == bigType:
+			if !value.IsNil() {
+				header += fmt.Sprintf(" %s=%s", field.Name, value.Interface())
+			}
+		case isBasic(value.Kind()):
+			if !value.IsZero() || field.Name == "Value" {
+				header += fmt.Sprintf(" %s=%s", field.Name, formatBasic(value))
+			}
+		default:
+			name := field.Name
+			children = append(children, func() { printChild(w, name, value, depth+1) })
+		}
+	}
+
+	if tok, ok := startToken(s); ok {
+		header += fmt.Sprintf(" (%d:%d)", tok.Line, tok.Column)
+	}
+
+	fmt.Fprintf(w, "%s%s%s\n", indent, label, header)
+	for _, child := range children {
+		child()
+	}
+}
+
+func printChild(w io.Writer, name string, v reflect.Value, depth int) {
+	switch v.Kind() {
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			printChild(w, fmt.Sprintf("%s[%d]", name, i), v.Index(i), depth)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return before(keys[i], keys[j]) })
+		for _, key := range keys {
+			printNode(w, name+" key", key, depth)
+			printNode(w, name+" value", v.MapIndex(key), depth)
+		}
+	case reflect.Struct:
+		// a part of a node that isn't a node itself, such as an IfCondition
+		fmt.Fprintf(w, "%s%s: %s\n", strings.Repeat("  ", depth), name, v.Type().Name())
+		for i := 0; i < v.NumField(); i++ {
+			printChild(w, v.Type().Field(i).Name, v.Field(i), depth+1)
+		}
+	default:
+		if v.Type().Implements(nodeType) || v.Type() == nodeType || v.Kind() == reflect.Interface {
+			printNode(w, name, v, depth)
+		}
+	}
+}
+
+func isBasic(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func formatBasic(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return fmt.Sprintf("%q", v.String())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func startToken(s reflect.Value) (token.Token, bool) {
+	field := s.FieldByName("Token")
+	if !field.IsValid() || field.Type() != tokenType {
+		return token.Token{}, false
+	}
+	tok := field.Interface().(token.Token)
+	return tok, tok.Line > 0
+}
+
+// before orders hash literal keys by where they start
+func before(a, b reflect.Value) bool {
+	ta, _ := startToken(reflect.Indirect(a.Elem()))
+	tb, _ := startToken(reflect.Indirect(b.Elem()))
+	if ta.Line != tb.Line {
+		return ta.Line < tb.Line
+	}
+	return ta.Column < tb.Column
+}
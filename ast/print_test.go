@@ -0,0 +1,67 @@
+package ast_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/parser"
+)
+
+func TestFprint(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1 + 2", `Program
+  Statements[0]: LetStatement (1:1)
+    Name: Identifier Value="x" (1:5)
+    Value: InfixExpression Operator="+" (1:11)
+      Left: IntegerLiteral Value=1 (1:9)
+      Right: IntegerLiteral Value=2 (1:13)
+`},
+		{"x += 2", `Program
+  Statements[0]: ReassignStatement Operator="+=" (1:3)
+    Name: Identifier Value="x" (1:1)
+    Value: IntegerLiteral Value=2 (1:6)
+`},
+		{`if (a) { f("s") } else { [] }`, `Program
+  Statements[0]: ExpressionStatement (1:1)
+    Expression: IfExpression (1:1)
+      Conditions[0]: IfCondition
+        Condition: Identifier Value="a" (1:5)
+        Consequence: BlockStatement (1:8)
+          Statements[0]: ExpressionStatement (1:10)
+            Expression: CallExpression (1:11)
+              Function: Identifier Value="f" (1:10)
+              Arguments[0]: StringLiteral Value="s" (1:12)
+      Alternative: BlockStatement (1:24)
+        Statements[0]: ExpressionStatement (1:26)
+          Expression: ArrayLiteral (1:26)
+`},
+		{`{"b": 1, "a": true}`, `Program
+  Statements[0]: ExpressionStatement (1:1)
+    Expression: HashLiteral (1:1)
+      Pairs key: StringLiteral Value="b" (1:2)
+      Pairs value: IntegerLiteral Value=1 (1:7)
+      Pairs key: StringLiteral Value="a" (1:10)
+      Pairs value: Boolean Value=true (1:15)
+`},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parse errors: %v", p.Errors())
+		}
+
+		var out bytes.Buffer
+		ast.Fprint(&out, program)
+
+		if out.String() != tt.expected {
+			t.Errorf("Fprint(%q) wrong.\nexpected=\n%s\ngot=\n%s", tt.input, tt.expected, out.String())
+		}
+	}
+}
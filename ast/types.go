@@ -0,0 +1,89 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/joshuahenriques/cixac/token"
+)
+
+// TypeExpr is a type annotation such as `int`, `[string]`, `{string: int}`,
+// `fn(int) -> bool` or `int | null`. The evaluator ignores annotations,
+// they are only read by the type checker.
+type TypeExpr interface {
+	Node
+	typeNode()
+}
+
+type NamedType struct {
+	Token token.Token // the name token
+	Name  string
+}
+
+func (nt *NamedType) typeNode()            {}
+func (nt *NamedType) TokenLiteral() string { return nt.Token.Literal }
+func (nt *NamedType) String() string       { return nt.Name }
+
+type ArrayType struct {
+	Token   token.Token // the [ token
+	Element TypeExpr
+}
+
+func (at *ArrayType) typeNode()            {}
+func (at *ArrayType) TokenLiteral() string { return at.Token.Literal }
+func (at *ArrayType) String() string       { return "[" + typeString(at.Element) + "]" }
+
+type HashType struct {
+	Token token.Token // the { token
+	Key   TypeExpr
+	Value TypeExpr
+}
+
+func (ht *HashType) typeNode()            {}
+func (ht *HashType) TokenLiteral() string { return ht.Token.Literal }
+func (ht *HashType) String() string {
+	return "{" + typeString(ht.Key) + ": " + typeString(ht.Value) + "}"
+}
+
+type FunctionType struct {
+	Token      token.Token // the 'fn' token
+	Parameters []TypeExpr
+	Return     TypeExpr // nil when the return type isn't given
+}
+
+func (ft *FunctionType) typeNode()            {}
+func (ft *FunctionType) TokenLiteral() string { return ft.Token.Literal }
+func (ft *FunctionType) String() string {
+	params := []string{}
+	for _, p := range ft.Parameters {
+		params = append(params, typeString(p))
+	}
+
+	out := "fn(" + strings.Join(params, ", ") + ")"
+	if ft.Return != nil {
+		out += " -> " + typeString(ft.Return)
+	}
+
+	return out
+}
+
+type UnionType struct {
+	Token token.Token // the first | token
+	Types []TypeExpr
+}
+
+func (ut *UnionType) typeNode()            {}
+func (ut *UnionType) TokenLiteral() string { return ut.Token.Literal }
+func (ut *UnionType) String() string {
+	types := []string{}
+	for _, t := range ut.Types {
+		types = append(types, typeString(t))
+	}
+	return strings.Join(types, " | ")
+}
+
+func typeString(t TypeExpr) string {
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
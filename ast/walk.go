@@ -0,0 +1,177 @@
+package ast
+
+import (
+	"reflect"
+
+	"github.com/joshuahenriques/cixac/token"
+)
+
+// Inspect traverses the tree in depth-first order, calling f for each node.
+// Children of a node are skipped when f returns false. Missing nodes, which
+// the parser can leave behind after an error, are not visited
+func Inspect(node Node, f func(Node) bool) {
+	if node == nil {
+		return
+	}
+	if v := reflect.ValueOf(node); v.Kind() == reflect.Pointer && v.IsNil() {
+		return
+	}
+
+	if !f(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		for _, stmt := range n.Statements {
+			Inspect(stmt, f)
+		}
+	case *BlockStatement:
+		for _, stmt := range n.Statements {
+			Inspect(stmt, f)
+		}
+	case *LetStatement:
+		Inspect(n.Name, f)
+		Inspect(n.Type, f)
+		Inspect(n.Value, f)
+	case *ReassignStatement:
+		Inspect(n.Name, f)
+		Inspect(n.Value, f)
+	case *ReturnStatement:
+		Inspect(n.ReturnValue, f)
+	case *ExpressionStatement:
+		Inspect(n.Expression, f)
+	case *DeferStatement:
+		Inspect(n.Call, f)
+	case *WhileStatement:
+		Inspect(n.Condition, f)
+		Inspect(n.Body, f)
+	case *ForLoopStatement:
+		Inspect(n.Initialization, f)
+		Inspect(n.Condition, f)
+		Inspect(n.Update, f)
+		Inspect(n.Body, f)
+	case *ForInLoopStatement:
+		Inspect(n.KeyIndex, f)
+		Inspect(n.ValueElement, f)
+		Inspect(n.Iterable, f)
+		Inspect(n.Body, f)
+	case *FunctionDeclaration:
+		Inspect(n.Name, f)
+		Inspect(n.Function, f)
+	case *FunctionLiteral:
+		for i, param := range n.Parameters {
+			Inspect(param, f)
+			if i < len(n.ParameterTypes) {
+				Inspect(n.ParameterTypes[i], f)
+			}
+		}
+		Inspect(n.ReturnType, f)
+		Inspect(n.Body, f)
+	case *ArrayType:
+		Inspect(n.Element, f)
+	case *HashType:
+		Inspect(n.Key, f)
+		Inspect(n.Value, f)
+	case *FunctionType:
+		for _, param := range n.Parameters {
+			Inspect(param, f)
+		}
+		Inspect(n.Return, f)
+	case *UnionType:
+		for _, t := range n.Types {
+			Inspect(t, f)
+		}
+	case *PrefixExpression:
+		Inspect(n.Right, f)
+	case *PostfixExpression:
+		Inspect(n.Left, f)
+	case *InfixExpression:
+		Inspect(n.Left, f)
+		Inspect(n.Right, f)
+	case *IsExpression:
+		Inspect(n.Left, f)
+		Inspect(n.Type, f)
+	case *IfExpression:
+		for _, cond := range n.Conditions {
+			Inspect(cond.Condition, f)
+			Inspect(cond.Consequence, f)
+		}
+		Inspect(n.Alternative, f)
+	case *MatchExpression:
+		Inspect(n.Value, f)
+		for _, arm := range n.Arms {
+			Inspect(arm.Pattern, f)
+			Inspect(arm.Guard, f)
+			Inspect(arm.Body, f)
+		}
+	case *LiteralPattern:
+		Inspect(n.Value, f)
+	case *BindingPattern:
+		Inspect(n.Name, f)
+	case *ArrayPattern:
+		for _, e := range n.Elements {
+			Inspect(e, f)
+		}
+		Inspect(n.Rest, f)
+	case *HashPattern:
+		for i, key := range n.Keys {
+			Inspect(key, f)
+			Inspect(n.Values[i], f)
+		}
+	case *CallExpression:
+		Inspect(n.Function, f)
+		for _, arg := range n.Arguments {
+			Inspect(arg, f)
+		}
+	case *BuiltinExpression:
+		Inspect(n.Left, f)
+		Inspect(n.Builtin, f)
+	case *PropertyExpression:
+		Inspect(n.Left, f)
+		Inspect(n.Property, f)
+	case *IndexExpression:
+		Inspect(n.Left, f)
+		Inspect(n.Index, f)
+	case *ArrayLiteral:
+		for _, ele := range n.Elements {
+			Inspect(ele, f)
+		}
+	case *HashLiteral:
+		for key, value := range n.Pairs {
+			Inspect(key, f)
+			Inspect(value, f)
+		}
+	}
+}
+
+// Start returns the first token of a statement
+func Start(stmt Statement) token.Token {
+	switch s := stmt.(type) {
+	case *LetStatement:
+		return s.Token
+	case *ReassignStatement:
+		return s.Name.Token
+	case *ReturnStatement:
+		return s.Token
+	case *ExpressionStatement:
+		return s.Token
+	case *FunctionDeclaration:
+		return s.Token
+	case *WhileStatement:
+		return s.Token
+	case *ForLoopStatement:
+		return s.Token
+	case *ForInLoopStatement:
+		return s.Token
+	case *BreakStatement:
+		return s.Token
+	case *ContinueStatement:
+		return s.Token
+	case *DeferStatement:
+		return s.Token
+	case *BlockStatement:
+		return s.Token
+	}
+	return token.Token{}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/joshuahenriques/cixac/typecheck"
+)
+
+type checkIssue struct {
+	File string `json:"file"`
+	typecheck.Issue
+}
+
+// checkMain runs `cixac check [-format text|json] files...`. It returns 1
+// when there are type mismatches and 2 when a file can't be read or parsed.
+func checkMain(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	outFormat := flags.String("format", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return exitStatus(err)
+	}
+
+	if *outFormat != "text" && *outFormat != "json" {
+		fmt.Fprintf(os.Stderr, "check: unknown format %q\n", *outFormat)
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "check: no files to check")
+		flags.Usage()
+		return 2
+	}
+
+	status := 0
+	issues := []checkIssue{}
+
+	for _, name := range flags.Args() {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "check: %s\n", err)
+			status = 2
+			continue
+		}
+
+		found, err := typecheck.Source(string(src))
+		if err != nil {
+			printFileError(os.Stderr, name, err)
+			status = 2
+			continue
+		}
+
+		for _, issue := range found {
+			issues = append(issues, checkIssue{File: name, Issue: issue})
+		}
+	}
+
+	if *outFormat == "json" {
+		out, _ := json.MarshalIndent(issues, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		for _, issue := range issues {
+			fmt.Printf("%s:%s\n", issue.File, issue.Issue)
+		}
+	}
+
+	if status == 0 && len(issues) != 0 {
+		status = 1
+	}
+
+	return status
+}
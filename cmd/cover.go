@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/cover"
+	"github.com/joshuahenriques/cixac/evaluator"
+)
+
+// startCoverage counts the coverage of the program when enabled. The returned
+// func stops counting, prints the summary to stderr and writes lcov.info and
+// coverage.html to dir.
+func startCoverage(enabled bool, dir, name, code string, program *ast.Program) func() {
+	if !enabled {
+		return func() {}
+	}
+
+	c := evaluator.StartCoverage(program)
+
+	return func() {
+		evaluator.StopCoverage()
+
+		files := []cover.File{{Name: name, Source: code, Coverage: c}}
+		cover.WriteSummary(os.Stderr, files)
+
+		lcovPath := filepath.Join(dir, "lcov.info")
+		htmlPath := filepath.Join(dir, "coverage.html")
+
+		writeCoverage(lcovPath, func(w io.Writer) error { return cover.WriteLCOV(w, files) })
+		writeCoverage(htmlPath, func(w io.Writer) error { return cover.WriteHTML(w, files) })
+	}
+}
+
+func writeCoverage(path string, write func(io.Writer) error) {
+	f, err := os.Create(path)
+	if err == nil {
+		err = write(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cover: %s\n", err)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/joshuahenriques/cixac/format"
+)
+
+// fmtMain runs `cixac fmt [-w] files...`, formatting stdin when no files are
+// given. It returns 1 when any file fails to format.
+func fmtMain(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	write := flags.Bool("w", false, "write the result to the file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fmt: %s\n", err)
+			return 1
+		}
+		return formatFile("<stdin>", src, false)
+	}
+
+	status := 0
+	for _, name := range flags.Args() {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fmt: %s\n", err)
+			status = 1
+			continue
+		}
+		if formatFile(name, src, *write) != 0 {
+			status = 1
+		}
+	}
+
+	return status
+}
+
+func formatFile(name string, src []byte, write bool) int {
+	out, err := format.Source(src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+		return 1
+	}
+
+	if !write {
+		os.Stdout.Write(out)
+		return 0
+	}
+
+	if bytes.Equal(src, out) {
+		return 0
+	}
+
+	if err := os.WriteFile(name, out, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "fmt: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
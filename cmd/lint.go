@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/joshuahenriques/cixac/lint"
+)
+
+const lintConfigFile = ".cixaclint.json"
+
+type lintIssue struct {
+	File string `json:"file"`
+	lint.Issue
+}
+
+// lintMain runs `cixac lint [-config file] [-format text|json] files...`. It
+// returns 1 when there are issues and 2 when a file or the config can't be
+// read or parsed.
+func lintMain(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	configPath := flags.String("config", lintConfigFile, "JSON file enabling or disabling rules")
+	outFormat := flags.String("format", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *outFormat != "text" && *outFormat != "json" {
+		fmt.Fprintf(os.Stderr, "lint: unknown format %q\n", *outFormat)
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "lint: no files to lint")
+		flags.Usage()
+		return 2
+	}
+
+	config, err := lint.LoadConfig(*configPath)
+	if err != nil && !(errors.Is(err, fs.ErrNotExist) && *configPath == lintConfigFile) {
+		fmt.Fprintf(os.Stderr, "lint: %s\n", err)
+		return 2
+	}
+
+	status := 0
+	issues := []lintIssue{}
+
+	for _, name := range flags.Args() {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "lint: %s\n", err)
+			status = 2
+			continue
+		}
+
+		found, err := lint.Source(string(src), config)
+		if err != nil {
+			printFileError(os.Stderr, name, err)
+			status = 2
+			continue
+		}
+
+		for _, issue := range found {
+			issues = append(issues, lintIssue{File: name, Issue: issue})
+		}
+	}
+
+	if *outFormat == "json" {
+		out, _ := json.MarshalIndent(issues, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		for _, issue := range issues {
+			fmt.Printf("%s:%s\n", issue.File, issue.Issue)
+		}
+	}
+
+	if status == 0 && len(issues) != 0 {
+		status = 1
+	}
+
+	return status
+}
@@ -6,74 +6,256 @@ import (
 	"io"
 	"os"
 	"runtime"
+	"strings"
 
+	"github.com/joshuahenriques/cixac/dap"
+	"github.com/joshuahenriques/cixac/debugger"
 	"github.com/joshuahenriques/cixac/evaluator"
 	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/lsp"
 	"github.com/joshuahenriques/cixac/object"
 	"github.com/joshuahenriques/cixac/parser"
 	"github.com/joshuahenriques/cixac/repl"
 )
 
-func check(e error) {
-	if e != nil {
-		panic(e)
-	}
-}
-
 var (
 	BuildVersion string = "0.5-beta"
 	BuildDate    string = "Oct 03 2024"
 )
 
+// Exit statuses of run, eval and debug. A status of 2 means nothing ran,
+// either because the program doesn't parse or the command line is wrong.
+const (
+	exitOK      = 0
+	exitRuntime = 1 // the program ended with an uncaught error
+	exitParse   = 2
+	exitUsage   = 2
+)
+
+const usage = `Usage:
+  cixac run [flags] FILE [--] [args...]   run a program, - reads it from stdin
+  cixac FILE [args...]                    same as run
+  cixac eval [flags] CODE [args...]       run the program CODE
+  cixac repl                              start the REPL, also with no arguments
+  cixac test [flags] [paths...]           run the tests in *_test.cx files
+  cixac fmt [-w] [files...]               format programs
+  cixac lint [flags] files...             report likely mistakes
+  cixac check [-format text|json] files   report mismatches with the type annotations
+  cixac debug FILE [args...]              run a program in the debugger
+  cixac lsp | dap                         serve the language or debug adapter protocol on stdio
+  cixac version                           print the version
+
+Run "cixac COMMAND -h" for the flags of a command.
+`
+
 func main() {
-	eFlag := flag.String("e", "", "Execute inline code: Specifies a string of code to be directly executed by the program")
-	flag.Parse()
+	os.Exit(run(os.Args[1:]))
+}
 
-	if len(os.Args) == 1 {
-		fmt.Printf("Cixac Version: %s (%s) on %s\n", BuildVersion, BuildDate, runtime.GOOS)
-		fmt.Printf("Use '\\' at the end of a line for multi-line input\n")
-		fmt.Printf("Type \"quit()\" to exit the REPL\n")
-		repl.Start(os.Stdin, os.Stdout)
+func run(args []string) int {
+	if len(args) == 0 {
+		return replMain()
 	}
 
-	if isFlagPassed("e") {
-		runProgram(*eFlag)
-	} else {
-		file, err := os.ReadFile(os.Args[1])
-		check(err)
-		runProgram(string(file))
+	switch args[0] {
+	case "repl":
+		return replMain()
+	case "run":
+		return runMain(args[1:])
+	case "eval":
+		return evalMain(args[1:])
+	case "-e":
+		// the flag eval replaced
+		return evalMain(args[1:])
+	case "version":
+		fmt.Printf("cixac %s (%s) %s/%s\n", BuildVersion, BuildDate, runtime.GOOS, runtime.GOARCH)
+		return exitOK
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return exitOK
+	case "fmt":
+		return fmtMain(args[1:])
+	case "lint":
+		return lintMain(args[1:])
+	case "check":
+		return checkMain(args[1:])
+	case "test":
+		return testMain(args[1:])
+	case "debug":
+		return debugMain(args[1:])
+	case "lsp":
+		if err := lsp.NewServer(os.Stdin, os.Stdout).Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "lsp: %s\n", err)
+			return 1
+		}
+		return exitOK
+	case "dap":
+		if err := dap.NewServer(os.Stdin, os.Stdout).Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "dap: %s\n", err)
+			return 1
+		}
+		return exitOK
 	}
+
+	return runMain(args)
+}
+
+func replMain() int {
+	fmt.Printf("Cixac Version: %s (%s) on %s\n", BuildVersion, BuildDate, runtime.GOOS)
+	fmt.Printf("Type \":help\" for the REPL commands and \"quit()\" to exit\n")
+	repl.Start(os.Stdin, os.Stdout)
+	return exitOK
+}
+
+// runFlags are the flags shared by run and eval
+type runFlags struct {
+	cpuProfile, memProfile string
+	cover                  bool
+	coverDir               string
 }
 
-func runProgram(code string) {
+func newRunFlags(name string) (*flag.FlagSet, *runFlags) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	rf := &runFlags{}
+	flags.StringVar(&rf.cpuProfile, "profile", "", "write a CPU profile of the program to the file and print the calls per function")
+	flags.StringVar(&rf.memProfile, "memprofile", "", "write an allocation profile of the program to the file")
+	flags.BoolVar(&rf.cover, "cover", false, "print the line and branch coverage of the program and write lcov.info and coverage.html")
+	flags.StringVar(&rf.coverDir, "coverdir", ".", "directory the -cover reports are written to")
+	return flags, rf
+}
+
+// runMain runs `cixac run [flags] FILE [--] [args...]`. The arguments after
+// FILE are the program's os.args, a -- before them is dropped.
+func runMain(args []string) int {
+	flags, rf := newRunFlags("run")
+	if err := flags.Parse(args); err != nil {
+		return exitStatus(err)
+	}
+
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "run: no file to run")
+		return exitUsage
+	}
+
+	name, code, err := readProgram(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cixac: %s\n", err)
+		return exitUsage
+	}
+
+	programArgs := flags.Args()[1:]
+	if len(programArgs) > 0 && programArgs[0] == "--" {
+		programArgs = programArgs[1:]
+	}
+	evaluator.SetArgs(programArgs)
+
+	return runProgram(name, code, rf)
+}
+
+// evalMain runs `cixac eval [flags] CODE [args...]`
+func evalMain(args []string) int {
+	flags, rf := newRunFlags("eval")
+	if err := flags.Parse(args); err != nil {
+		return exitStatus(err)
+	}
+
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "eval: no code to run")
+		return exitUsage
+	}
+
+	evaluator.SetArgs(flags.Args()[1:])
+	return runProgram("<eval>", flags.Arg(0), rf)
+}
+
+// debugMain runs `cixac debug FILE [args...]`
+func debugMain(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "debug: no file to debug")
+		return exitUsage
+	}
+
+	name, code, err := readProgram(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cixac: %s\n", err)
+		return exitUsage
+	}
+
+	evaluator.SetArgs(args[1:])
+	return debugger.RunConsole(name, code, os.Stdin, os.Stdout)
+}
+
+// readProgram reads the program in path, stdin when path is -
+func readProgram(path string) (name, code string, err error) {
+	if path == "-" {
+		src, err := io.ReadAll(os.Stdin)
+		return "<stdin>", string(src), err
+	}
+
+	src, err := os.ReadFile(path)
+	return path, string(src), err
+}
+
+// exitStatus is the status for an error parsing flags, asking for the flags
+// with -h isn't a failure
+func exitStatus(err error) int {
+	if err == flag.ErrHelp {
+		return exitOK
+	}
+	return exitUsage
+}
+
+// runProgram returns the exit status of the program: exitRuntime when it ends
+// with an uncaught error and exitParse when it doesn't parse, in which case
+// nothing runs
+func runProgram(name, code string, rf *runFlags) int {
 	l := lexer.New(code)
 	p := parser.New(l)
 
 	program := p.ParseProgram()
 
+	if len(p.Errors()) != 0 {
+		printParserErrors(os.Stderr, name, p.Diagnostics())
+		return exitParse
+	}
+
+	status := exitOK
+
+	stopCoverage := startCoverage(rf.cover, rf.coverDir, name, code, program)
+	stopProfiling := startProfiling(name, rf.cpuProfile, rf.memProfile)
+	stop := func() {
+		stopProfiling()
+		stopCoverage()
+	}
+
+	// os.exit doesn't return here, the reports are written before it exits
+	evaluator.SetExitHook(stop)
 	evaluated := evaluator.Eval(program, object.NewEnvironment())
-	if evaluated != nil && evaluated.Type() != object.EMPTY_OBJ {
+	evaluator.SetExitHook(nil)
+	stop()
+
+	if evaluated != nil && evaluated.Type() == object.ERROR_OBJ {
+		io.WriteString(os.Stderr, evaluated.Inspect())
+		io.WriteString(os.Stderr, "\n")
+		status = exitRuntime
+	} else if evaluated != nil && evaluated.Type() != object.EMPTY_OBJ {
 		io.WriteString(os.Stdout, evaluated.Inspect())
 		io.WriteString(os.Stdout, "\n")
 	}
 
-	if len(p.Errors()) != 0 {
-		printParserErrors(os.Stdout, p.Errors())
-	}
+	return status
 }
 
-func isFlagPassed(name string) bool {
-	found := false
-	flag.Visit(func(f *flag.Flag) {
-		if f.Name == name {
-			found = true
-		}
-	})
-	return found
+func printParserErrors(out io.Writer, name string, diagnostics []parser.Diagnostic) {
+	for _, d := range diagnostics {
+		io.WriteString(out, name+":"+d.String()+"\n")
+	}
 }
 
-func printParserErrors(out io.Writer, errors []string) {
-	for _, msg := range errors {
-		io.WriteString(out, "\t"+msg+"\n")
+// printFileError prints the diagnostics in err, one per line, each prefixed
+// with name like printParserErrors does
+func printFileError(out io.Writer, name string, err error) {
+	for _, line := range strings.Split(err.Error(), "\n") {
+		io.WriteString(out, name+":"+line+"\n")
 	}
 }
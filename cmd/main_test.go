@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain lets the tests run the test binary as cixac: with CIXAC_TEST_MAIN
+// set, it runs its arguments like the cixac command does
+func TestMain(m *testing.M) {
+	if os.Getenv("CIXAC_TEST_MAIN") != "" {
+		os.Exit(run(os.Args[1:]))
+	}
+	os.Exit(m.Run())
+}
+
+// cixac runs cixac with args in dir and returns what it printed to stderr
+// and its exit status
+func cixac(t *testing.T, dir string, args ...string) (string, int) {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CIXAC_TEST_MAIN=1")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("running cixac: %s", err)
+	}
+
+	return stderr.String(), cmd.ProcessState.ExitCode()
+}
+
+func TestRunReportsOnExit(t *testing.T) {
+	tests := []struct {
+		flags  []string
+		files  []string
+		stderr string // the start of the report printed to stderr
+	}{
+		{[]string{"-cover"}, []string{"lcov.info", "coverage.html"}, "file     lines"},
+		{[]string{"-profile", "cpu.pprof", "-memprofile", "mem.pprof"}, []string{"cpu.pprof", "mem.pprof"}, "   calls   cumulative"},
+	}
+
+	scripts := []struct {
+		code   string
+		status int
+	}{
+		{"let x = 1\nprint(x)\n", exitOK},
+		{"let x = 1\nif (x > 0) { os.exit(3) }\nprint(x)\n", 3},
+	}
+
+	for i, tt := range tests {
+		for _, script := range scripts {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "main.cx"), []byte(script.code), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			args := append(append([]string{"run"}, tt.flags...), "main.cx")
+			stderr, status := cixac(t, dir, args...)
+			if status != script.status {
+				t.Errorf("[test: %d] wrong exit status for %q. got=%d, want=%d, stderr=%q", i, script.code, status, script.status, stderr)
+			}
+			if !strings.HasPrefix(stderr, tt.stderr) {
+				t.Errorf("[test: %d] report not printed for %q. stderr=%q", i, script.code, stderr)
+			}
+
+			for _, file := range tt.files {
+				if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+					t.Errorf("[test: %d] %s not written for %q: %s", i, file, script.code, err)
+				}
+			}
+		}
+	}
+}
+
+func TestFileCommandErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "bad.cx"), []byte("let x = ;\nlet y = ;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args   []string
+		status int
+		stderr string // the start of what is printed to stderr
+	}{
+		{[]string{"lint", "bad.cx"}, 2, "bad.cx:1:9: error: no prefix parse function for ; found\nbad.cx:2:9: error: no prefix parse function for ; found\n"},
+		{[]string{"lint"}, 2, "lint: no files to lint\nUsage of lint:\n"},
+		{[]string{"check", "bad.cx"}, 2, "bad.cx:1:9: error: no prefix parse function for ; found\nbad.cx:2:9: error: no prefix parse function for ; found\n"},
+		{[]string{"check"}, 2, "check: no files to check\nUsage of check:\n"},
+	}
+
+	for i, tt := range tests {
+		stderr, status := cixac(t, dir, tt.args...)
+		if status != tt.status {
+			t.Errorf("[test: %d] wrong exit status for %v. got=%d, want=%d", i, tt.args, status, tt.status)
+		}
+		if !strings.HasPrefix(stderr, tt.stderr) {
+			t.Errorf("[test: %d] wrong stderr for %v.\nexpected=%q\ngot=%q", i, tt.args, tt.stderr, stderr)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/joshuahenriques/cixac/profile"
+)
+
+// startProfiling profiles the program when a profile file is given. The
+// returned func stops profiling, writes the profiles and prints the calls
+// per function to stderr.
+func startProfiling(name, cpuFile, memFile string) func() {
+	if cpuFile == "" && memFile == "" {
+		return func() {}
+	}
+
+	p := profile.Start(name, memFile != "")
+
+	return func() {
+		p.Stop()
+
+		if cpuFile != "" {
+			writeProfile(cpuFile, p.WriteCPU)
+		}
+		if memFile != "" {
+			writeProfile(memFile, p.WriteAllocs)
+		}
+
+		p.WriteTable(os.Stderr)
+	}
+}
+
+func writeProfile(path string, write func(io.Writer) error) {
+	f, err := os.Create(path)
+	if err == nil {
+		err = write(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "profile: %s\n", err)
+	}
+}
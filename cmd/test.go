@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/joshuahenriques/cixac/testrunner"
+)
+
+// testMain runs `cixac test [-run pattern] [-junit file] [-v] [dirs or files...]`,
+// the current directory by default. It returns 1 when a test fails and 2 when
+// the tests can't be found or the flags are wrong.
+func testMain(args []string) int {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	runPattern := flags.String("run", "", "run only the tests whose name matches the regular expression")
+	junitPath := flags.String("junit", "", "write the results as JUnit XML to the file")
+	verbose := flags.Bool("v", false, "print every test, not only failures")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var filter *regexp.Regexp
+	if *runPattern != "" {
+		var err error
+		if filter, err = regexp.Compile(*runPattern); err != nil {
+			fmt.Fprintf(os.Stderr, "test: invalid -run pattern: %s\n", err)
+			return 2
+		}
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "test: %s\n", err)
+			return 2
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		found, err := testrunner.Discover(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "test: %s\n", err)
+			return 2
+		}
+		files = append(files, found...)
+	}
+
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "test: no %s files in %s\n", testrunner.FileSuffix, strings.Join(paths, ", "))
+		return 2
+	}
+
+	status := 0
+	var suites []testrunner.Suite
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "test: %s\n", err)
+			status = 2
+			continue
+		}
+
+		suite := testrunner.Run(file, string(src), filter)
+		suites = append(suites, suite)
+		printSuite(suite, *verbose)
+
+		if suite.Failed() && status == 0 {
+			status = 1
+		}
+	}
+
+	if *junitPath != "" {
+		f, err := os.Create(*junitPath)
+		if err == nil {
+			err = testrunner.WriteJUnit(f, suites)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "test: %s\n", err)
+			return 2
+		}
+	}
+
+	return status
+}
+
+func printSuite(suite testrunner.Suite, verbose bool) {
+	if suite.Err != "" {
+		fmt.Printf("%s%s\n", testrunner.Position(suite.File, suite.Line, suite.Column), suite.Err)
+		fmt.Printf("FAIL\t%s\t%s\n", suite.File, duration(suite.Duration))
+		return
+	}
+
+	for _, r := range suite.Results {
+		if r.Status == testrunner.Pass && !verbose {
+			continue
+		}
+
+		fmt.Printf("--- %s: %s (%s)\n", r.Status, r.Name, duration(r.Duration))
+		if r.Status != testrunner.Pass {
+			message := testrunner.Position(suite.File, r.Line, r.Column) + r.Message
+			fmt.Printf("    %s\n", strings.ReplaceAll(message, "\n", "\n    "))
+		}
+	}
+
+	switch {
+	case suite.Failed():
+		fmt.Printf("FAIL\t%s\t%s\n", suite.File, duration(suite.Duration))
+	case len(suite.Results) == 0:
+		fmt.Printf("ok  \t%s\t%s [no tests to run]\n", suite.File, duration(suite.Duration))
+	default:
+		fmt.Printf("ok  \t%s\t%s\n", suite.File, duration(suite.Duration))
+	}
+}
+
+func duration(d time.Duration) string {
+	return fmt.Sprintf("%.3fs", d.Seconds())
+}
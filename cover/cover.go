@@ -0,0 +1,149 @@
+// Package cover reports the coverage the evaluator counts for a program as a
+// summary, an LCOV tracefile and an annotated HTML page.
+package cover
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/joshuahenriques/cixac/evaluator"
+)
+
+type File struct {
+	Name     string
+	Source   string
+	Coverage *evaluator.Coverage
+}
+
+// Lines returns the runs of each line a statement starts on. With several
+// statements on a line it is the most any of them ran.
+func (f *File) Lines() map[int]int {
+	lines := map[int]int{}
+	for pos, count := range f.Coverage.Statements {
+		if count >= lines[pos.Line] {
+			lines[pos.Line] = count
+		}
+	}
+	return lines
+}
+
+type site struct {
+	evaluator.Position
+	*evaluator.Branch
+}
+
+// branches returns the branches in source order
+func (f *File) branches() []site {
+	var sites []site
+	for pos, branch := range f.Coverage.Branches {
+		sites = append(sites, site{pos, branch})
+	}
+
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].Line != sites[j].Line {
+			return sites[i].Line < sites[j].Line
+		}
+		return sites[i].Column < sites[j].Column
+	})
+
+	return sites
+}
+
+type Summary struct {
+	Lines, LinesHit       int
+	Branches, BranchesHit int
+}
+
+func (f *File) Summary() Summary {
+	var s Summary
+
+	for _, count := range f.Lines() {
+		s.Lines++
+		if count > 0 {
+			s.LinesHit++
+		}
+	}
+
+	for _, branch := range f.Coverage.Branches {
+		for _, count := range branch.Arms {
+			s.Branches++
+			if count > 0 {
+				s.BranchesHit++
+			}
+		}
+	}
+
+	return s
+}
+
+func percent(hit, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(hit)/float64(total))
+}
+
+// WriteSummary writes the line and branch coverage of each file
+func WriteSummary(w io.Writer, files []File) {
+	width := len("file")
+	for _, f := range files {
+		width = max(width, len(f.Name))
+	}
+
+	fmt.Fprintf(w, "%-*s  %-16s  %s\n", width, "file", "lines", "branches")
+	for _, f := range files {
+		s := f.Summary()
+		lines := fmt.Sprintf("%s (%d/%d)", percent(s.LinesHit, s.Lines), s.LinesHit, s.Lines)
+		branches := fmt.Sprintf("%s (%d/%d)", percent(s.BranchesHit, s.Branches), s.BranchesHit, s.Branches)
+		fmt.Fprintf(w, "%-*s  %-16s  %s\n", width, f.Name, lines, branches)
+	}
+}
+
+// WriteLCOV writes the files as an LCOV tracefile, which genhtml and most
+// coverage services read
+func WriteLCOV(w io.Writer, files []File) error {
+	var b strings.Builder
+
+	for _, f := range files {
+		b.WriteString("TN:\n")
+		fmt.Fprintf(&b, "SF:%s\n", f.Name)
+
+		s := f.Summary()
+		for block, site := range f.branches() {
+			ran := false
+			for _, count := range site.Arms {
+				ran = ran || count > 0
+			}
+
+			for arm, count := range site.Arms {
+				taken := "-"
+				if ran {
+					taken = fmt.Sprint(count)
+				}
+				fmt.Fprintf(&b, "BRDA:%d,%d,%d,%s\n", site.Line, block, arm, taken)
+			}
+		}
+		fmt.Fprintf(&b, "BRF:%d\nBRH:%d\n", s.Branches, s.BranchesHit)
+
+		lines := f.Lines()
+		for _, line := range sortedLines(lines) {
+			fmt.Fprintf(&b, "DA:%d,%d\n", line, lines[line])
+		}
+		fmt.Fprintf(&b, "LF:%d\nLH:%d\n", s.Lines, s.LinesHit)
+		b.WriteString("end_of_record\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func sortedLines(lines map[int]int) []int {
+	sorted := make([]int, 0, len(lines))
+	for line := range lines {
+		sorted = append(sorted, line)
+	}
+	sort.Ints(sorted)
+	return sorted
+}
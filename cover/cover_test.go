@@ -0,0 +1,103 @@
+package cover
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/joshuahenriques/cixac/evaluator"
+	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/object"
+	"github.com/joshuahenriques/cixac/parser"
+)
+
+const source = `let n = 1
+if (n > 0) {
+	print("positive")
+} else {
+	print("negative")
+}
+while (n > 5) { n-- }`
+
+func testFiles(t *testing.T) []File {
+	t.Helper()
+
+	program := parser.New(lexer.New(source)).ParseProgram()
+
+	var out bytes.Buffer
+	evaluator.SetOutput(&out)
+	defer evaluator.SetOutput(os.Stdout)
+
+	c := evaluator.StartCoverage(program)
+	evaluator.Eval(program, object.NewEnvironment())
+	evaluator.StopCoverage()
+
+	return []File{{Name: "main.cx", Source: source, Coverage: c}}
+}
+
+func TestSummary(t *testing.T) {
+	files := testFiles(t)
+
+	s := files[0].Summary()
+	expected := Summary{Lines: 5, LinesHit: 4, Branches: 4, BranchesHit: 2}
+	if s != expected {
+		t.Errorf("wrong summary. expected=%+v, got=%+v", expected, s)
+	}
+
+	var out bytes.Buffer
+	WriteSummary(&out, files)
+
+	expectedOut := "file     lines             branches\nmain.cx  80.0% (4/5)       50.0% (2/4)\n"
+	if out.String() != expectedOut {
+		t.Errorf("wrong summary output.\nexpected=%q\ngot=     %q", expectedOut, out.String())
+	}
+}
+
+func TestWriteLCOV(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteLCOV(&out, testFiles(t)); err != nil {
+		t.Fatalf("WriteLCOV error: %s", err)
+	}
+
+	expected := `TN:
+SF:main.cx
+BRDA:2,0,0,1
+BRDA:2,0,1,0
+BRDA:7,1,0,0
+BRDA:7,1,1,1
+BRF:4
+BRH:2
+DA:1,1
+DA:2,1
+DA:3,1
+DA:5,0
+DA:7,1
+LF:5
+LH:4
+end_of_record
+`
+	if out.String() != expected {
+		t.Errorf("wrong LCOV.\nexpected=%s\ngot=%s", expected, out.String())
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteHTML(&out, testFiles(t)); err != nil {
+		t.Fatalf("WriteHTML error: %s", err)
+	}
+
+	html := out.String()
+	for _, expected := range []string{
+		`<tr class="partial" title="if at column 1: if 1, else 0"><td class="number">2</td><td class="hits">1</td>`,
+		`<tr class="covered"><td class="number">3</td><td class="hits">1</td><td class="code">	print(&#34;positive&#34;)</td></tr>`,
+		`<tr class="uncovered"><td class="number">5</td><td class="hits">0</td>`,
+		`<tr class=""><td class="number">6</td><td class="hits"></td><td class="code">}</td></tr>`,
+		`<td>80.0% (4/5)</td><td>50.0% (2/4)</td>`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("HTML doesn't contain %q", expected)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package cover
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+var htmlTemplate = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Cixac coverage</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table.summary td, table.summary th { padding: 0.2em 1em; text-align: left; }
+table.source { border-collapse: collapse; font-family: monospace; white-space: pre; }
+table.source td { padding: 0 0.5em; }
+td.number, td.hits { color: #888; text-align: right; }
+tr.covered td.code { background: #d7f5d7; }
+tr.uncovered td.code { background: #f8d3d3; }
+tr.partial td.code { background: #f8f0c0; }
+</style>
+</head>
+<body>
+<h1>Coverage</h1>
+<table class="summary">
+<tr><th>file</th><th>lines</th><th>branches</th></tr>
+{{range .}}<tr><td><a href="#{{.Name}}">{{.Name}}</a></td><td>{{.Lines}}</td><td>{{.Branches}}</td></tr>
+{{end}}</table>
+{{range .}}
+<h2 id="{{.Name}}">{{.Name}}</h2>
+<table class="source">
+{{range .Rows}}<tr class="{{.Class}}"{{if .Title}} title="{{.Title}}"{{end}}><td class="number">{{.Number}}</td><td class="hits">{{.Hits}}</td><td class="code">{{.Code}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+type htmlFile struct {
+	Name     string
+	Lines    string
+	Branches string
+	Rows     []htmlRow
+}
+
+type htmlRow struct {
+	Number int
+	Hits   string
+	Code   string
+	Class  string
+	Title  string
+}
+
+// WriteHTML writes a page with the source of the files, lines that ran in
+// green, lines that didn't in red and lines with a branch that never went
+// one of its ways in yellow. Hovering a line shows its branches.
+func WriteHTML(w io.Writer, files []File) error {
+	var data []htmlFile
+
+	for _, f := range files {
+		s := f.Summary()
+		hf := htmlFile{
+			Name:     f.Name,
+			Lines:    fmt.Sprintf("%s (%d/%d)", percent(s.LinesHit, s.Lines), s.LinesHit, s.Lines),
+			Branches: fmt.Sprintf("%s (%d/%d)", percent(s.BranchesHit, s.Branches), s.BranchesHit, s.Branches),
+		}
+
+		ran := map[int]bool{}
+		missed := map[int]bool{}
+		for pos, count := range f.Coverage.Statements {
+			if count > 0 {
+				ran[pos.Line] = true
+			} else {
+				missed[pos.Line] = true
+			}
+		}
+
+		titles := map[int][]string{}
+		for _, site := range f.branches() {
+			titles[site.Line] = append(titles[site.Line], describe(site))
+			for _, count := range site.Arms {
+				if count == 0 {
+					missed[site.Line] = true
+				}
+			}
+		}
+
+		lines := f.Lines()
+		for i, code := range strings.Split(f.Source, "\n") {
+			n := i + 1
+			row := htmlRow{Number: n, Code: strings.TrimRight(code, "\r"), Title: strings.Join(titles[n], "\n")}
+
+			if count, ok := lines[n]; ok {
+				row.Hits = fmt.Sprint(count)
+			}
+
+			switch {
+			case ran[n] && missed[n]:
+				row.Class = "partial"
+			case ran[n]:
+				row.Class = "covered"
+			case missed[n]:
+				row.Class = "uncovered"
+			}
+
+			hf.Rows = append(hf.Rows, row)
+		}
+
+		data = append(data, hf)
+	}
+
+	return htmlTemplate.Execute(w, data)
+}
+
+func describe(s site) string {
+	if s.Loop {
+		return fmt.Sprintf("loop at column %d: body ran %d times, skipped %d times", s.Column, s.Arms[0], s.Arms[1])
+	}
+
+	if s.Match {
+		arms := make([]string, len(s.Arms))
+		for i, count := range s.Arms {
+			arms[i] = fmt.Sprintf("arm %d %d", i+1, count)
+		}
+		return fmt.Sprintf("match at column %d: %s", s.Column, strings.Join(arms, ", "))
+	}
+
+	arms := make([]string, len(s.Arms))
+	for i, count := range s.Arms {
+		name := "else"
+		if i == 0 {
+			name = "if"
+		} else if i < len(s.Arms)-1 {
+			name = "else if"
+		}
+		arms[i] = fmt.Sprintf("%s %d", name, count)
+	}
+	return fmt.Sprintf("if at column %d: %s", s.Column, strings.Join(arms, ", "))
+}
@@ -0,0 +1,567 @@
+// Package dap serves the Debug Adapter Protocol so IDEs can debug Cixac
+// programs, on top of the debugger package.
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/debugger"
+	"github.com/joshuahenriques/cixac/evaluator"
+	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/object"
+	"github.com/joshuahenriques/cixac/parser"
+)
+
+// the program runs as the only thread
+const threadID = 1
+
+// Server is a debug adapter that talks to a client over a pair of streams,
+// normally stdin and stdout. It debugs one program per session, running it
+// on its own goroutine once the client is done configuring.
+type Server struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	wmu sync.Mutex // guards writing
+	seq int
+
+	mu          sync.Mutex // guards the fields below, the program changes them while it runs
+	d           *debugger.Debugger
+	launch      *LaunchArguments
+	program     *ast.Program
+	breakpoints map[string][]int // by source path
+	running     bool
+	paused      bool
+	terminating bool
+	handles     map[int]handle // variable references, valid while paused
+
+	resume chan func(*debugger.Debugger)
+	done   chan struct{}
+}
+
+// handle is what a variable reference expands to, the variables of a scope
+// of a frame or the children of an array or hash
+type handle struct {
+	frame   int
+	globals bool
+	object  object.Object
+}
+
+// quit unwinds the program when the client disconnects
+type quit struct{}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:          bufio.NewReader(in),
+		out:         out,
+		breakpoints: map[string][]int{},
+		resume:      make(chan func(*debugger.Debugger)),
+		done:        make(chan struct{}),
+	}
+}
+
+// Run serves requests until the client disconnects or closes the input,
+// stopping the program if it still runs
+func (s *Server) Run() error {
+	defer s.stop()
+
+	for {
+		data, err := s.readMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(data, &req); err != nil || req.Type != "request" {
+			continue
+		}
+
+		body, then, err := s.handle(&req)
+
+		resp := &response{message: message{Type: "response"}, RequestSeq: req.Seq, Success: err == nil, Command: req.Command, Body: body}
+		if err != nil {
+			resp.Message = err.Error()
+		}
+		s.send(resp)
+
+		if then != nil {
+			then()
+		}
+
+		if req.Command == "disconnect" {
+			return nil
+		}
+	}
+}
+
+// handle returns the body of the response to req and what to do once it's
+// sent
+func (s *Server) handle(req *request) (any, func(), error) {
+	switch req.Command {
+	case "initialize":
+		return Capabilities{
+			SupportsConfigurationDoneRequest: true,
+			SupportsEvaluateForHovers:        true,
+			SupportTerminateDebuggee:         true,
+		}, func() { s.event("initialized", nil) }, nil
+
+	case "launch":
+		var args LaunchArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, s.load(args)
+
+	case "setBreakpoints":
+		var args SetBreakpointsArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, nil, err
+		}
+		return s.setBreakpoints(args), nil, nil
+
+	case "configurationDone":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.program == nil {
+			return nil, nil, errors.New("launch the program first")
+		}
+		if s.running {
+			return nil, nil, nil
+		}
+		s.running = true
+		return nil, s.start, nil
+
+	case "threads":
+		return map[string]any{"threads": []Thread{{ID: threadID, Name: "main"}}}, nil, nil
+
+	case "stackTrace":
+		var args StackTraceArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, nil, err
+		}
+		return s.stackTrace(args)
+
+	case "scopes":
+		var args ScopesArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, nil, err
+		}
+		return s.scopes(args)
+
+	case "variables":
+		var args VariablesArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, nil, err
+		}
+		return s.variables(args)
+
+	case "evaluate":
+		var args EvaluateArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, nil, err
+		}
+		return s.evaluate(args)
+
+	case "continue":
+		return s.resumeWith((*debugger.Debugger).Continue, map[string]any{"allThreadsContinued": true})
+	case "next":
+		return s.resumeWith((*debugger.Debugger).StepOver, nil)
+	case "stepIn":
+		return s.resumeWith((*debugger.Debugger).StepIn, nil)
+	case "stepOut":
+		return s.resumeWith((*debugger.Debugger).StepOut, nil)
+
+	case "pause":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.running && !s.paused {
+			s.d.Pause()
+		}
+		return nil, nil, nil
+
+	case "disconnect", "terminate":
+		return nil, s.stop, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported request %q", req.Command)
+}
+
+func (s *Server) load(args LaunchArguments) error {
+	if args.Program == "" {
+		return errors.New("launch needs a program")
+	}
+
+	src, err := os.ReadFile(args.Program)
+	if err != nil {
+		return err
+	}
+
+	p := parser.New(lexer.New(string(src)))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		msgs := make([]string, 0, len(p.Diagnostics()))
+		for _, d := range p.Diagnostics() {
+			msgs = append(msgs, args.Program+":"+d.String())
+		}
+		return errors.New(strings.Join(msgs, "\n"))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.program != nil {
+		return errors.New("a program is already launched")
+	}
+
+	s.launch = &args
+	s.program = program
+	s.d = debugger.New(args.StopOnEntry && !args.NoDebug)
+	if !args.NoDebug {
+		s.d.SetBreakpoints(s.breakpoints[cleanPath(args.Program)])
+	}
+
+	return nil
+}
+
+func (s *Server) setBreakpoints(args SetBreakpointsArguments) any {
+	path := cleanPath(args.Source.Path)
+
+	lines := make([]int, len(args.Breakpoints))
+	for i, bp := range args.Breakpoints {
+		lines[i] = bp.Line
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.breakpoints[path] = lines
+
+	verified := true
+	if s.launch != nil {
+		verified = path == cleanPath(s.launch.Program)
+		if verified && !s.launch.NoDebug {
+			s.d.SetBreakpoints(lines)
+		}
+	}
+
+	breakpoints := make([]Breakpoint, len(lines))
+	for i, line := range lines {
+		breakpoints[i] = Breakpoint{Verified: verified, Line: line, Source: args.Source}
+	}
+
+	return map[string]any{"breakpoints": breakpoints}
+}
+
+func cleanPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// start runs the program, reporting its output as output events and its end
+// as exited and terminated events
+func (s *Server) start() {
+	s.d.OnPause = s.pause
+	evaluator.SetArgs(s.launch.Args)
+	evaluator.SetOutput(outputWriter{s, "stdout"})
+
+	go func() {
+		defer close(s.done)
+
+		status := 0
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					if _, ok := r.(quit); !ok {
+						panic(r)
+					}
+				}
+			}()
+
+			result := s.d.Run(s.program, object.NewEnvironment())
+			if result != nil && result.Type() == object.ERROR_OBJ {
+				s.event("output", OutputEventBody{Category: "stderr", Output: result.Inspect() + "\n"})
+				status = 1
+			}
+		}()
+
+		evaluator.SetOutput(os.Stdout)
+
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+
+		s.event("exited", map[string]int{"exitCode": status})
+		s.event("terminated", nil)
+	}()
+}
+
+// pause runs on the program's goroutine, blocking it until the client
+// resumes or disconnects
+func (s *Server) pause(reason string) {
+	s.mu.Lock()
+	if s.terminating {
+		s.mu.Unlock()
+		panic(quit{})
+	}
+	s.paused = true
+	s.handles = map[int]handle{}
+	s.mu.Unlock()
+
+	s.event("stopped", StoppedEventBody{Reason: reason, ThreadID: threadID, AllThreadsStopped: true})
+
+	action := <-s.resume
+	if action == nil {
+		panic(quit{})
+	}
+	action(s.d)
+}
+
+func (s *Server) resumeWith(action func(*debugger.Debugger), body any) (any, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.paused {
+		return nil, nil, errors.New("the program is not paused")
+	}
+	s.paused = false
+	s.handles = nil
+
+	return body, func() { s.resume <- action }, nil
+}
+
+// stop ends the program if it runs and waits for it
+func (s *Server) stop() {
+	s.mu.Lock()
+	running, paused := s.running, s.paused
+	s.terminating = true
+	s.paused = false
+	s.mu.Unlock()
+
+	if !running {
+		return
+	}
+
+	if paused {
+		s.resume <- nil
+	} else {
+		s.d.Pause()
+	}
+	<-s.done
+}
+
+// lockPaused locks the server when the program is paused, so its state can
+// be read, and fails otherwise. The caller unlocks
+func (s *Server) lockPaused() error {
+	s.mu.Lock()
+	if !s.paused {
+		s.mu.Unlock()
+		return errors.New("the program is not paused")
+	}
+	return nil
+}
+
+func (s *Server) stackTrace(args StackTraceArguments) (any, func(), error) {
+	if err := s.lockPaused(); err != nil {
+		return nil, nil, err
+	}
+	defer s.mu.Unlock()
+
+	frames := s.d.Frames()
+	source := Source{Name: filepath.Base(s.launch.Program), Path: cleanPath(s.launch.Program)}
+
+	start := min(args.StartFrame, len(frames))
+	end := len(frames)
+	if args.Levels > 0 {
+		end = min(start+args.Levels, end)
+	}
+
+	stackFrames := []StackFrame{}
+	for i := start; i < end; i++ {
+		f := frames[i]
+		stackFrames = append(stackFrames, StackFrame{ID: i + 1, Name: f.Name, Source: source, Line: f.Line, Column: f.Column})
+	}
+
+	return map[string]any{"stackFrames": stackFrames, "totalFrames": len(frames)}, nil, nil
+}
+
+func (s *Server) scopes(args ScopesArguments) (any, func(), error) {
+	if err := s.lockPaused(); err != nil {
+		return nil, nil, err
+	}
+	defer s.mu.Unlock()
+
+	frame := args.FrameID - 1
+	if frame < 0 || frame >= len(s.d.Frames()) {
+		return nil, nil, fmt.Errorf("no frame %d", args.FrameID)
+	}
+
+	scopes := []Scope{{Name: "Locals", VariablesReference: s.newHandle(handle{frame: frame})}}
+	if frame != len(s.d.Frames())-1 {
+		scopes = append(scopes, Scope{Name: "Globals", VariablesReference: s.newHandle(handle{globals: true})})
+	}
+
+	return map[string]any{"scopes": scopes}, nil, nil
+}
+
+func (s *Server) variables(args VariablesArguments) (any, func(), error) {
+	if err := s.lockPaused(); err != nil {
+		return nil, nil, err
+	}
+	defer s.mu.Unlock()
+
+	h, ok := s.handles[args.VariablesReference]
+	if !ok {
+		return nil, nil, fmt.Errorf("no variables %d", args.VariablesReference)
+	}
+
+	variables := []Variable{}
+
+	switch obj := h.object.(type) {
+	case nil:
+		vars := s.d.Locals(h.frame)
+		if h.globals {
+			vars = s.d.Globals()
+		}
+		for _, v := range vars {
+			variables = append(variables, s.variable(v.Name, v.Value))
+		}
+
+	case *object.Array:
+		for i, element := range obj.Elements {
+			variables = append(variables, s.variable(strconv.Itoa(i), element))
+		}
+
+	case *object.Hash:
+		pairs := make([]object.HashPair, 0, len(obj.Pairs))
+		for _, pair := range obj.Pairs {
+			pairs = append(pairs, pair)
+		}
+		sort.Slice(pairs, func(i, j int) bool {
+			return debugger.Inspect(pairs[i].Key) < debugger.Inspect(pairs[j].Key)
+		})
+		for _, pair := range pairs {
+			variables = append(variables, s.variable(debugger.Inspect(pair.Key), pair.Value))
+		}
+	}
+
+	return map[string]any{"variables": variables}, nil, nil
+}
+
+// variable gives arrays and hashes a reference the client expands them with
+func (s *Server) variable(name string, obj object.Object) Variable {
+	v := Variable{Name: name, Value: debugger.Inspect(obj), Type: strings.ToLower(string(obj.Type()))}
+
+	switch obj := obj.(type) {
+	case *object.Array:
+		if len(obj.Elements) != 0 {
+			v.VariablesReference = s.newHandle(handle{object: obj})
+		}
+	case *object.Hash:
+		if len(obj.Pairs) != 0 {
+			v.VariablesReference = s.newHandle(handle{object: obj})
+		}
+	}
+
+	return v
+}
+
+func (s *Server) newHandle(h handle) int {
+	ref := len(s.handles) + 1
+	s.handles[ref] = h
+	return ref
+}
+
+func (s *Server) evaluate(args EvaluateArguments) (any, func(), error) {
+	if err := s.lockPaused(); err != nil {
+		return nil, nil, err
+	}
+	defer s.mu.Unlock()
+
+	frame := max(args.FrameID-1, 0)
+
+	result, err := s.d.Evaluate(frame, args.Expression)
+	if err != nil {
+		return nil, nil, err
+	}
+	if result.Type() == object.ERROR_OBJ {
+		return nil, nil, errors.New(result.Inspect())
+	}
+
+	v := s.variable("", result)
+	return map[string]any{"result": v.Value, "type": v.Type, "variablesReference": v.VariablesReference}, nil, nil
+}
+
+func (s *Server) event(name string, body any) {
+	s.send(&event{message: message{Type: "event"}, Event: name, Body: body})
+}
+
+// send numbers and writes a response or event
+func (s *Server) send(msg interface{ number(int) }) {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+
+	s.seq++
+	msg.number(s.seq)
+	s.write(msg)
+}
+
+func (s *Server) write(v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+// readMessage reads the headers of a message and returns its content
+func (s *Server) readMessage() ([]byte, error) {
+	headers, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(strings.TrimSpace(headers.Get("Content-Length")))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", headers.Get("Content-Length"))
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(s.in, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// outputWriter turns what the program prints into output events
+type outputWriter struct {
+	s        *Server
+	category string
+}
+
+func (w outputWriter) Write(p []byte) (int, error) {
+	w.s.event("output", OutputEventBody{Category: w.category, Output: string(p)})
+	return len(p), nil
+}
@@ -0,0 +1,129 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRecordedSessions replays the sessions in testdata. Lines starting with
+// -> are sent to the server and lines starting with <- are the messages
+// expected back in order, matched on the fields they list.
+func TestRecordedSessions(t *testing.T) {
+	paths, _ := filepath.Glob("testdata/*.dap")
+	if len(paths) == 0 {
+		t.Fatalf("no recorded sessions")
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			replay(t, path)
+		})
+	}
+}
+
+func replay(t *testing.T, path string) {
+	script, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	server := NewServer(inR, outW)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.Run()
+		outW.Close()
+	}()
+
+	messages := make(chan map[string]any)
+	go func() {
+		defer close(messages)
+		r := &Server{in: bufio.NewReader(outR)}
+		for {
+			data, err := r.readMessage()
+			if err != nil {
+				return
+			}
+			var msg map[string]any
+			json.Unmarshal(data, &msg)
+			messages <- msg
+		}
+	}()
+
+	for n, line := range strings.Split(string(script), "\n") {
+		switch {
+		case strings.HasPrefix(line, "-> "):
+			data := strings.TrimPrefix(line, "-> ")
+			fmt.Fprintf(inW, "Content-Length: %d\r\n\r\n%s", len(data), data)
+
+		case strings.HasPrefix(line, "<- "):
+			var expected map[string]any
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "<- ")), &expected); err != nil {
+				t.Fatalf("line %d: %s", n+1, err)
+			}
+
+			select {
+			case msg, ok := <-messages:
+				if !ok {
+					t.Fatalf("line %d: server closed, expected %v", n+1, expected)
+				}
+				if !matches(expected, msg) {
+					t.Fatalf("line %d: message wrong.\nexpected=%v\ngot=%v", n+1, expected, msg)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("line %d: timed out waiting for %v", n+1, expected)
+			}
+		}
+	}
+
+	inW.Close()
+	if err := <-errc; err != nil {
+		t.Errorf("Run error: %s", err)
+	}
+
+	for msg := range messages {
+		t.Errorf("unexpected message %v", msg)
+	}
+}
+
+// matches reports whether actual has the fields of expected, arrays have to
+// be of the same length
+func matches(expected, actual any) bool {
+	switch e := expected.(type) {
+	case map[string]any:
+		a, ok := actual.(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, value := range e {
+			if !matches(value, a[key]) {
+				return false
+			}
+		}
+		return true
+
+	case []any:
+		a, ok := actual.([]any)
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		for i := range e {
+			if !matches(e[i], a[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return reflect.DeepEqual(expected, actual)
+}
@@ -0,0 +1,209 @@
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/object"
+	"github.com/joshuahenriques/cixac/parser"
+)
+
+const consolePrompt = "(debug) "
+
+const consoleHelp = `Commands:
+  break N, b N       pause before line N
+  clear N            remove the breakpoint on line N
+  breakpoints        list the breakpoints
+  continue, c        run to the next breakpoint
+  step, s            step into the next statement
+  next, n            step over calls to the next line
+  out, o             run until the current function returns
+  stack, bt          print the call stack
+  frame N, f N       select frame N of the stack
+  locals             print the variables of the selected frame
+  print EXPR, p EXPR evaluate EXPR in the selected frame
+  list, l            print the source around the current line
+  quit, q            stop the program
+`
+
+// quit unwinds the program when the console asks to quit
+type quit struct{}
+
+type console struct {
+	d     *Debugger
+	name  string
+	lines []string
+	in    *bufio.Scanner
+	out   io.Writer
+	frame int
+}
+
+// RunConsole debugs code from a line based console, pausing before the first
+// statement. It returns the exit status of the program like running it: 1
+// when it ends with an error and 2 when it doesn't parse.
+func RunConsole(name, code string, in io.Reader, out io.Writer) (status int) {
+	p := parser.New(lexer.New(code))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		for _, d := range p.Diagnostics() {
+			fmt.Fprintf(out, "%s:%s\n", name, d)
+		}
+		return 2
+	}
+
+	c := &console{
+		d:     New(true),
+		name:  name,
+		lines: strings.Split(code, "\n"),
+		in:    bufio.NewScanner(in),
+		out:   out,
+	}
+	c.d.OnPause = c.pause
+
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(quit); !ok {
+				panic(r)
+			}
+			status = 0
+		}
+	}()
+
+	result := c.d.Run(program, object.NewEnvironment())
+	if result != nil && result.Type() == object.ERROR_OBJ {
+		fmt.Fprintln(out, result.Inspect())
+		return 1
+	}
+
+	fmt.Fprintln(out, "program exited")
+	return 0
+}
+
+func (c *console) pause(reason string) {
+	c.frame = 0
+	top := c.d.Frames()[0]
+	fmt.Fprintf(c.out, "paused at %s:%d (%s) in %s\n", c.name, top.Line, reason, top.Name)
+	c.printLine(top.Line, true)
+
+	for {
+		fmt.Fprint(c.out, consolePrompt)
+		if !c.in.Scan() {
+			panic(quit{})
+		}
+
+		cmd, arg, _ := strings.Cut(strings.TrimSpace(c.in.Text()), " ")
+		arg = strings.TrimSpace(arg)
+
+		switch cmd {
+		case "":
+		case "break", "b":
+			if line, ok := c.line(arg); ok {
+				c.d.SetBreakpoints(append(c.d.Breakpoints(), line))
+				fmt.Fprintf(c.out, "breakpoint at line %d\n", line)
+			}
+		case "clear":
+			if line, ok := c.line(arg); ok {
+				var lines []int
+				for _, bp := range c.d.Breakpoints() {
+					if bp != line {
+						lines = append(lines, bp)
+					}
+				}
+				c.d.SetBreakpoints(lines)
+			}
+		case "breakpoints":
+			for _, line := range c.d.Breakpoints() {
+				c.printLine(line, false)
+			}
+		case "continue", "c":
+			c.d.Continue()
+			return
+		case "step", "s":
+			c.d.StepIn()
+			return
+		case "next", "n":
+			c.d.StepOver()
+			return
+		case "out", "o":
+			c.d.StepOut()
+			return
+		case "stack", "bt":
+			for i, frame := range c.d.Frames() {
+				marker := " "
+				if i == c.frame {
+					marker = "*"
+				}
+				fmt.Fprintf(c.out, "%s %d %s at %s:%d\n", marker, i, frame.Name, c.name, frame.Line)
+			}
+		case "frame", "f":
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 || n >= len(c.d.Frames()) {
+				fmt.Fprintf(c.out, "no frame %q\n", arg)
+				continue
+			}
+			c.frame = n
+		case "locals":
+			for _, v := range c.d.Locals(c.frame) {
+				fmt.Fprintf(c.out, "%s = %s\n", v.Name, Inspect(v.Value))
+			}
+		case "print", "p":
+			result, err := c.d.Evaluate(c.frame, arg)
+			if err != nil {
+				fmt.Fprintln(c.out, err)
+				continue
+			}
+			fmt.Fprintln(c.out, Inspect(result))
+		case "list", "l":
+			line := c.d.Frames()[c.frame].Line
+			for n := max(line-3, 1); n <= min(line+3, len(c.lines)); n++ {
+				c.printLine(n, n == line)
+			}
+		case "quit", "q":
+			panic(quit{})
+		case "help", "h":
+			fmt.Fprint(c.out, consoleHelp)
+		default:
+			fmt.Fprintf(c.out, "unknown command %q, try help\n", cmd)
+		}
+	}
+}
+
+func (c *console) line(arg string) (int, bool) {
+	line, err := strconv.Atoi(arg)
+	if err != nil || line < 1 || line > len(c.lines) {
+		fmt.Fprintf(c.out, "no line %q\n", arg)
+		return 0, false
+	}
+	return line, true
+}
+
+func (c *console) printLine(n int, current bool) {
+	marker := " "
+	if current {
+		marker = ">"
+	}
+	if n >= 1 && n <= len(c.lines) {
+		fmt.Fprintf(c.out, "%s %4d\t%s\n", marker, n, strings.TrimRight(c.lines[n-1], "\r"))
+	}
+}
+
+// Inspect formats a value for display. Strings are quoted so they can be told
+// apart from other values and functions leave out their bodies.
+func Inspect(obj object.Object) string {
+	switch obj := obj.(type) {
+	case *object.String:
+		return strconv.Quote(obj.Value)
+	case *object.Function:
+		params := make([]string, len(obj.Parameters))
+		for i, param := range obj.Parameters {
+			params[i] = param.Value
+		}
+		return "fn(" + strings.Join(params, ", ") + ")"
+	}
+	return obj.Inspect()
+}
@@ -0,0 +1,271 @@
+// Package debugger pauses a running program at breakpoints and steps, and
+// inspects the paused program. It drives the evaluator through
+// evaluator.Hook, pausing on the goroutine that runs the program.
+package debugger
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/evaluator"
+	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/object"
+	"github.com/joshuahenriques/cixac/parser"
+)
+
+// Reasons the program paused
+const (
+	ReasonEntry      = "entry"
+	ReasonBreakpoint = "breakpoint"
+	ReasonStep       = "step"
+	ReasonPause      = "pause"
+)
+
+type mode int
+
+const (
+	modeRun mode = iota
+	modeStepIn
+	modeStepOver
+	modeStepOut
+)
+
+type Frame struct {
+	Name   string
+	Line   int
+	Column int
+	Env    *object.Environment // the scope of the current statement
+	base   *object.Environment // the scope of the call
+	stmt   ast.Statement
+}
+
+type Variable struct {
+	Name  string
+	Value object.Object
+}
+
+type Debugger struct {
+	// OnPause runs on the program's goroutine whenever it pauses, the
+	// program resumes when it returns. It calls Continue or a step to say
+	// how to resume, the default being Continue.
+	OnPause func(reason string)
+
+	mu          sync.Mutex // guards breakpoints and pause, which change while the program runs
+	breakpoints map[int]bool
+	pause       bool
+	frames      []*Frame
+	mode        mode
+	stepDepth   int
+	stepLine    int
+	stepStmt    ast.Statement
+	evaluating  bool
+}
+
+// New returns a debugger that pauses before the first statement when
+// stopOnEntry is set
+func New(stopOnEntry bool) *Debugger {
+	d := &Debugger{breakpoints: map[int]bool{}}
+	if stopOnEntry {
+		d.mode = modeStepIn
+	}
+	return d
+}
+
+// Run evaluates program with the debugger attached
+func (d *Debugger) Run(program *ast.Program, env *object.Environment) object.Object {
+	evaluator.SetHook(d)
+	defer evaluator.SetHook(nil)
+
+	d.frames = []*Frame{{Name: "main", Env: env, base: env}}
+
+	return evaluator.Eval(program, env)
+}
+
+func (d *Debugger) SetBreakpoints(lines []int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.breakpoints = map[int]bool{}
+	for _, line := range lines {
+		d.breakpoints[line] = true
+	}
+}
+
+func (d *Debugger) Breakpoints() []int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	lines := make([]int, 0, len(d.breakpoints))
+	for line := range d.breakpoints {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+
+	return lines
+}
+
+// Pause asks the running program to pause before its next statement
+func (d *Debugger) Pause() {
+	d.mu.Lock()
+	d.pause = true
+	d.mu.Unlock()
+}
+
+func (d *Debugger) Continue() { d.mode = modeRun }
+func (d *Debugger) StepIn()   { d.step(modeStepIn) }
+func (d *Debugger) StepOver() { d.step(modeStepOver) }
+func (d *Debugger) StepOut()  { d.step(modeStepOut) }
+
+func (d *Debugger) step(m mode) {
+	d.mode = m
+	d.stepDepth = len(d.frames)
+	d.stepLine = d.frames[len(d.frames)-1].Line
+	d.stepStmt = d.frames[len(d.frames)-1].stmt
+}
+
+// Frames returns the call stack, innermost first
+func (d *Debugger) Frames() []Frame {
+	frames := make([]Frame, len(d.frames))
+	for i, frame := range d.frames {
+		frames[len(frames)-1-i] = *frame
+	}
+	return frames
+}
+
+// Locals returns the variables in scope in a frame of Frames, up to the
+// scope of its call, inner scopes first
+func (d *Debugger) Locals(frame int) []Variable {
+	f := d.frame(frame)
+	if f == nil {
+		return nil
+	}
+
+	var vars []Variable
+	seen := map[string]bool{}
+
+	for env := f.Env; env != nil; env = env.Outer() {
+		for _, name := range env.Names() {
+			if seen[name] || evaluator.IsInternalName(name) {
+				continue
+			}
+			seen[name] = true
+
+			meta, _ := env.Get(name)
+			vars = append(vars, Variable{Name: name, Value: meta.Object})
+		}
+		if env == f.base {
+			break
+		}
+	}
+
+	return vars
+}
+
+// Globals returns the variables of the program's top level
+func (d *Debugger) Globals() []Variable {
+	if len(d.frames) == 0 {
+		return nil
+	}
+	return d.Locals(len(d.frames) - 1)
+}
+
+// Evaluate runs code in the scope of a frame of Frames, without pausing
+func (d *Debugger) Evaluate(frame int, code string) (object.Object, error) {
+	f := d.frame(frame)
+	if f == nil {
+		return nil, errors.New("no such frame")
+	}
+
+	p := parser.New(lexer.New(code))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, errors.New(strings.Join(p.Errors(), "; "))
+	}
+
+	d.evaluating = true
+	defer func() { d.evaluating = false }()
+
+	result := evaluator.Eval(program, f.Env)
+	if result == nil {
+		result = object.NULL
+	}
+
+	return result, nil
+}
+
+func (d *Debugger) frame(i int) *Frame {
+	if i < 0 || i >= len(d.frames) {
+		return nil
+	}
+	return d.frames[len(d.frames)-1-i]
+}
+
+func (d *Debugger) Statement(stmt ast.Statement, env *object.Environment) {
+	if d.evaluating {
+		return
+	}
+
+	// a line is paused on once, unless a loop comes back to the statement,
+	// so `if (x) { y }` doesn't pause twice
+	frame := d.frames[len(d.frames)-1]
+	tok := ast.Start(stmt)
+	moved := tok.Line != frame.Line || stmt == frame.stmt
+	frame.Line, frame.Column, frame.Env, frame.stmt = tok.Line, tok.Column, env, stmt
+
+	d.mu.Lock()
+	breakpoint := d.breakpoints[tok.Line] && moved
+	pause := d.pause
+	d.pause = false
+	d.mu.Unlock()
+
+	depth := len(d.frames)
+	stepped := depth != d.stepDepth || tok.Line != d.stepLine || stmt == d.stepStmt
+
+	reason := ""
+	switch {
+	case d.mode == modeStepIn && stepped:
+		reason = ReasonStep
+	case d.mode == modeStepOver && depth <= d.stepDepth && stepped:
+		reason = ReasonStep
+	case d.mode == modeStepOut && depth < d.stepDepth:
+		reason = ReasonStep
+	case breakpoint:
+		reason = ReasonBreakpoint
+	case pause:
+		reason = ReasonPause
+	}
+
+	if reason == "" {
+		return
+	}
+	if d.stepDepth == 0 && d.mode == modeStepIn {
+		reason = ReasonEntry
+	}
+
+	d.mode = modeRun
+	if d.OnPause != nil {
+		d.OnPause(reason)
+	}
+}
+
+func (d *Debugger) Call(fn *object.Function, env *object.Environment) {
+	if d.evaluating {
+		return
+	}
+
+	name := fn.Name
+	if name == "" {
+		name = "fn"
+	}
+	d.frames = append(d.frames, &Frame{Name: name, Env: env, base: env})
+}
+
+func (d *Debugger) Return(fn *object.Function) {
+	if d.evaluating {
+		return
+	}
+	d.frames = d.frames[:len(d.frames)-1]
+}
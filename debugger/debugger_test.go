@@ -0,0 +1,180 @@
+package debugger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/object"
+	"github.com/joshuahenriques/cixac/parser"
+)
+
+const program = `let add = fn(a, b) {
+	let sum = a + b
+	return sum
+}
+let total = 0
+for (let i = 0; i < 3; i++) {
+	total = add(total, i)
+}
+if (total > 1) { total = total * 2 }
+total`
+
+type stop struct {
+	reason string
+	line   int
+	frames int
+}
+
+// debug runs program, resuming each pause with the next of actions
+func debug(t *testing.T, breakpoints []int, actions ...func(d *Debugger)) []stop {
+	t.Helper()
+
+	p := parser.New(lexer.New(program))
+	prog := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parse errors: %v", p.Errors())
+	}
+
+	var stops []stop
+
+	d := New(true)
+	d.SetBreakpoints(breakpoints)
+	d.OnPause = func(reason string) {
+		frames := d.Frames()
+		stops = append(stops, stop{reason, frames[0].Line, len(frames)})
+		if len(stops) <= len(actions) {
+			actions[len(stops)-1](d)
+		}
+	}
+
+	result := d.Run(prog, object.NewEnvironment())
+	if result.Inspect() != "6" {
+		t.Errorf("program result wrong. expected=6, got=%s", result.Inspect())
+	}
+
+	return stops
+}
+
+func TestStepping(t *testing.T) {
+	cont := (*Debugger).Continue
+	stepIn := (*Debugger).StepIn
+	stepOver := (*Debugger).StepOver
+	stepOut := (*Debugger).StepOut
+
+	tests := []struct {
+		name        string
+		breakpoints []int
+		actions     []func(d *Debugger)
+		expected    []stop
+	}{
+		{"entry", nil, []func(*Debugger){cont}, []stop{{"entry", 1, 1}}},
+		{"breakpoints", []int{3, 9}, []func(*Debugger){cont, cont, cont, cont, cont},
+			[]stop{{"entry", 1, 1}, {"breakpoint", 3, 2}, {"breakpoint", 3, 2}, {"breakpoint", 3, 2}, {"breakpoint", 9, 1}}},
+		{"step over", nil, []func(*Debugger){stepOver, stepOver, stepOver, stepOver, cont},
+			[]stop{{"entry", 1, 1}, {"step", 5, 1}, {"step", 6, 1}, {"step", 7, 1}, {"step", 7, 1}}},
+		{"step in and out", []int{7}, []func(*Debugger){cont, stepIn, stepIn, stepOut, func(d *Debugger) { d.SetBreakpoints(nil) }},
+			[]stop{{"entry", 1, 1}, {"breakpoint", 7, 1}, {"step", 2, 2}, {"step", 3, 2}, {"step", 7, 1}}},
+		{"one line if pauses once", []int{9}, []func(*Debugger){cont, cont},
+			[]stop{{"entry", 1, 1}, {"breakpoint", 9, 1}}},
+	}
+
+	for _, tt := range tests {
+		stops := debug(t, tt.breakpoints, tt.actions...)
+
+		if len(stops) != len(tt.expected) {
+			t.Errorf("%s: wrong stops. expected=%v, got=%v", tt.name, tt.expected, stops)
+			continue
+		}
+
+		for i, s := range stops {
+			if s != tt.expected[i] {
+				t.Errorf("%s: stop %d wrong. expected=%v, got=%v", tt.name, i, tt.expected[i], s)
+			}
+		}
+	}
+}
+
+func TestInspectPausedFrame(t *testing.T) {
+	debug(t, []int{3}, (*Debugger).Continue, func(d *Debugger) {
+		frames := d.Frames()
+		if frames[0].Name != "add" || frames[1].Name != "main" {
+			t.Errorf("wrong stack. got=%s, %s", frames[0].Name, frames[1].Name)
+		}
+
+		var locals []string
+		for _, v := range d.Locals(0) {
+			locals = append(locals, v.Name+"="+v.Value.Inspect())
+		}
+		if strings.Join(locals, " ") != "a=0 b=0 sum=0" {
+			t.Errorf("wrong locals. got=%v", locals)
+		}
+
+		var outer []string
+		for _, v := range d.Locals(1) {
+			outer = append(outer, v.Name)
+		}
+		if strings.Join(outer, " ") != "i add total" {
+			t.Errorf("wrong locals of main. got=%v", outer)
+		}
+
+		result, err := d.Evaluate(0, "a + b + 10")
+		if err != nil || result.Inspect() != "10" {
+			t.Errorf("wrong evaluation. got=%v, %v", result, err)
+		}
+
+		result, err = d.Evaluate(1, "total + i")
+		if err != nil || result.Inspect() != "0" {
+			t.Errorf("wrong evaluation in main. got=%v, %v", result, err)
+		}
+
+		if _, err := d.Evaluate(0, "let = 1"); err == nil {
+			t.Errorf("expected a parse error")
+		}
+
+		d.SetBreakpoints(nil)
+	})
+}
+
+func TestConsole(t *testing.T) {
+	input := "b 3\nc\nbt\nlocals\np sum + 1\nq\n"
+
+	var out bytes.Buffer
+	status := RunConsole("main.cx", program, strings.NewReader(input), &out)
+
+	expected := `paused at main.cx:1 (entry) in main
+>    1	let add = fn(a, b) {
+(debug) breakpoint at line 3
+(debug) paused at main.cx:3 (breakpoint) in add
+>    3		return sum
+(debug) * 0 add at main.cx:3
+  1 main at main.cx:7
+(debug) a = 0
+b = 0
+sum = 0
+(debug) 1
+(debug) `
+
+	if status != 0 {
+		t.Errorf("wrong status. got=%d", status)
+	}
+
+	if out.String() != expected {
+		t.Errorf("wrong output.\nexpected=%q\ngot=%q", expected, out.String())
+	}
+}
+
+func TestConsoleExit(t *testing.T) {
+	var out bytes.Buffer
+	status := RunConsole("main.cx", "let x = 1\nx + \"a\" - 1", strings.NewReader("c\n"), &out)
+
+	if status != 1 || !strings.HasSuffix(out.String(), "ERROR: unknown operator: STRING - STRING\n") {
+		t.Errorf("expected the runtime error. status=%d, output=%q", status, out.String())
+	}
+
+	out.Reset()
+	if status := RunConsole("main.cx", "let = 1", strings.NewReader(""), &out); status != 2 {
+		t.Errorf("expected status 2 for a parse error. got=%d", status)
+	}
+}
@@ -0,0 +1,313 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+// Failed assertions are errors whose message starts with the name of the
+// assertion, so they stop the test like any other error
+const assertPrefix = "assert."
+
+func init() {
+	registerModule(&object.Module{
+		Name: "assert",
+		Members: map[string]object.Object{
+			"equal":     &object.Builtin{Fn: assertEqual},
+			"notEqual":  &object.Builtin{Fn: assertNotEqual},
+			"deepEqual": &object.Builtin{Fn: assertDeepEqual},
+			"approx":    &object.Builtin{Fn: assertApprox},
+			"raises":    &object.Builtin{Fn: assertRaises},
+		},
+	})
+}
+
+// IsAssertionError reports whether obj is the error of a failed assertion
+// rather than one the program ran into
+func IsAssertionError(obj object.Object) bool {
+	err, ok := obj.(*object.Error)
+	return ok && strings.HasPrefix(err.Message, assertPrefix)
+}
+
+func assertionError(name string, message object.Object, format string, a ...interface{}) *object.Error {
+	msg := fmt.Sprintf(format, a...)
+	if message != nil {
+		// values a string can't be joined with are shown as they print
+		if str := convertToString(message); str != nil {
+			msg = str.Inspect() + ": " + msg
+		} else {
+			msg = message.Inspect() + ": " + msg
+		}
+	}
+	return newError("%s%s failed: %s", assertPrefix, name, msg)
+}
+
+// assertArgs checks for want arguments and an optional message after them
+func assertArgs(args []object.Object, want int) (object.Object, *object.Error) {
+	if len(args) != want && len(args) != want+1 {
+		return nil, newError("wrong number of arguments. got=%d, want=%d", len(args), want)
+	}
+	if len(args) == want+1 {
+		return args[want], nil
+	}
+	return nil, nil
+}
+
+func assertEqual(args ...object.Object) object.Object {
+	message, err := assertArgs(args, 2)
+	if err != nil {
+		return err
+	}
+
+	actual, expected := args[0], args[1]
+	if valuesEqual(actual, expected) {
+		return NULL
+	}
+
+	if deepEqual(actual, expected) {
+		return assertionError("equal", message, "expected %s, got %s (a different %s with the same contents, compare them with assert.deepEqual)", formatValue(expected), formatValue(actual), actual.Type())
+	}
+	return assertionError("equal", message, "expected %s, got %s", formatValue(expected), formatValue(actual))
+}
+
+func assertNotEqual(args ...object.Object) object.Object {
+	message, err := assertArgs(args, 2)
+	if err != nil {
+		return err
+	}
+
+	if valuesEqual(args[0], args[1]) {
+		return assertionError("notEqual", message, "expected a value other than %s", formatValue(args[1]))
+	}
+	return NULL
+}
+
+func assertDeepEqual(args ...object.Object) object.Object {
+	message, err := assertArgs(args, 2)
+	if err != nil {
+		return err
+	}
+
+	actual, expected := args[0], args[1]
+
+	var diffs []string
+	diffValues("", actual, expected, &diffs)
+	if len(diffs) == 0 {
+		return NULL
+	}
+
+	if len(diffs) == 1 && !strings.HasPrefix(diffs[0], "[") {
+		return assertionError("deepEqual", message, "%s", diffs[0])
+	}
+	return assertionError("deepEqual", message, "values differ\n  %s", strings.Join(diffs, "\n  "))
+}
+
+func assertApprox(args ...object.Object) object.Object {
+	if len(args) < 2 || len(args) > 4 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+
+	var message object.Object
+	tolerance := 1e-9
+
+	if len(args) > 2 {
+		tol, ok := toFloat(args[2])
+		if !ok {
+			return newError("tolerance to `approx` must be INTEGER or FLOAT, got %s", args[2].Type())
+		}
+		tolerance = tol
+	}
+	if len(args) == 4 {
+		message = args[3]
+	}
+
+	actual, ok := toFloat(args[0])
+	if !ok {
+		return newError("argument to `approx` must be INTEGER or FLOAT, got %s", args[0].Type())
+	}
+	expected, ok := toFloat(args[1])
+	if !ok {
+		return newError("argument to `approx` must be INTEGER or FLOAT, got %s", args[1].Type())
+	}
+
+	if math.Abs(actual-expected) > tolerance {
+		return assertionError("approx", message, "expected %s ± %g, got %s", formatValue(args[1]), tolerance, formatValue(args[0]))
+	}
+	return NULL
+}
+
+// assertRaises calls fn and passes when it returns an error, optionally one
+// containing a substring. It returns the message of the error.
+func assertRaises(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	switch fn := args[0].(type) {
+	case *object.Function:
+		if len(fn.Parameters) != 0 {
+			return newError("function passed to `raises` must take no arguments, got %d", len(fn.Parameters))
+		}
+	case *object.Builtin:
+	default:
+		return newError("argument to `raises` must be FUNCTION, got %s", args[0].Type())
+	}
+
+	var substring string
+	if len(args) == 2 {
+		str, ok := args[1].(*object.String)
+		if !ok {
+			return newError("second argument to `raises` must be STRING, got %s", args[1].Type())
+		}
+		substring = str.Value
+	}
+
+	result := applyFunction(args[0], nil)
+
+	err, ok := result.(*object.Error)
+	if !ok {
+		return assertionError("raises", nil, "expected an error, got %s", formatValue(result))
+	}
+
+	if !strings.Contains(err.Message, substring) {
+		return assertionError("raises", nil, "expected an error containing %q, got %q", substring, err.Message)
+	}
+
+	return &object.String{Value: err.Message}
+}
+
+// valuesEqual is == restricted to values of the same type, so that 1 and "1"
+// differ. Numbers compare across integers and floats.
+func valuesEqual(a, b object.Object) bool {
+	_, aNumber := toFloat(a)
+	_, bNumber := toFloat(b)
+
+	if a.Type() != b.Type() && !(aNumber && bNumber) {
+		return false
+	}
+
+	switch a.(type) {
+	case *object.Array, *object.Hash, *object.Function, *object.Builtin, *object.Module:
+		return a == b
+	}
+
+	result, ok := evalInfixExpression("==", a, b).(*object.Boolean)
+	return ok && result.Value
+}
+
+func deepEqual(a, b object.Object) bool {
+	var diffs []string
+	diffValues("", a, b, &diffs)
+	return len(diffs) == 0
+}
+
+// diffValues appends a line for each difference between actual and expected,
+// prefixed with the index path to it
+func diffValues(path string, actual, expected object.Object, diffs *[]string) {
+	switch expected := expected.(type) {
+	case *object.Array:
+		actual, ok := actual.(*object.Array)
+		if !ok {
+			break
+		}
+
+		for i := 0; i < len(expected.Elements) || i < len(actual.Elements); i++ {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(actual.Elements):
+				*diffs = append(*diffs, diffLine(elemPath, "missing, expected %s", formatValue(expected.Elements[i])))
+			case i >= len(expected.Elements):
+				*diffs = append(*diffs, diffLine(elemPath, "unexpected %s", formatValue(actual.Elements[i])))
+			default:
+				diffValues(elemPath, actual.Elements[i], expected.Elements[i], diffs)
+			}
+		}
+		return
+
+	case *object.Hash:
+		actual, ok := actual.(*object.Hash)
+		if !ok {
+			break
+		}
+
+		for _, key := range sortedHashKeys(expected, actual) {
+			expectedPair, inExpected := expected.Pairs[key]
+			actualPair, inActual := actual.Pairs[key]
+
+			pair := expectedPair
+			if !inExpected {
+				pair = actualPair
+			}
+			keyPath := fmt.Sprintf("%s[%s]", path, formatValue(pair.Key))
+
+			switch {
+			case !inActual:
+				*diffs = append(*diffs, diffLine(keyPath, "missing, expected %s", formatValue(expectedPair.Value)))
+			case !inExpected:
+				*diffs = append(*diffs, diffLine(keyPath, "unexpected %s", formatValue(actualPair.Value)))
+			default:
+				diffValues(keyPath, actualPair.Value, expectedPair.Value, diffs)
+			}
+		}
+		return
+	}
+
+	if !valuesEqual(actual, expected) {
+		*diffs = append(*diffs, diffLine(path, "expected %s, got %s", formatValue(expected), formatValue(actual)))
+	}
+}
+
+func diffLine(path, format string, a ...interface{}) string {
+	if path == "" {
+		return fmt.Sprintf(format, a...)
+	}
+	return path + ": " + fmt.Sprintf(format, a...)
+}
+
+func sortedHashKeys(hashes ...*object.Hash) []object.HashKey {
+	seen := map[object.HashKey]string{}
+	var keys []object.HashKey
+
+	for _, hash := range hashes {
+		for key, pair := range hash.Pairs {
+			if _, ok := seen[key]; !ok {
+				seen[key] = formatValue(pair.Key)
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return seen[keys[i]] < seen[keys[j]] })
+
+	return keys
+}
+
+// formatValue quotes strings, including nested ones, prints floats in full
+// and sorts hash keys so messages are the same from run to run
+func formatValue(obj object.Object) string {
+	switch obj := obj.(type) {
+	case *object.String:
+		return strconv.Quote(obj.Value)
+	case *object.Float:
+		return strconv.FormatFloat(obj.Value, 'f', -1, 64)
+	case *object.Array:
+		elements := make([]string, len(obj.Elements))
+		for i, elem := range obj.Elements {
+			elements[i] = formatValue(elem)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *object.Hash:
+		pairs := make([]string, 0, len(obj.Pairs))
+		for _, key := range sortedHashKeys(obj) {
+			pair := obj.Pairs[key]
+			pairs = append(pairs, formatValue(pair.Key)+": "+formatValue(pair.Value))
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	}
+	return obj.Inspect()
+}
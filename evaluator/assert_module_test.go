@@ -0,0 +1,81 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func TestAssertModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string // the error message, empty when the assertion passes
+	}{
+		{`assert.equal(1 + 1, 2)`, ""},
+		{`assert.equal(1, 1.0)`, ""},
+		{`assert.equal("a", "a")`, ""},
+		{`assert.equal(null, null)`, ""},
+		{`assert.equal(1 + 1, 3)`, "assert.equal failed: expected 3, got 2"},
+		{`assert.equal("1", 1)`, "assert.equal failed: expected 1, got \"1\""},
+		{`assert.equal("a", "b")`, "assert.equal failed: expected \"b\", got \"a\""},
+		{`assert.equal(2, 3, "sum")`, "assert.equal failed: sum: expected 3, got 2"},
+		{`assert.equal(2, 3, null)`, "assert.equal failed: null: expected 3, got 2"},
+		{`assert.equal(2, 3, fn(x) { x })`, "assert.equal failed: fn(x) {\nx\n}: expected 3, got 2"},
+		{`assert.equal([1], [1])`, "assert.equal failed: expected [1], got [1] (a different ARRAY with the same contents, compare them with assert.deepEqual)"},
+		{`let a = [1]; assert.equal(a, a)`, ""},
+		{`assert.notEqual(1, 2)`, ""},
+		{`assert.notEqual("a", "a")`, "assert.notEqual failed: expected a value other than \"a\""},
+		{`assert.deepEqual([1, [2, "x"]], [1, [2, "x"]])`, ""},
+		{`assert.deepEqual({"a": [1], "b": 2}, {"b": 2, "a": [1]})`, ""},
+		{`assert.deepEqual(1, 2)`, "assert.deepEqual failed: expected 2, got 1"},
+		{`assert.deepEqual([1, 2, 3], [1, 5])`, "assert.deepEqual failed: values differ\n  [1]: expected 5, got 2\n  [2]: unexpected 3"},
+		{`assert.deepEqual({"a": {"b": 1}, "c": 2}, {"a": {"b": "1"}, "d": 2})`, "assert.deepEqual failed: values differ\n  [\"a\"][\"b\"]: expected \"1\", got 1\n  [\"c\"]: unexpected 2\n  [\"d\"]: missing, expected 2"},
+		{`assert.approx(0.1 + 0.2, 0.3)`, ""},
+		{`assert.approx(3.14, 3, 0.2)`, ""},
+		{`assert.approx(3.14, 3, 0.1)`, "assert.approx failed: expected 3 ± 0.1, got 3.14"},
+		{`assert.approx("a", 3)`, "argument to `approx` must be INTEGER or FLOAT, got STRING"},
+		{`assert.raises(fn() { 1 / 0 })`, ""},
+		{`assert.raises(fn() { 1 / 0 }, "division")`, ""},
+		{`assert.raises(fn() { 1 })`, "assert.raises failed: expected an error, got 1"},
+		{`assert.raises(fn() { 1 / 0 }, "type")`, "assert.raises failed: expected an error containing \"type\", got \"division by zero\""},
+		{`assert.raises(fn(x) { x })`, "function passed to `raises` must take no arguments, got 1"},
+		{`assert.equal(1)`, "wrong number of arguments. got=1, want=2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, isErr := evaluated.(*object.Error)
+		if tt.expected == "" {
+			if isErr {
+				t.Errorf("%s: unexpected error %q", tt.input, errObj.Message)
+			}
+			continue
+		}
+
+		if !isErr {
+			t.Errorf("%s: expected error %q, got=%s", tt.input, tt.expected, evaluated.Inspect())
+			continue
+		}
+
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error.\nexpected=%q\ngot=     %q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestAssertRaisesReturnsMessage(t *testing.T) {
+	evaluated := testEval(`assert.raises(fn() { 1 / 0 })`)
+
+	str, ok := evaluated.(*object.String)
+	if !ok || str.Value != "division by zero" {
+		t.Fatalf("expected the error message, got=%s", evaluated.Inspect())
+	}
+
+	if !IsAssertionError(testEval(`assert.equal(1, 2)`)) {
+		t.Errorf("failed assertion is not an assertion error")
+	}
+	if IsAssertionError(testEval(`1 / 0`)) {
+		t.Errorf("runtime error is an assertion error")
+	}
+}
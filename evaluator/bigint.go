@@ -0,0 +1,122 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+// Integers are int64 until an operation overflows, then they are promoted to
+// object.BigInteger. Results that fit in an int64 again are demoted, so a
+// BigInteger always holds a value outside the int64 range.
+
+// maxPowerBits is the largest result `**` computes, in bits. Bigger powers
+// take too long and too much memory to be worth waiting for.
+const maxPowerBits = 1 << 24
+
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIGINT_OBJ
+}
+
+func toBigInt(obj object.Object) *big.Int {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return big.NewInt(obj.Value)
+	case *object.BigInteger:
+		return obj.Value
+	default:
+		return nil
+	}
+}
+
+func toFloat(obj object.Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value), true
+	case *object.BigInteger:
+		f, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return f, true
+	case *object.Float:
+		return obj.Value, true
+	default:
+		return 0, false
+	}
+}
+
+func normalizeBigInteger(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+
+	return &object.BigInteger{Value: value}
+}
+
+// powerTooLarge reports whether base ** exp has more than maxPowerBits bits.
+// Bases of 0, 1 and -1 stay small whatever the exponent.
+func powerTooLarge(base, exp *big.Int) bool {
+	if new(big.Int).Abs(base).Cmp(big.NewInt(1)) <= 0 {
+		return false
+	}
+	if !exp.IsInt64() || exp.Int64() > maxPowerBits {
+		return true
+	}
+
+	// |base| is at least 2 ** (bits - 1)
+	return int64(base.BitLen()-1)*exp.Int64() > maxPowerBits
+}
+
+func evalBigIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toBigInt(left)
+	rightVal := toBigInt(right)
+
+	switch operator {
+	case "+":
+		return normalizeBigInteger(new(big.Int).Add(leftVal, rightVal))
+	case "-":
+		return normalizeBigInteger(new(big.Int).Sub(leftVal, rightVal))
+	case "*":
+		return normalizeBigInteger(new(big.Int).Mul(leftVal, rightVal))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+		return normalizeBigInteger(new(big.Int).Quo(leftVal, rightVal))
+	case "%":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+
+		// the sign follows the divisor, like modLikePython
+		rem := new(big.Int).Rem(leftVal, rightVal)
+		if rem.Sign() != 0 && rem.Sign() != rightVal.Sign() {
+			rem.Add(rem, rightVal)
+		}
+		return normalizeBigInteger(rem)
+	case "**":
+		if rightVal.Sign() < 0 {
+			base, _ := toFloat(left)
+			exp, _ := toFloat(right)
+			return &object.Float{Value: math.Pow(base, exp)}
+		}
+		if powerTooLarge(leftVal, rightVal) {
+			return newError("result of %s ** %s is too large", leftVal, rightVal)
+		}
+		return normalizeBigInteger(new(big.Int).Exp(leftVal, rightVal, nil))
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError("unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+}
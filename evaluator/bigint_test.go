@@ -0,0 +1,101 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func TestBigIntegerPromotion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775808 - 1", "-9223372036854775809"},
+		{"4611686018427387904 * 2", "9223372036854775808"},
+		{"-(-9223372036854775808)", "9223372036854775808"},
+		{"-9223372036854775808 / -1", "9223372036854775808"},
+		{"2 ** 64", "18446744073709551616"},
+		{"99999999999999999999", "99999999999999999999"},
+		{"99999999999999999999 * 99999999999999999999", "9999999999999999999800000000000000000001"},
+		{"let i = 9223372036854775807; i++; i", "9223372036854775808"},
+		{"let i = 9223372036854775807; i += 1; i", "9223372036854775808"},
+		{"let i = 9223372036854775807; i *= 4; i", "36893488147419103228"},
+		{"fn fact(n) { if (n <= 1) { return 1 } n * fact(n - 1) } fact(25)", "15511210043330985984000000"},
+		{"let a = 0; let b = 1; for (let i = 0; i < 100; i++) { let t = a + b; a = b; b = t }; a", "354224848179261915075"},
+		{`"big: " + 2 ** 70`, "big: 1180591620717411303424"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("[test: %d] wrong value. got=%s, want=%s", i, str.Value, expected)
+				}
+				continue
+			}
+
+			result, ok := evaluated.(*object.BigInteger)
+			if !ok {
+				t.Errorf("[test: %d] object is not BigInteger. got=%T (%+v)", i, evaluated, evaluated)
+				continue
+			}
+			if result.Inspect() != expected {
+				t.Errorf("[test: %d] wrong value. got=%s, want=%s", i, result.Inspect(), expected)
+			}
+		}
+	}
+}
+
+func TestBigIntegerDemotion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"(9223372036854775807 + 1) - 1", 9223372036854775807},
+		{"-9223372036854775808", -9223372036854775808},
+		{"(2 ** 64) / (2 ** 60)", 16},
+		{"(2 ** 64) % 10", 6},
+		{"(2 ** 64) % -10", -4},
+		{"-(2 ** 64) % 10", 4},
+		{"2 ** 64 > 2 ** 63", true},
+		{"2 ** 64 == 2 ** 64", true},
+		{"2 ** 64 == 1", false},
+		{"2 ** 64 + 0.5", 18446744073709551616.5},
+		{"math.abs(-(2 ** 64)) == 2 ** 64", true},
+		{`{2 ** 64: "a", -(2 ** 64): "b"}[2 ** 64]`, "a"},
+		{"1 / 0", "division by zero"},
+		{"2 ** 64 % 0", "division by zero"},
+		{"1 ** 100000000000", 1},
+		{"(-1) ** 100000000001", -1},
+		{"0 ** 100000000000", 0},
+		{"2 ** 100000000000", "result of 2 ** 100000000000 is too large"},
+		{"(2 ** 64) ** 1000000", "result of 18446744073709551616 ** 1000000 is too large"},
+		{"2 ** 99999999999999999999", "result of 2 ** 99999999999999999999 is too large"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, i, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, i, evaluated, expected)
+		case bool:
+			testBooleanObject(t, i, evaluated, expected)
+		case string:
+			if errObj, ok := evaluated.(*object.Error); ok {
+				if errObj.Message != expected {
+					t.Errorf("[test: %d] wrong error message. expected=%q, got=%q", i, expected, errObj.Message)
+				}
+				continue
+			}
+			testStringObject(t, evaluated, expected)
+		}
+	}
+}
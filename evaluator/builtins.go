@@ -2,6 +2,7 @@ package evaluator
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/joshuahenriques/cixac/object"
 )
@@ -28,12 +29,47 @@ var builtins = map[string]*object.Builtin{
 	"print": {
 		Fn: func(args ...object.Object) object.Object {
 			for _, arg := range args {
-				fmt.Println(arg.Inspect())
+				fmt.Fprintln(stdout, arg.Inspect())
 			}
 
 			return EMPTY
 		},
 	},
+	"input": {
+		Fn: inputBuiltin,
+	},
+	"decimal": {
+		Fn: decimalBuiltin,
+	},
+	"re": {
+		Fn: reBuiltin,
+	},
+	"typeof": {
+		Fn: typeofBuiltin,
+	},
+}
+
+// conversions are builtins that, unlike the others, variables may shadow, as
+// their names were free to use before they were added
+var conversions = map[string]*object.Builtin{
+	"int":   {Fn: intBuiltin},
+	"float": {Fn: floatBuiltin},
+	"str":   {Fn: strBuiltin},
+	"bool":  {Fn: boolBuiltin},
+	"array": {Fn: arrayBuiltin},
+}
+
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins)+len(conversions))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	for name := range conversions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
 }
 
 func ExistsInBuiltins(name string) bool {
@@ -0,0 +1,124 @@
+package evaluator
+
+import (
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/token"
+)
+
+type Position struct {
+	Line   int
+	Column int
+}
+
+func position(tok token.Token) Position {
+	return Position{Line: tok.Line, Column: tok.Column}
+}
+
+// Coverage counts how often the statements of a program run and which way
+// its branches go, keyed by the position the statement or branch starts at.
+type Coverage struct {
+	Statements map[Position]int
+	Branches   map[Position]*Branch
+
+	bodies map[*ast.BlockStatement]Position // loop bodies to their loops
+}
+
+// Branch counts the times each arm of an if expression ran, the last arm
+// being the else, written or not. For a loop the arms are the runs of the
+// body and the times the loop ended without running it, for a match the
+// arms of the match.
+type Branch struct {
+	Loop  bool
+	Match bool
+	Arms  []int
+}
+
+var coverage *Coverage
+
+// StartCoverage starts counting for program, the statements and branches it
+// has start at zero so the ones that never run are known
+func StartCoverage(program *ast.Program) *Coverage {
+	c := &Coverage{
+		Statements: map[Position]int{},
+		Branches:   map[Position]*Branch{},
+		bodies:     map[*ast.BlockStatement]Position{},
+	}
+
+	ast.Inspect(program, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.Program:
+			c.addStatements(node.Statements)
+		case *ast.BlockStatement:
+			c.addStatements(node.Statements)
+		case *ast.IfExpression:
+			c.Branches[position(node.Token)] = &Branch{Arms: make([]int, len(node.Conditions)+1)}
+		case *ast.MatchExpression:
+			c.Branches[position(node.Token)] = &Branch{Match: true, Arms: make([]int, len(node.Arms))}
+		case *ast.WhileStatement:
+			c.addLoop(node.Token, node.Body)
+		case *ast.ForLoopStatement:
+			c.addLoop(node.Token, node.Body)
+		case *ast.ForInLoopStatement:
+			c.addLoop(node.Token, node.Body)
+		}
+		return true
+	})
+
+	coverage = c
+	return c
+}
+
+// StopCoverage stops counting, the counts stay in the Coverage
+func StopCoverage() {
+	coverage = nil
+}
+
+func (c *Coverage) addStatements(stmts []ast.Statement) {
+	for _, stmt := range stmts {
+		c.Statements[position(ast.Start(stmt))] = 0
+	}
+}
+
+func (c *Coverage) addLoop(tok token.Token, body *ast.BlockStatement) {
+	c.Branches[position(tok)] = &Branch{Loop: true, Arms: make([]int, 2)}
+	if body != nil {
+		c.bodies[body] = position(tok)
+	}
+}
+
+// statement ignores statements that aren't part of the program, such as ones
+// a debugger evaluates
+func (c *Coverage) statement(stmt ast.Statement) {
+	pos := position(ast.Start(stmt))
+	if _, ok := c.Statements[pos]; ok {
+		c.Statements[pos]++
+	}
+}
+
+func (c *Coverage) block(block *ast.BlockStatement) {
+	if pos, ok := c.bodies[block]; ok {
+		c.Branches[pos].Arms[0]++
+	}
+}
+
+func (c *Coverage) branch(tok token.Token, arm int) {
+	if b, ok := c.Branches[position(tok)]; ok && arm < len(b.Arms) {
+		b.Arms[arm]++
+	}
+}
+
+// loop is called when a loop starts, the func it returns when it ends to
+// count the loop as skipped when its body didn't run
+func (c *Coverage) loop(tok token.Token) func() {
+	b, ok := c.Branches[position(tok)]
+	if !ok {
+		return func() {}
+	}
+	before := b.Arms[0]
+
+	return func() {
+		if b.Arms[0] == before {
+			b.Arms[1]++
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package evaluator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/object"
+	"github.com/joshuahenriques/cixac/parser"
+)
+
+func TestCoverage(t *testing.T) {
+	input := `fn sign(n) {
+	if (n < 0) { return -1 } else if (n == 0) { return 0 }
+	return 1
+}
+let skipped = fn() { 1 }
+for (let i = 0; i < 3; i++) { sign(i) }
+let n = 0
+while (n > 0) { n-- }
+for (k, v in [1, 2]) { sign(-v) }
+let m = match n { 0 => "zero", _ => "other" }`
+
+	program := parser.New(lexer.New(input)).ParseProgram()
+
+	c := StartCoverage(program)
+	Eval(program, object.NewEnvironment())
+	StopCoverage()
+
+	expectedStatements := map[Position]int{
+		{1, 1}:   1,
+		{2, 2}:   5,
+		{2, 15}:  2,
+		{2, 46}:  1,
+		{3, 2}:   2,
+		{5, 1}:   1,
+		{5, 22}:  0,
+		{6, 1}:   1,
+		{6, 31}:  3,
+		{7, 1}:   1,
+		{8, 1}:   1,
+		{8, 17}:  0,
+		{9, 1}:   1,
+		{9, 24}:  2,
+		{10, 1}:  1,
+		{10, 24}: 1,
+		{10, 37}: 0,
+	}
+	if !reflect.DeepEqual(c.Statements, expectedStatements) {
+		t.Errorf("wrong statement counts.\nexpected=%v\ngot=     %v", expectedStatements, c.Statements)
+	}
+
+	expectedBranches := map[Position]*Branch{
+		{2, 2}:  {Arms: []int{2, 1, 2}},
+		{6, 1}:  {Loop: true, Arms: []int{3, 0}},
+		{8, 1}:  {Loop: true, Arms: []int{0, 1}},
+		{9, 1}:  {Loop: true, Arms: []int{2, 0}},
+		{10, 9}: {Match: true, Arms: []int{1, 0}},
+	}
+	if !reflect.DeepEqual(c.Branches, expectedBranches) {
+		t.Errorf("wrong branch counts.")
+		for pos, b := range c.Branches {
+			t.Errorf("%v: %+v", pos, *b)
+		}
+	}
+
+	// statements of other programs aren't counted
+	testEval("let a = 1")
+	if len(c.Statements) != len(expectedStatements) {
+		t.Errorf("counted after StopCoverage")
+	}
+}
@@ -0,0 +1,132 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func toDecimal(obj object.Object) (*object.Decimal, bool) {
+	switch obj := obj.(type) {
+	case *object.Decimal:
+		return obj, true
+	case *object.Integer:
+		return object.NewDecimal(big.NewInt(obj.Value)), true
+	case *object.BigInteger:
+		return object.NewDecimal(obj.Value), true
+	default:
+		return nil, false
+	}
+}
+
+func evalDecimalInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal, _ := toDecimal(left)
+	rightVal, _ := toDecimal(right)
+
+	switch operator {
+	case "+":
+		return leftVal.Add(rightVal)
+	case "-":
+		return leftVal.Sub(rightVal)
+	case "*":
+		return leftVal.Mul(rightVal)
+	case "/":
+		scale := max(leftVal.Scale, rightVal.Scale, object.DecimalDivisionScale)
+		quo, err := leftVal.Quo(rightVal, scale, object.ROUND_HALF_EVEN)
+		if err != nil {
+			return newError("%s", err)
+		}
+		return quo
+	case "%":
+		mod, err := leftVal.Mod(rightVal)
+		if err != nil {
+			return newError("%s", err)
+		}
+		return mod
+	case "**":
+		exp, ok := right.(*object.Integer)
+		if !ok || exp.Value < 0 {
+			return newError("exponent of DECIMAL must be a non-negative INTEGER")
+		}
+
+		power := big.NewInt(exp.Value)
+		if powerTooLarge(leftVal.Value, power) {
+			return newError("result of %s ** %d is too large", leftVal.Inspect(), exp.Value)
+		}
+		// (v * 10^-s) ** e is v ** e * 10^-(s * e)
+		if leftVal.Scale > 0 && exp.Value > math.MaxInt32/int64(leftVal.Scale) {
+			return newError("scale of %s ** %d must be at most %d", leftVal.Inspect(), exp.Value, math.MaxInt32)
+		}
+
+		return &object.Decimal{
+			Value: new(big.Int).Exp(leftVal.Value, power, nil),
+			Scale: leftVal.Scale * int32(exp.Value),
+		}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError("unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+}
+
+func decimalBuiltin(args ...object.Object) object.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+
+	var dec *object.Decimal
+
+	switch arg := args[0].(type) {
+	case *object.String:
+		parsed, ok := object.ParseDecimal(arg.Value)
+		if !ok {
+			return newError("could not parse %q as decimal", arg.Value)
+		}
+		dec = parsed
+	case *object.Float:
+		// the shortest representation, so decimal(0.1) is 0.1 and not the
+		// binary approximation of it
+		parsed, ok := object.ParseDecimal(strconv.FormatFloat(arg.Value, 'f', -1, 64))
+		if !ok {
+			return newError("could not convert %s to decimal", arg.Inspect())
+		}
+		dec = parsed
+	default:
+		converted, ok := toDecimal(arg)
+		if !ok {
+			return newError("argument to `decimal` not supported, got %s", args[0].Type())
+		}
+		dec = converted
+	}
+
+	if len(args) == 2 {
+		scale, ok := args[1].(*object.Integer)
+		if !ok || scale.Value < 0 {
+			return newError("scale for `decimal` must be a non-negative INTEGER")
+		}
+		if scale.Value > math.MaxInt32 {
+			return newError("scale for `decimal` must be at most %d, got %d", math.MaxInt32, scale.Value)
+		}
+
+		rounded, err := dec.Round(int32(scale.Value), object.ROUND_HALF_EVEN)
+		if err != nil {
+			return newError("%s", err)
+		}
+		dec = rounded
+	}
+
+	return dec
+}
@@ -0,0 +1,116 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func TestDecimalArithmetic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0.1d + 0.2d", "0.3"},
+		{"0.1d + 0.2d == 0.3d", "true"},
+		{"12.50d", "12.50"},
+		{"12.50d * 3", "37.50"},
+		{"1.1d * 1.1d", "1.21"},
+		{"19.99d - 20", "-0.01"},
+		{"-0.05d", "-0.05"},
+		{"10d / 4", "2.5000000000000000"},
+		{"10d / 3", "3.3333333333333333"},
+		{"2d / 3", "0.6666666666666667"},
+		{"7.5d % 2", "1.5"},
+		{"-1.5d % 1", "0.5"},
+		{"1.5d ** 2", "2.25"},
+		{"1.5d ** 0", "1"},
+		{"-0.1d ** 3", "-0.001"},
+		{"1d ** 100000000", "1"},
+		{"0.1d ** 10", "0.0000000001"},
+		{"1.5d < 1.51d", "true"},
+		{"1.5d == 1.50d", "true"},
+		{"2 ** 64 + 0.5d", "18446744073709551616.5"},
+		{`"total: " + 9.90d`, "total: 9.90"},
+		{"let t = 0d; for (let i = 0; i < 10; i++) { t += 0.1d }; t", "1.0"},
+		{`{1.5d: "a"}[1.50d]`, "a"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("[test: %d] wrong result for %q. got=%v, want=%s", i, tt.input, evaluated, tt.expected)
+		}
+	}
+}
+
+func TestDecimalRounding(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2.345d.round(2)", "2.34"},
+		{"2.355d.round(2)", "2.36"},
+		{`2.345d.round(2, "half_up")`, "2.35"},
+		{`2.345d.round(2, "half_down")`, "2.34"},
+		{`2.341d.round(2, "up")`, "2.35"},
+		{`2.349d.round(2, "down")`, "2.34"},
+		{`(-2.341d).round(2, "ceiling")`, "-2.34"},
+		{`(-2.341d).round(2, "floor")`, "-2.35"},
+		{`(-2.345d).round(2, "half_up")`, "-2.35"},
+		{"2.5d.round(4)", "2.5000"},
+		{"(10d / 3).round(2)", "3.33"},
+		{"10d.div(3, 2)", "3.33"},
+		{`10d.div(3, 2, "up")`, "3.34"},
+		{`100d.div(7, 0, "floor")`, "14"},
+		{"12.50d.scale()", "2"},
+		{`decimal("19.99")`, "19.99"},
+		{`decimal(0.1)`, "0.1"},
+		{`decimal(42)`, "42"},
+		{`decimal("2.675", 2)`, "2.68"},
+		{`decimal(1, 2)`, "1.00"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("[test: %d] wrong result for %q. got=%v, want=%s", i, tt.input, evaluated, tt.expected)
+		}
+	}
+}
+
+func TestDecimalErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5d + 1.5", "type mismatch: DECIMAL + FLOAT"},
+		{"1.5 < 1.5d", "type mismatch: FLOAT < DECIMAL"},
+		{"1d / 0", "division by zero"},
+		{"1d % 0d", "division by zero"},
+		{"1.5d ** 0.5d", "exponent of DECIMAL must be a non-negative INTEGER"},
+		{"2d ** 100000000", "result of 2 ** 100000000 is too large"},
+		{"1.5d ** 100000000", "result of 1.5 ** 100000000 is too large"},
+		{"0.1d ** 4294967296", "scale of 0.1 ** 4294967296 must be at most 2147483647"},
+		{`decimal("1.2.3")`, `could not parse "1.2.3" as decimal`},
+		{`decimal("abc")`, `could not parse "abc" as decimal`},
+		{`1.5d.round(-1)`, "scale for `round` must be a non-negative INTEGER"},
+		{`1.5d.round(4294967296)`, "scale for `round` must be at most 2147483647, got 4294967296"},
+		{`10d.div(3, 4294967297)`, "scale for `div` must be at most 2147483647, got 4294967297"},
+		{`decimal("1.5", 4294967297)`, "scale for `decimal` must be at most 2147483647, got 4294967297"},
+		{`1.55d.round(1, "sideways")`, `unknown rounding mode "sideways"`},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("[test: %d] no error object returned. got=%T(%+v)", i, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("[test: %d] wrong error message. expected=%q, got=%q", i, tt.expected, errObj.Message)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package evaluator
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"strconv"
+	"strings"
 
 	"github.com/beorn7/floats"
 	"github.com/joshuahenriques/cixac/ast"
@@ -12,15 +14,17 @@ import (
 
 // Avoid creating object.Boolean & object.Null every time
 var (
-	NULL                    = &object.Null{}
-	EMPTY                   = &object.Empty{}
-	TRUE                    = &object.Boolean{Value: true}
-	FALSE                   = &object.Boolean{Value: false}
+	NULL                    = object.NULL
+	EMPTY                   = object.EMPTY
+	TRUE                    = object.TRUE
+	FALSE                   = object.FALSE
 	BREAK                   = &object.Break{}
 	CONTINUE                = &object.Continue{}
 	ENV_FOR_FLAG            = "ENV_FOR_FLAG"
 	ENV_WHILE_FLAG          = "ENV_WHILE_FLAG"
 	ENV_OBJECT_BUILTIN_FLAG = "ENV_OBJECT_BUILTIN_FLAG"
+	ENV_DEFER_FLAG          = "ENV_DEFER_FLAG"
+	ENV_MATCH_FLAG          = "ENV_MATCH_FLAG"
 )
 
 func Eval(node ast.Node, env *object.Environment) object.Object {
@@ -59,6 +63,9 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(val) {
 			return val
 		}
+		if _, ok := node.Value.(*ast.FunctionLiteral); ok {
+			val.(*object.Function).Name = node.Name.Value
+		}
 
 		obj := object.ObjectMeta{Object: val, Const: node.Name.Const}
 		env.Set(node.Name.Value, obj)
@@ -76,6 +83,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(val) {
 			return val
 		}
+		val.(*object.Function).Name = node.Name.Value
 
 		env.Set(node.Name.Value, object.ObjectMeta{Object: val, Const: node.Name.Const})
 
@@ -98,69 +106,18 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return val
 		}
 
-		switch node.TokenLiteral() {
-		case "+=":
-			if obj.Object.Type() == object.INTEGER_OBJ {
-				if val.Type() == object.FLOAT_OBJ {
-					val.(*object.Float).Value = float64(obj.Object.(*object.Integer).Value) + val.(*object.Float).Value
-				} else {
-					val.(*object.Integer).Value = obj.Object.(*object.Integer).Value + val.(*object.Integer).Value
-				}
-			} else if obj.Object.Type() == object.FLOAT_OBJ {
-				if val.Type() == object.INTEGER_OBJ {
-					val = &object.Float{Value: obj.Object.(*object.Float).Value + float64(val.(*object.Integer).Value)}
-				} else {
-					val.(*object.Float).Value = obj.Object.(*object.Float).Value + val.(*object.Float).Value
-				}
-			}
-		case "-=":
-			if obj.Object.Type() == object.INTEGER_OBJ {
-				if val.Type() == object.FLOAT_OBJ {
-					val.(*object.Float).Value = float64(obj.Object.(*object.Integer).Value) - val.(*object.Float).Value
-				} else {
-					val.(*object.Integer).Value = obj.Object.(*object.Integer).Value - val.(*object.Integer).Value
-				}
-			} else if obj.Object.Type() == object.FLOAT_OBJ {
-				if val.Type() == object.INTEGER_OBJ {
-					val = &object.Float{Value: obj.Object.(*object.Float).Value - float64(val.(*object.Integer).Value)}
-				} else {
-					val.(*object.Float).Value = obj.Object.(*object.Float).Value - val.(*object.Float).Value
-				}
-			}
-		case "*=":
-			if obj.Object.Type() == object.INTEGER_OBJ {
-				if val.Type() == object.FLOAT_OBJ {
-					val.(*object.Float).Value = float64(obj.Object.(*object.Integer).Value) * val.(*object.Float).Value
-				} else {
-					val.(*object.Integer).Value = obj.Object.(*object.Integer).Value * val.(*object.Integer).Value
-				}
-			} else if obj.Object.Type() == object.FLOAT_OBJ {
-				if val.Type() == object.INTEGER_OBJ {
-					val = &object.Float{Value: obj.Object.(*object.Float).Value * float64(val.(*object.Integer).Value)}
-				} else {
-					val.(*object.Float).Value = obj.Object.(*object.Float).Value * val.(*object.Float).Value
-				}
-			}
-		case "/=":
-			if obj.Object.Type() == object.INTEGER_OBJ {
-				if val.Type() == object.FLOAT_OBJ {
-					val.(*object.Float).Value = float64(obj.Object.(*object.Integer).Value) / val.(*object.Float).Value
-				} else {
-					val = &object.Float{Value: float64(obj.Object.(*object.Integer).Value / val.(*object.Integer).Value)}
-				}
-			} else if obj.Object.Type() == object.FLOAT_OBJ {
-				if val.Type() == object.INTEGER_OBJ {
-					val = &object.Float{Value: obj.Object.(*object.Float).Value / float64(val.(*object.Integer).Value)}
-				} else {
-					val.(*object.Float).Value = obj.Object.(*object.Float).Value / val.(*object.Float).Value
-				}
+		if operator := strings.TrimSuffix(node.TokenLiteral(), "="); operator != "" {
+			val = evalCompoundAssignment(operator, obj.Object, val)
+			if isError(val) {
+				return val
 			}
 		}
 
-		if env.ExistsInScope(ENV_FOR_FLAG) && !env.ExistsInScope(node.Name.Value) && env.ExistsOutsideScope(node.Name.Value) {
-			env.SetOutsideScope(node.Name.Value, object.ObjectMeta{Object: val})
+		scope := assignmentScope(env, node.Name.Value)
+		if scope.ExistsInScope(ENV_FOR_FLAG) && !scope.ExistsInScope(node.Name.Value) && scope.ExistsOutsideScope(node.Name.Value) {
+			scope.SetOutsideScope(node.Name.Value, object.ObjectMeta{Object: val})
 		} else {
-			env.Set(node.Name.Value, object.ObjectMeta{Object: val})
+			scope.Set(node.Name.Value, object.ObjectMeta{Object: val})
 		}
 
 		return val
@@ -186,13 +143,26 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return CONTINUE
 
+	case *ast.DeferStatement:
+		return evalDeferStatement(node, env)
+
 	// Expressions
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
 
+	case *ast.BigIntegerLiteral:
+		return &object.BigInteger{Value: node.Value}
+
 	case *ast.FloatLiteral:
 		return &object.Float{Value: node.Value}
 
+	case *ast.DecimalLiteral:
+		dec, ok := object.ParseDecimal(node.Value)
+		if !ok {
+			return newError("could not parse %q as decimal", node.Value)
+		}
+		return dec
+
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 
@@ -237,6 +207,9 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 		return evalInfixExpression(node.Operator, left, right)
 
+	case *ast.IsExpression:
+		return evalIsExpression(node, env)
+
 	case *ast.PostfixExpression:
 		ident, ok := node.Left.(*ast.Identifier)
 		if !ok {
@@ -254,10 +227,11 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 		val, retVal := evalPostfixExpression(node.Operator, obj.Object)
 
-		if env.ExistsInScope(ENV_FOR_FLAG) && !env.ExistsInScope(ident.Value) && env.ExistsOutsideScope(ident.Value) {
-			env.SetOutsideScope(ident.Value, object.ObjectMeta{Object: val})
+		scope := assignmentScope(env, ident.Value)
+		if scope.ExistsInScope(ENV_FOR_FLAG) && !scope.ExistsInScope(ident.Value) && scope.ExistsOutsideScope(ident.Value) {
+			scope.SetOutsideScope(ident.Value, object.ObjectMeta{Object: val})
 		} else {
-			env.Set(ident.Value, object.ObjectMeta{Object: val})
+			scope.Set(ident.Value, object.ObjectMeta{Object: val})
 		}
 
 		return retVal
@@ -265,41 +239,29 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
 
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
+
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 
 	case *ast.CallExpression:
-		function := Eval(node.Function, env)
+		function, args := evalCallExpression(node, env)
 		if isError(function) {
 			return function
 		}
-		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
-		}
 		return applyFunction(function, args)
 
 	case *ast.BuiltinExpression:
-		left := Eval(node.Left, env)
-		if isError(left) {
-			return left
-		}
-
-		methEnv := object.NewEnclosedEnvironment(env)
-		methEnv.Set(ENV_OBJECT_BUILTIN_FLAG, object.ObjectMeta{Object: left})
-
-		method := Eval(node.Builtin.Function, methEnv)
+		method, args := evalBuiltinExpression(node, env)
 		if isError(method) {
 			return method
 		}
-
-		args := evalExpressions(node.Builtin.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
-		}
-		args = append([]object.Object{left}, args...)
 		return applyFunction(method, args)
 
+	case *ast.PropertyExpression:
+		return evalPropertyExpression(node, env)
+
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
@@ -316,10 +278,113 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	return nil
 }
 
+func evalCallExpression(node *ast.CallExpression, env *object.Environment) (object.Object, []object.Object) {
+	function := Eval(node.Function, env)
+	if isError(function) {
+		return function, nil
+	}
+
+	args := evalExpressions(node.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0], nil
+	}
+
+	return function, args
+}
+
+func evalBuiltinExpression(node *ast.BuiltinExpression, env *object.Environment) (object.Object, []object.Object) {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left, nil
+	}
+
+	methEnv := object.NewEnclosedEnvironment(env)
+	methEnv.Set(ENV_OBJECT_BUILTIN_FLAG, object.ObjectMeta{Object: left})
+
+	method := Eval(node.Builtin.Function, methEnv)
+	if isError(method) {
+		return method, nil
+	}
+
+	args := evalExpressions(node.Builtin.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0], nil
+	}
+
+	// module members are plain functions and don't take the module as receiver
+	if _, ok := left.(*object.Module); ok {
+		return method, args
+	}
+
+	return method, append([]object.Object{left}, args...)
+}
+
+func evalPropertyExpression(node *ast.PropertyExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	switch obj := left.(type) {
+	case *object.Module:
+		member, ok := obj.Methods(node.Property.Value)
+		if !ok {
+			return newError("%s has no member %s", obj.Name, node.Property.Value)
+		}
+		return member
+
+	case object.Propertied:
+		property, ok := obj.Property(node.Property.Value)
+		if !ok {
+			return newError("%s has no property %s", left.Type(), node.Property.Value)
+		}
+		return property
+
+	default:
+		return newError("property access not supported: %s", left.Type())
+	}
+}
+
+// The callee and arguments are evaluated now, the call runs when the
+// enclosing function returns
+func evalDeferStatement(node *ast.DeferStatement, env *object.Environment) object.Object {
+	frame, ok := env.Get(ENV_DEFER_FLAG)
+	if !ok {
+		return newError("defer not in function")
+	}
+
+	var function object.Object
+	var args []object.Object
+
+	switch call := node.Call.(type) {
+	case *ast.CallExpression:
+		function, args = evalCallExpression(call, env)
+	case *ast.BuiltinExpression:
+		function, args = evalBuiltinExpression(call, env)
+	default:
+		return newError("expression in defer must be function call")
+	}
+
+	if isError(function) {
+		return function
+	}
+
+	defers := frame.Object.(*object.DeferStack)
+	defers.Calls = append(defers.Calls, object.DeferredCall{Fn: function, Args: args})
+
+	return NULL
+}
+
 func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 	var result object.Object
 
 	for _, statement := range program.Statements {
+		if hook != nil {
+			hook.Statement(statement, env)
+		}
+		if coverage != nil {
+			coverage.statement(statement)
+		}
 		result = Eval(statement, env)
 
 		switch result := result.(type) {
@@ -336,9 +401,19 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
 	var result object.Object
 
+	if coverage != nil {
+		coverage.block(block)
+	}
+
 	forLoopFlag := env.ExistsInScope(ENV_FOR_FLAG)
 	whileLoopFlag := env.ExistsInScope(ENV_WHILE_FLAG)
 	for _, statement := range block.Statements {
+		if hook != nil {
+			hook.Statement(statement, env)
+		}
+		if coverage != nil {
+			coverage.statement(statement)
+		}
 		result = Eval(statement, env)
 
 		if result != nil {
@@ -371,6 +446,28 @@ func nativeNulltoNullObject() *object.Null {
 	return NULL
 }
 
+func evalCompoundAssignment(operator string, left, right object.Object) object.Object {
+	// only numbers are combined, anything else is replaced by the new value
+	if !isNumber(left) || !isNumber(right) {
+		return right
+	}
+
+	// /= on two integers has always produced a float of the integer quotient
+	if operator == "/" && left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ {
+		quotient := evalIntegerInfixExpression(operator, left, right)
+		if integer, ok := quotient.(*object.Integer); ok {
+			return &object.Float{Value: float64(integer.Value)}
+		}
+		return quotient
+	}
+
+	return evalInfixExpression(operator, left, right)
+}
+
+func isNumber(obj object.Object) bool {
+	return isInteger(obj) || obj.Type() == object.FLOAT_OBJ || obj.Type() == object.DECIMAL_OBJ
+}
+
 func evalPrefixExpression(operator string, right object.Object) object.Object {
 	switch operator {
 	case "!":
@@ -384,13 +481,12 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 
 func evalPostfixExpression(operator string, left object.Object) (object.Object, object.Object) {
 	switch left.Type() {
-	case object.INTEGER_OBJ:
-		objVal := left.(*object.Integer)
+	case object.INTEGER_OBJ, object.BIGINT_OBJ:
 		switch operator {
 		case "++":
-			return &object.Integer{Value: objVal.Value + 1}, objVal
+			return evalInfixExpression("+", left, &object.Integer{Value: 1}), left
 		case "--":
-			return &object.Integer{Value: objVal.Value - 1}, objVal
+			return evalInfixExpression("-", left, &object.Integer{Value: 1}), left
 		}
 
 	case object.FLOAT_OBJ:
@@ -427,7 +523,15 @@ func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
 
 	switch right.Type() {
 	case object.INTEGER_OBJ:
-		obj = &object.Integer{Value: -right.(*object.Integer).Value}
+		value := right.(*object.Integer).Value
+		if value == math.MinInt64 {
+			return normalizeBigInteger(new(big.Int).Neg(big.NewInt(value)))
+		}
+		obj = &object.Integer{Value: -value}
+	case object.BIGINT_OBJ:
+		obj = normalizeBigInteger(new(big.Int).Neg(right.(*object.BigInteger).Value))
+	case object.DECIMAL_OBJ:
+		obj = right.(*object.Decimal).Neg()
 	case object.FLOAT_OBJ:
 		obj = &object.Float{Value: -right.(*object.Float).Value}
 	default:
@@ -441,17 +545,34 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isInteger(left) && isInteger(right):
+		return evalBigIntegerInfixExpression(operator, left, right)
 	case (left.Type() == object.FLOAT_OBJ && right.Type() == object.FLOAT_OBJ) ||
-		(left.Type() == object.INTEGER_OBJ && right.Type() == object.FLOAT_OBJ) ||
-		(left.Type() == object.FLOAT_OBJ && right.Type() == object.INTEGER_OBJ):
+		(isInteger(left) && right.Type() == object.FLOAT_OBJ) ||
+		(left.Type() == object.FLOAT_OBJ && isInteger(right)):
 		return evalFloatInfixExpression(operator, left, right)
+	case (left.Type() == object.DECIMAL_OBJ && (right.Type() == object.DECIMAL_OBJ || isInteger(right))) ||
+		(isInteger(left) && right.Type() == object.DECIMAL_OBJ):
+		return evalDecimalInfixExpression(operator, left, right)
+	case (left.Type() == object.DECIMAL_OBJ && right.Type() == object.FLOAT_OBJ) ||
+		(left.Type() == object.FLOAT_OBJ && right.Type() == object.DECIMAL_OBJ):
+		// floats are inexact, so mixing them with decimals must be explicit
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	case (left.Type() == object.STRING_OBJ && isTimeOperand(right)) ||
+		(isTimeOperand(left) && right.Type() == object.STRING_OBJ):
+		return evalStringInfixExpression(operator, left, right)
+	case isTimeOperation(left, right):
+		return evalTimeInfixExpression(operator, left, right)
+	case (left.Type() == object.STRING_OBJ && right.Type() == object.DECIMAL_OBJ) ||
+		(left.Type() == object.DECIMAL_OBJ && right.Type() == object.STRING_OBJ):
+		return evalStringInfixExpression(operator, left, right)
 	case (left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ):
 		return evalStringInfixExpression(operator, left, right)
 	case (left.Type() == object.STRING_OBJ && right.Type() == object.FLOAT_OBJ) ||
 		(left.Type() == object.FLOAT_OBJ && right.Type() == object.STRING_OBJ):
 		return evalStringInfixExpression(operator, left, right)
-	case (left.Type() == object.STRING_OBJ && right.Type() == object.INTEGER_OBJ) ||
-		(left.Type() == object.INTEGER_OBJ && right.Type() == object.STRING_OBJ):
+	case (left.Type() == object.STRING_OBJ && isInteger(right)) ||
+		(isInteger(left) && right.Type() == object.STRING_OBJ):
 		return evalStringInfixExpression(operator, left, right)
 	case (left.Type() == object.STRING_OBJ && right.Type() == object.BOOLEAN_OBJ) ||
 		(left.Type() == object.BOOLEAN_OBJ && right.Type() == object.STRING_OBJ):
@@ -490,15 +611,41 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		sum := leftVal + rightVal
+		if (leftVal^sum)&(rightVal^sum) < 0 {
+			return evalBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: sum}
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		diff := leftVal - rightVal
+		if (leftVal^rightVal)&(leftVal^diff) < 0 {
+			return evalBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: diff}
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		product := leftVal * rightVal
+		if leftVal != 0 && (product/leftVal != rightVal || (leftVal == -1 && rightVal == math.MinInt64)) {
+			return evalBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: product}
 	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		if leftVal == math.MinInt64 && rightVal == -1 {
+			return evalBigIntegerInfixExpression(operator, left, right)
+		}
 		return &object.Integer{Value: leftVal / rightVal}
 	case "%":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
 		return &object.Integer{Value: modLikePython(leftVal, rightVal)}
+	case "**":
+		if rightVal < 0 {
+			return &object.Float{Value: math.Pow(float64(leftVal), float64(rightVal))}
+		}
+		return evalBigIntegerInfixExpression(operator, left, right)
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case "<=":
@@ -518,19 +665,8 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 }
 
 func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
-	var leftVal, rightVal float64
-
-	if left.Type() == object.INTEGER_OBJ {
-		leftVal = float64(left.(*object.Integer).Value)
-	} else {
-		leftVal = left.(*object.Float).Value
-	}
-
-	if right.Type() == object.INTEGER_OBJ {
-		rightVal = float64(right.(*object.Integer).Value)
-	} else {
-		rightVal = right.(*object.Float).Value
-	}
+	leftVal, _ := toFloat(left)
+	rightVal, _ := toFloat(right)
 
 	switch operator {
 	case "+":
@@ -543,6 +679,8 @@ func evalFloatInfixExpression(operator string, left, right object.Object) object
 		return &object.Float{Value: leftVal / rightVal}
 	case "%":
 		return &object.Float{Value: modLikePython(leftVal, rightVal)}
+	case "**":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case "<=":
@@ -578,6 +716,10 @@ func evalBooleanInfixExpression(operator string, left, right object.Object) obje
 func evalForInLoopStatement(fl *ast.ForInLoopStatement, env *object.Environment) object.Object {
 	var result object.Object
 
+	if coverage != nil {
+		defer coverage.loop(fl.Token)()
+	}
+
 	forEnv := object.NewEnclosedEnvironment(env)
 	forEnv.Set(ENV_FOR_FLAG, object.ObjectMeta{Object: TRUE})
 
@@ -602,6 +744,25 @@ func evalForInLoopStatement(fl *ast.ForInLoopStatement, env *object.Environment)
 
 			result = Eval(fl.Body, forEnv)
 
+			if result.Type() == object.BREAK_OBJ {
+				break
+			}
+		}
+	case *object.Iterator:
+		for i := 0; ; i++ {
+			ele, ok := iterable.Next()
+			if !ok {
+				break
+			}
+			if isError(ele) {
+				return ele
+			}
+
+			forEnv.Set(fl.KeyIndex.Value, object.ObjectMeta{Object: &object.Integer{Value: int64(i)}})
+			forEnv.Set(fl.ValueElement.Value, object.ObjectMeta{Object: ele})
+
+			result = Eval(fl.Body, forEnv)
+
 			if result.Type() == object.BREAK_OBJ {
 				break
 			}
@@ -626,6 +787,10 @@ func evalForInLoopStatement(fl *ast.ForInLoopStatement, env *object.Environment)
 func evalForLoopStatement(fl *ast.ForLoopStatement, env *object.Environment) object.Object {
 	var result object.Object
 
+	if coverage != nil {
+		defer coverage.loop(fl.Token)()
+	}
+
 	forEnv := object.NewEnclosedEnvironment(env)
 	Eval(fl.Initialization, forEnv)
 
@@ -647,6 +812,10 @@ func evalForLoopStatement(fl *ast.ForLoopStatement, env *object.Environment) obj
 func evalWhileStatement(w *ast.WhileStatement, env *object.Environment) object.Object {
 	var result object.Object
 
+	if coverage != nil {
+		defer coverage.loop(w.Token)()
+	}
+
 	env.Set(ENV_WHILE_FLAG, object.ObjectMeta{Object: TRUE})
 	for isTruthy(Eval(w.Condition, env)) {
 		result = Eval(w.Body, env)
@@ -661,17 +830,24 @@ func evalWhileStatement(w *ast.WhileStatement, env *object.Environment) object.O
 }
 
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
-	for _, con := range ie.Conditions {
+	for i, con := range ie.Conditions {
 		condition := Eval(con.Condition, env)
 		if isError(condition) {
 			return condition
 		}
 
 		if isTruthy(condition) {
+			if coverage != nil {
+				coverage.branch(ie.Token, i)
+			}
 			return Eval(con.Consequence, env)
 		}
 	}
 
+	if coverage != nil {
+		coverage.branch(ie.Token, len(ie.Conditions))
+	}
+
 	if ie.Alternative != nil {
 		return Eval(ie.Alternative, env)
 	} else {
@@ -704,10 +880,7 @@ func isError(obj object.Object) bool {
 }
 
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
-	if val, ok := env.Get(node.Value); ok {
-		return val.Object
-	}
-
+	// methods aren't shadowed by names in the environment
 	if env.ExistsInScope(ENV_OBJECT_BUILTIN_FLAG) {
 		envObj, _ := env.Get(ENV_OBJECT_BUILTIN_FLAG)
 		obj, ok := envObj.Object.(object.Methodable)
@@ -722,10 +895,22 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 		return newError("Method not found in object methods")
 	}
 
+	if val, ok := env.Get(node.Value); ok {
+		return val.Object
+	}
+
 	if builtin, ok := builtins[node.Value]; ok {
 		return builtin
 	}
 
+	if conversion, ok := conversions[node.Value]; ok {
+		return conversion
+	}
+
+	if module, ok := modules[node.Value]; ok {
+		return module
+	}
+
 	return newError("Identifier not found: " + node.Value)
 }
 
@@ -747,7 +932,20 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
 		extendedEnv := extendFunctionEnv(fn, args)
+
+		defers := &object.DeferStack{}
+		extendedEnv.Set(ENV_DEFER_FLAG, object.ObjectMeta{Object: defers})
+
+		if hook != nil {
+			hook.Call(fn, extendedEnv)
+			defer hook.Return(fn)
+		}
+
 		evaluated := Eval(fn.Body, extendedEnv)
+		if len(defers.Calls) > 0 {
+			evaluated = runDeferredCalls(defers, evaluated)
+		}
+
 		return unwrapReturnValue(evaluated)
 
 	case *object.Builtin:
@@ -758,6 +956,23 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
 	}
 }
 
+// Deferred calls run in LIFO order. A failing deferred call replaces the
+// function's result unless the function already failed
+func runDeferredCalls(defers *object.DeferStack, result object.Object) object.Object {
+	for i := len(defers.Calls) - 1; i >= 0; i-- {
+		call := defers.Calls[i]
+
+		val := applyFunction(call.Fn, call.Args)
+		if isError(val) && !isError(result) {
+			result = val
+		}
+	}
+
+	defers.Calls = nil
+
+	return result
+}
+
 func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
 	env := object.NewEnclosedEnvironment(fn.Env)
 
@@ -779,10 +994,8 @@ func unwrapReturnValue(obj object.Object) object.Object {
 
 func convertToString(obj object.Object) object.Object {
 	switch obj.Type() {
-	case object.INTEGER_OBJ:
-		intNum := obj.(*object.Integer)
-		string := &object.String{Value: intNum.Inspect()}
-		return string
+	case object.INTEGER_OBJ, object.BIGINT_OBJ, object.DECIMAL_OBJ, object.TIME_OBJ, object.DURATION_OBJ:
+		return &object.String{Value: obj.Inspect()}
 	case object.FLOAT_OBJ:
 		floatNum := obj.(*object.Float)
 		string := &object.String{Value: strconv.FormatFloat(floatNum.Value, 'f', -1, 64)}
@@ -444,6 +444,80 @@ func TestContinueStatement(t *testing.T) {
 	testIntegerObject(t, 1, testEval(input), 0)
 }
 
+func TestDeferStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []int64
+	}{
+		{`let log = []; fn f() { defer log.push(1); defer log.push(2); log.push(0) } f(); log`, []int64{0, 2, 1}},
+		{`let log = []; fn f(x) { defer log.push(x); if (x > 0) { return x } log.push(-1) } f(5); f(0); log`, []int64{5, -1, 0}},
+		{`let log = []; fn f() { let x = 1; defer fn() { log.push(x) }(); x = 2 } f(); log`, []int64{2}},
+		{`let log = []; fn f() { let x = 1; defer log.push(x); x = 2 } f(); log`, []int64{1}},
+		{`let log = []; fn f() { for (let i = 0; i < 3; i++) { defer log.push(i) } log.push(9) } f(); log`, []int64{9, 2, 1, 0}},
+		{`let log = []; fn f() { defer log.push(1); fn() { defer log.push(2) }(); log.push(3) } f(); log`, []int64{2, 3, 1}},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		array, ok := evaluated.(*object.Array)
+		if !ok {
+			t.Errorf("[test: %d] object is not Array. got=%T (%+v)", i, evaluated, evaluated)
+			continue
+		}
+
+		if len(array.Elements) != len(tt.expected) {
+			t.Errorf("[test: %d] wrong num of elements. want=%d, got=%d", i, len(tt.expected), len(array.Elements))
+			continue
+		}
+
+		for j, expected := range tt.expected {
+			testIntegerObject(t, i, array.Elements[j], expected)
+		}
+	}
+}
+
+func TestDeferStatementErrors(t *testing.T) {
+	input := `let log = []; fn f() { defer log.push("cleanup"); return 1 + true } f()`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	env := object.NewEnvironment()
+
+	evaluated := Eval(program, env)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if errObj.Message != "type mismatch: INTEGER + BOOLEAN" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+
+	log, _ := env.Get("log")
+	if log.Object.Inspect() != "[cleanup]" {
+		t.Errorf("deferred call did not run on error. got=%s", log.Object.Inspect())
+	}
+
+	evaluated = testEval(`fn f() { defer fn() { 1 + true }(); return 5 } f()`)
+	errObj, ok = evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if errObj.Message != "type mismatch: INTEGER + BOOLEAN" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+
+	evaluated = testEval(`defer print(1)`)
+	errObj, ok = evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if errObj.Message != "defer not in function" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+}
+
 func TestReassignStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -511,6 +585,9 @@ func TestFunctionApplication(t *testing.T) {
 		{"let add = fn(x, y) { x + y; }; add(5 + 5, add(5, 5));", 20},
 		{"fn(x) { x; }(5)", 5},
 		{"fn adder(x, y) { return x + y }; adder(5, 5)", 10},
+		// annotations aren't checked when the program runs
+		{"fn adder(x: int, y: int) -> int { return x + y }; adder(5, 5)", 10},
+		{"let twice: fn(int) -> int = fn(x: string) -> string { x * 2 }; let n: string = twice(5); n", 10},
 	}
 
 	for i, tt := range tests {
@@ -0,0 +1,41 @@
+package evaluator
+
+import (
+	"io"
+	"os"
+
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/object"
+)
+
+// Hook follows the evaluation of a program, for debuggers. Without a hook
+// the evaluator only pays for a nil check per statement and call.
+type Hook interface {
+	// Statement is called before each statement of a program or block runs
+	Statement(stmt ast.Statement, env *object.Environment)
+	// Call and Return bracket the body of a Cixac function, env holds its
+	// parameters
+	Call(fn *object.Function, env *object.Environment)
+	Return(fn *object.Function)
+}
+
+var hook Hook
+
+// SetHook attaches h to the evaluator, nil detaches it
+func SetHook(h Hook) {
+	hook = h
+}
+
+// IsInternalName reports whether name is bookkeeping the evaluator keeps in
+// an environment rather than a variable
+func IsInternalName(name string) bool {
+	return name == ENV_FOR_FLAG || name == ENV_WHILE_FLAG || name == ENV_OBJECT_BUILTIN_FLAG || name == ENV_DEFER_FLAG || name == ENV_MATCH_FLAG
+}
+
+var stdout io.Writer = os.Stdout
+
+// SetOutput redirects what print writes, for hosts that use stdout for
+// something else
+func SetOutput(w io.Writer) {
+	stdout = w
+}
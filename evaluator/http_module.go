@@ -0,0 +1,208 @@
+package evaluator
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+var httpClient = &http.Client{}
+
+var httpMembers = map[string]object.Object{
+	"get":     &object.Builtin{Fn: httpGet},
+	"post":    &object.Builtin{Fn: httpPost},
+	"request": &object.Builtin{Fn: httpRequest},
+}
+
+func init() {
+	registerModule(&object.Module{Name: "http", Members: httpMembers})
+}
+
+type httpSpec struct {
+	method  string
+	url     string
+	headers http.Header
+	body    io.Reader
+	timeout time.Duration
+}
+
+func httpGet(args ...object.Object) object.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+
+	url, ok := args[0].(*object.String)
+	if !ok {
+		return newError("url passed to `get` must be STRING, got %s", args[0].Type())
+	}
+
+	spec := &httpSpec{method: http.MethodGet, url: url.Value, headers: http.Header{}}
+	if len(args) == 2 {
+		if err := spec.options("get", args[1]); err != nil {
+			return err
+		}
+	}
+
+	return spec.do()
+}
+
+func httpPost(args ...object.Object) object.Object {
+	if len(args) < 2 || len(args) > 3 {
+		return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+	}
+
+	url, ok := args[0].(*object.String)
+	if !ok {
+		return newError("url passed to `post` must be STRING, got %s", args[0].Type())
+	}
+
+	spec := &httpSpec{method: http.MethodPost, url: url.Value, headers: http.Header{}}
+	if err := spec.setBody(args[1]); err != nil {
+		return err
+	}
+	if len(args) == 3 {
+		if err := spec.options("post", args[2]); err != nil {
+			return err
+		}
+	}
+
+	return spec.do()
+}
+
+func httpRequest(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	spec := &httpSpec{method: http.MethodGet, headers: http.Header{}}
+	if err := spec.options("request", args[0]); err != nil {
+		return err
+	}
+	if spec.url == "" {
+		return newError("`request` needs a url")
+	}
+
+	return spec.do()
+}
+
+// options reads method, url, headers, body and timeout from a hash. The
+// timeout is in milliseconds or a duration
+func (s *httpSpec) options(name string, arg object.Object) *object.Error {
+	opts, ok := arg.(*object.Hash)
+	if !ok {
+		return newError("options to `%s` must be HASH, got %s", name, arg.Type())
+	}
+
+	if method, ok := hashString(opts, "method"); ok {
+		s.method = strings.ToUpper(method)
+	}
+
+	if url, ok := hashString(opts, "url"); ok {
+		s.url = url
+	}
+
+	if headers, ok := hashValue(opts, "headers"); ok {
+		hash, ok := headers.(*object.Hash)
+		if !ok {
+			return newError("headers passed to `%s` must be HASH, got %s", name, headers.Type())
+		}
+
+		for _, pair := range hash.Pairs {
+			value := convertToString(pair.Value)
+			if value == nil {
+				return newError("header value passed to `%s` not supported, got %s", name, pair.Value.Type())
+			}
+			s.headers.Set(convertToString(pair.Key).(*object.String).Value, value.(*object.String).Value)
+		}
+	}
+
+	if body, ok := hashValue(opts, "body"); ok {
+		if err := s.setBody(body); err != nil {
+			return err
+		}
+	}
+
+	if timeout, ok := hashValue(opts, "timeout"); ok {
+		switch timeout := timeout.(type) {
+		case *object.Integer:
+			s.timeout = time.Duration(timeout.Value) * time.Millisecond
+		case *object.Duration:
+			s.timeout = timeout.Value
+		default:
+			return newError("timeout passed to `%s` must be INTEGER or DURATION, got %s", name, timeout.Type())
+		}
+	}
+
+	return nil
+}
+
+// setBody sends strings as they are and arrays and hashes as JSON
+func (s *httpSpec) setBody(body object.Object) *object.Error {
+	switch body := body.(type) {
+	case *object.Array, *object.Hash:
+		data, err := object.ToJSON(body)
+		if err != nil {
+			return newError("could not encode body: %s", err)
+		}
+		s.body = strings.NewReader(string(data))
+		if s.headers.Get("Content-Type") == "" {
+			s.headers.Set("Content-Type", "application/json")
+		}
+	default:
+		str := convertToString(body)
+		if str == nil {
+			return newError("body not supported, got %s", body.Type())
+		}
+		s.body = strings.NewReader(str.(*object.String).Value)
+	}
+
+	return nil
+}
+
+// do sends the request. Responses with any status are returned, only
+// failing to get a response is an error
+func (s *httpSpec) do() object.Object {
+	ctx := context.Background()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, s.method, s.url, s.body)
+	if err != nil {
+		return newError("http: %s", err)
+	}
+	req.Header = s.headers
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return newError("http: %s", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return newError("http: %s", err)
+	}
+
+	return &object.Response{
+		Status:  int64(resp.StatusCode),
+		Headers: headersToHash(resp.Header),
+		Body:    string(body),
+	}
+}
+
+// headersToHash joins repeated headers with ", "
+func headersToHash(header http.Header) *object.Hash {
+	pairs := make(map[string]object.Object, len(header))
+	for name, values := range header {
+		pairs[name] = &object.String{Value: strings.Join(values, ", ")}
+	}
+
+	return object.NewHash(pairs)
+}
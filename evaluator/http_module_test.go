@@ -0,0 +1,105 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func newEchoServer() *httptest.Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Add("X-Multi", "a")
+		w.Header().Add("X-Multi", "b")
+		json.NewEncoder(w).Encode(map[string]any{
+			"method":      r.Method,
+			"token":       r.Header.Get("Authorization"),
+			"contentType": r.Header.Get("Content-Type"),
+			"body":        string(body),
+			"nums":        []any{1, 2.5, json.Number("12345678901234567890")},
+		})
+	})
+
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusNotFound)
+	})
+
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestHTTPClient(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`http.get(URL + "/echo").status`, 200},
+		{`http.get(URL + "/echo").json()["method"]`, "GET"},
+		{`http.get(URL + "/echo").headers["Content-Type"]`, "application/json"},
+		{`http.get(URL + "/echo").headers["X-Multi"]`, "a, b"},
+		{`http.get(URL + "/echo", {"headers": {"Authorization": "Bearer t"}}).json()["token"]`, "Bearer t"},
+		{`http.get(URL + "/echo").json()["nums"][0]`, 1},
+		{`http.get(URL + "/echo").json()["nums"][1]`, 2.5},
+		{`http.get(URL + "/echo").json()["nums"][2] > 1`, true},
+		{`http.post(URL + "/echo", "hi").json()["body"]`, "hi"},
+		{`http.post(URL + "/echo", {"a": [1, true, null]}).json()["body"]`, `{"a":[1,true,null]}`},
+		{`http.post(URL + "/echo", {"a": 1}).json()["contentType"]`, "application/json"},
+		{`http.post(URL + "/echo", 1.50d).json()["body"]`, "1.50"},
+		{`http.request({"method": "put", "url": URL + "/echo", "body": "x"}).json()["method"]`, "PUT"},
+		{`http.request({"url": URL + "/echo", "body": "x"}).json()["body"]`, "x"},
+		{`http.get(URL + "/missing").status`, 404},
+		{`http.get(URL + "/missing").body`, "nope\n"},
+		{`http.get(URL + "/missing").json()`, "invalid JSON in response body: invalid character 'o' in literal null (expecting 'u')"},
+		{`http.get(URL + "/slow", {"timeout": 50}).status`, "context deadline exceeded"},
+		{`http.request({"url": URL + "/slow", "timeout": time.millisecond * 50})`, "context deadline exceeded"},
+		{`http.get(URL + "/echo").nope`, "RESPONSE has no property nope"},
+		{`http.request({"method": "GET"})`, "`request` needs a url"},
+		{`http.get(URL, {"headers": 1})`, "headers passed to `get` must be HASH, got INTEGER"},
+		{`http.post(URL, fn() {})`, "body not supported, got FUNCTION"},
+		{`http.get(5)`, "url passed to `get` must be STRING, got INTEGER"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(`let URL = "` + server.URL + `"; ` + tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, i, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, i, evaluated, expected)
+		case bool:
+			testBooleanObject(t, i, evaluated, expected)
+		case string:
+			if errObj, ok := evaluated.(*object.Error); ok {
+				if !strings.HasSuffix(errObj.Message, expected) {
+					t.Errorf("[test: %d] wrong error message. expected=%q, got=%q", i, expected, errObj.Message)
+				}
+				continue
+			}
+
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("[test: %d] wrong value. expected=%q, got=%s", i, expected, fmt.Sprint(evaluated))
+			}
+		}
+	}
+}
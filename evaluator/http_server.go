@@ -0,0 +1,242 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+	"sync"
+	"syscall"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func init() {
+	httpMembers["serve"] = &object.Builtin{Fn: httpServe}
+}
+
+// serveContext is done when the server should shut down. Tests replace it to
+// stop the server without sending a signal
+var serveContext = func() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+var serveLog io.Writer = os.Stderr
+
+// Handlers share the environments of the script, which are not safe for
+// concurrent use, so requests are handled one at a time
+type serveHandler struct {
+	mu  sync.Mutex
+	mux *http.ServeMux
+}
+
+var routeParam = regexp.MustCompile(`\{([^}.]+)(\.\.\.)?\}`)
+
+// httpServe blocks until SIGINT or SIGTERM, then waits for in flight
+// requests to finish
+func httpServe(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+
+	addr, ok := args[0].(*object.String)
+	if !ok {
+		return newError("address passed to `serve` must be STRING, got %s", args[0].Type())
+	}
+
+	handler, errObj := newServeHandler(args[1])
+	if errObj != nil {
+		return errObj
+	}
+
+	listener, err := net.Listen("tcp", addr.Value)
+	if err != nil {
+		return newError("serve: %s", err)
+	}
+
+	ctx, stop := serveContext()
+	defer stop()
+
+	server := &http.Server{Handler: handler}
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-done:
+		return newError("serve: %s", err)
+	case <-ctx.Done():
+	}
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		return newError("serve: %s", err)
+	}
+	if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+		return newError("serve: %s", err)
+	}
+
+	return EMPTY
+}
+
+// newServeHandler accepts a single function for every request or a hash of
+// routes such as {"GET /items/{id}": fn(req) {...}}
+func newServeHandler(arg object.Object) (h *serveHandler, errObj *object.Error) {
+	h = &serveHandler{mux: http.NewServeMux()}
+
+	switch arg := arg.(type) {
+	case *object.Function, *object.Builtin:
+		h.mux.Handle("/", h.route(arg, nil))
+
+	case *object.Hash:
+		// ServeMux panics on invalid or conflicting patterns
+		defer func() {
+			if r := recover(); r != nil {
+				h, errObj = nil, newError("serve: %v", r)
+			}
+		}()
+
+		for _, pair := range arg.Pairs {
+			pattern, ok := pair.Key.(*object.String)
+			if !ok {
+				return nil, newError("route passed to `serve` must be STRING, got %s", pair.Key.Type())
+			}
+
+			switch pair.Value.(type) {
+			case *object.Function, *object.Builtin:
+			default:
+				return nil, newError("handler for %q must be FUNCTION, got %s", pattern.Value, pair.Value.Type())
+			}
+
+			var params []string
+			for _, match := range routeParam.FindAllStringSubmatch(pattern.Value, -1) {
+				params = append(params, match[1])
+			}
+
+			h.mux.Handle(pattern.Value, h.route(pair.Value, params))
+		}
+
+	default:
+		return nil, newError("handler passed to `serve` must be FUNCTION or HASH, got %s", arg.Type())
+	}
+
+	return h, nil
+}
+
+func (h *serveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *serveHandler) route(fn object.Object, params []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "could not read body", http.StatusBadRequest)
+			return
+		}
+
+		req := &object.Request{
+			Method:  r.Method,
+			Path:    r.URL.Path,
+			Headers: headersToHash(r.Header),
+			Query:   headersToHash(http.Header(r.URL.Query())),
+			Params:  object.NewHash(nil),
+			Body:    string(body),
+		}
+		for _, name := range params {
+			key := &object.String{Value: name}
+			req.Params.Pairs[key.HashKey()] = object.HashPair{Key: key, Value: &object.String{Value: r.PathValue(name)}}
+		}
+
+		// unlocked even when the handler panics, which net/http recovers from
+		result := func() object.Object {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			return applyFunction(fn, []object.Object{req})
+		}()
+
+		writeServeResult(w, r, result)
+	})
+}
+
+// writeServeResult sends a hash with a status, and optional headers and body.
+// Any other value becomes the body of a 200 response. Bodies that are
+// arrays or hashes are sent as JSON
+func writeServeResult(w http.ResponseWriter, r *http.Request, result object.Object) {
+	fail := func(msg string) {
+		fmt.Fprintf(serveLog, "%s %s: %s\n", r.Method, r.URL.Path, msg)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+
+	if isError(result) {
+		fail(result.Inspect())
+		return
+	}
+
+	status := http.StatusOK
+	body := result
+
+	if hash, ok := result.(*object.Hash); ok {
+		if val, ok := hashValue(hash, "status"); ok {
+			code, ok := val.(*object.Integer)
+			if !ok {
+				fail("status must be INTEGER, got " + string(val.Type()))
+				return
+			}
+			// net/http panics on codes it can't write
+			if code.Value < 100 || code.Value > 999 {
+				fail(fmt.Sprintf("status must be between 100 and 999, got %d", code.Value))
+				return
+			}
+			status = int(code.Value)
+
+			body, _ = hashValue(hash, "body")
+
+			if val, ok := hashValue(hash, "headers"); ok {
+				headers, ok := val.(*object.Hash)
+				if !ok {
+					fail("headers must be HASH, got " + string(val.Type()))
+					return
+				}
+
+				for _, pair := range headers.Pairs {
+					name, value := convertToString(pair.Key), convertToString(pair.Value)
+					if name == nil || value == nil {
+						fail("header not supported, got " + string(pair.Value.Type()))
+						return
+					}
+					w.Header().Set(name.(*object.String).Value, value.(*object.String).Value)
+				}
+			}
+		}
+	}
+
+	var data string
+	switch body := body.(type) {
+	case nil, *object.Null, *object.Empty:
+	case *object.Array, *object.Hash:
+		encoded, err := object.ToJSON(body)
+		if err != nil {
+			fail(err.Error())
+			return
+		}
+		data = string(encoded)
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+	default:
+		if str := convertToString(body); str != nil {
+			data = str.(*object.String).Value
+		} else {
+			data = body.Inspect()
+		}
+	}
+
+	w.WriteHeader(status)
+	io.WriteString(w, data)
+}
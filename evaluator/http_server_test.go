@@ -0,0 +1,214 @@
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func TestHTTPServeRoutes(t *testing.T) {
+	var logs bytes.Buffer
+	serveLog = &logs
+	defer func() { serveLog = os.Stderr }()
+
+	handler, errObj := newServeHandler(testEval(`
+		let hits = [];
+		{
+			"GET /items/{id}": fn(req) {
+				hits.push(req.path)
+				return {"status": 200, "body": {"id": req.params["id"], "q": req.query["q"], "hits": len(hits)}}
+			},
+			"POST /hook": fn(req) {
+				return {"status": 201, "headers": {"X-Kind": req.headers["X-Kind"]}, "body": req.json()["name"]}
+			},
+			"GET /files/{path...}": fn(req) { return req.params["path"] },
+			"GET /broken": fn(req) { return 1 + true },
+			"GET /zero": fn(req) { return {"status": 0} },
+			"GET /huge": fn(req) { return {"status": 1000, "body": "big"} },
+		}
+	`))
+	if errObj != nil {
+		t.Fatalf("newServeHandler returned error: %s", errObj.Message)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	tests := []struct {
+		method  string
+		path    string
+		body    string
+		status  int
+		resBody string
+		header  string
+	}{
+		{"GET", "/items/42?q=x", "", 200, `{"hits":1,"id":"42","q":"x"}`, ""},
+		{"GET", "/items/7", "", 200, `{"hits":2,"id":"7","q":null}`, ""},
+		{"POST", "/hook", `{"name": "cixac"}`, 201, "cixac", "hook"},
+		{"POST", "/hook", `nope`, 500, "internal server error\n", ""},
+		{"GET", "/files/a/b.txt", "", 200, "a/b.txt", ""},
+		{"GET", "/broken", "", 500, "internal server error\n", ""},
+		{"GET", "/zero", "", 500, "internal server error\n", ""},
+		{"GET", "/huge", "", 500, "internal server error\n", ""},
+		{"DELETE", "/items/1", "", 405, "Method Not Allowed\n", ""},
+		{"GET", "/elsewhere", "", 404, "404 page not found\n", ""},
+	}
+
+	for i, tt := range tests {
+		req, _ := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		req.Header.Set("X-Kind", "hook")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("[test: %d] request failed: %s", i, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("[test: %d] wrong status. expected=%d, got=%d", i, tt.status, resp.StatusCode)
+		}
+		if string(body) != tt.resBody {
+			t.Errorf("[test: %d] wrong body. expected=%q, got=%q", i, tt.resBody, body)
+		}
+		if tt.header != "" && resp.Header.Get("X-Kind") != tt.header {
+			t.Errorf("[test: %d] wrong header. expected=%q, got=%q", i, tt.header, resp.Header.Get("X-Kind"))
+		}
+	}
+
+	if !strings.Contains(logs.String(), "GET /broken: ERROR: type mismatch: INTEGER + BOOLEAN") {
+		t.Errorf("handler error not logged. got=%q", logs.String())
+	}
+	if !strings.Contains(logs.String(), "GET /huge: status must be between 100 and 999, got 1000") {
+		t.Errorf("bad status not logged. got=%q", logs.String())
+	}
+}
+
+func TestHTTPServeHandlerPanic(t *testing.T) {
+	calls := 0
+	handler, errObj := newServeHandler(&object.Builtin{Fn: func(args ...object.Object) object.Object {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		return &object.String{Value: "ok"}
+	}})
+	if errObj != nil {
+		t.Fatalf("newServeHandler returned error: %s", errObj.Message)
+	}
+
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.Start()
+	defer server.Close()
+
+	// net/http recovers the panic and drops the connection
+	if resp, err := http.Get(server.URL); err == nil {
+		resp.Body.Close()
+	}
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request after a panic failed: %s", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "ok" {
+		t.Errorf("wrong body. expected=%q, got=%q", "ok", body)
+	}
+}
+
+func TestHTTPServeConcurrentRequests(t *testing.T) {
+	handler, errObj := newServeHandler(testEval(`let seen = []; fn(req) { seen.push(req.path); return len(seen) }`))
+	if errObj != nil {
+		t.Fatalf("newServeHandler returned error: %s", errObj.Message)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "51" {
+		t.Errorf("handlers did not run one at a time. expected=51, got=%s", body)
+	}
+}
+
+func TestHTTPServeShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	original := serveContext
+	serveContext = func() (context.Context, context.CancelFunc) { return ctx, cancel }
+	defer func() { serveContext = original }()
+
+	done := make(chan object.Object)
+	go func() {
+		done <- testEval(`http.serve("127.0.0.1:0", fn(req) { "ok" })`)
+	}()
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	select {
+	case result := <-done:
+		if result != EMPTY {
+			t.Errorf("serve did not shut down cleanly. got=%+v", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after shutdown")
+	}
+}
+
+func TestHTTPServeErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`http.serve(8080, fn(req) {})`, "address passed to `serve` must be STRING, got INTEGER"},
+		{`http.serve(":0", 1)`, "handler passed to `serve` must be FUNCTION or HASH, got INTEGER"},
+		{`http.serve(":0", {"GET /": 1})`, `handler for "GET /" must be FUNCTION, got INTEGER`},
+		{`http.serve(":0", {"BAD PATTERN": fn(req) {}})`, "serve: parsing \"BAD PATTERN\": at offset 4: host/path missing /"},
+		{`http.serve("256.0.0.1:0", fn(req) {})`, "serve: listen tcp: lookup 256.0.0.1"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("[test: %d] no error object returned. got=%T(%+v)", i, evaluated, evaluated)
+			continue
+		}
+		if !strings.HasPrefix(errObj.Message, tt.expected) {
+			t.Errorf("[test: %d] wrong error message. expected=%q, got=%q", i, tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package evaluator
+
+import (
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func evalMatchExpression(me *ast.MatchExpression, env *object.Environment) object.Object {
+	value := Eval(me.Value, env)
+	if isError(value) {
+		return value
+	}
+
+	for i, arm := range me.Arms {
+		armEnv := newArmEnvironment(env)
+
+		matched, err := matchPattern(arm.Pattern, value, armEnv)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			continue
+		}
+
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, armEnv)
+			if isError(guard) {
+				return guard
+			}
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+
+		if coverage != nil {
+			coverage.branch(me.Token, i)
+		}
+		if result := Eval(arm.Body, armEnv); result != nil {
+			return result
+		}
+		return NULL
+	}
+
+	if str, ok := value.(*object.String); ok {
+		return newError("no match arm matches %q", str.Value)
+	}
+	return newError("no match arm matches %s", value.Inspect())
+}
+
+// newArmEnvironment is the scope of the names an arm binds. break and
+// continue work in it when the match is in a loop.
+func newArmEnvironment(env *object.Environment) *object.Environment {
+	armEnv := object.NewEnclosedEnvironment(env)
+	armEnv.Set(ENV_MATCH_FLAG, object.ObjectMeta{Object: TRUE})
+
+	for _, flag := range []string{ENV_FOR_FLAG, ENV_WHILE_FLAG} {
+		if env.ExistsInScope(flag) {
+			armEnv.Set(flag, object.ObjectMeta{Object: TRUE})
+		}
+	}
+
+	return armEnv
+}
+
+// assignmentScope is the scope an assignment to name in env happens in.
+// Match arms only keep the names they bind, others are assigned in the scope
+// the match is in.
+func assignmentScope(env *object.Environment, name string) *object.Environment {
+	for env.ExistsInScope(ENV_MATCH_FLAG) && !env.ExistsInScope(name) {
+		env = env.Outer()
+	}
+	return env
+}
+
+// matchPattern reports whether value matches pattern, binding the names in
+// the pattern in env
+func matchPattern(pattern ast.Pattern, value object.Object, env *object.Environment) (bool, *object.Error) {
+	switch p := pattern.(type) {
+	case *ast.WildcardPattern:
+		return true, nil
+
+	case *ast.BindingPattern:
+		if ExistsInBuiltins(p.Name.Value) {
+			return false, newError("Identifier %s has same name as builtin", p.Name.Value)
+		}
+		env.Set(p.Name.Value, object.ObjectMeta{Object: value})
+		return true, nil
+
+	case *ast.LiteralPattern:
+		literal := Eval(p.Value, env)
+		if err, ok := literal.(*object.Error); ok {
+			return false, err
+		}
+		return valuesEqual(value, literal), nil
+
+	case *ast.ArrayPattern:
+		array, ok := value.(*object.Array)
+		if !ok || len(array.Elements) < len(p.Elements) || (p.Rest == nil && len(array.Elements) != len(p.Elements)) {
+			return false, nil
+		}
+
+		for i, element := range p.Elements {
+			if matched, err := matchPattern(element, array.Elements[i], env); err != nil || !matched {
+				return false, err
+			}
+		}
+
+		if p.Rest != nil {
+			rest := append([]object.Object{}, array.Elements[len(p.Elements):]...)
+			return matchPattern(p.Rest, &object.Array{Elements: rest}, env)
+		}
+		return true, nil
+
+	case *ast.HashPattern:
+		hash, ok := value.(*object.Hash)
+		if !ok {
+			return false, nil
+		}
+
+		for i, keyNode := range p.Keys {
+			key := Eval(keyNode, env)
+			if err, ok := key.(*object.Error); ok {
+				return false, err
+			}
+
+			hashable, ok := key.(object.Hashable)
+			if !ok {
+				return false, newError("unusable as hash key: %s", key.Type())
+			}
+
+			pair, ok := hash.Pairs[hashable.HashKey()]
+			if !ok {
+				return false, nil
+			}
+			if matched, err := matchPattern(p.Values[i], pair.Value, env); err != nil || !matched {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,95 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func TestMatchExpression(t *testing.T) {
+	shape := `fn area(s) {
+	match s {
+		{"type": "circle", "r": r} => 3 * r * r,
+		{"type": "rect", "w": w, "h": h} => w * h,
+		_ => null
+	}
+}
+`
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match 0 { 0 => \"zero\", _ => \"other\" }", "zero"},
+		{"match 5 { 0 => \"zero\", _ => \"other\" }", "other"},
+		{"match -1 { 1 => \"one\", -1 => \"minus one\" }", "minus one"},
+		{"match 2.0 { 2 => \"two\" }", "two"},
+		{"match 1.5d { 1.50d => \"exact\" }", "exact"},
+		{`match "b" { "a" => 1, "b" => 2 }`, "2"},
+		{"match null { null => 1, _ => 2 }", "1"},
+		{"match false { null => 1, false => 2 }", "2"},
+		{`match "1" { 1 => "int", _ => "string" }`, "string"},
+		{"match [] { [] => \"empty\", _ => \"other\" }", "empty"},
+		{"match [1, 2, 3] { [first, ...rest] => [first, rest] }", "[1, [2, 3]]"},
+		{"match [1] { [first, ...rest] => rest }", "[]"},
+		{"match [1, 2] { [a] => 1, [a, b] => a + b }", "3"},
+		{"match [1, 2, 3] { [a, b] => 1, [a, b, ...] => a + b }", "3"},
+		{"match [1, [2, 3]] { [a, [b, c]] => a + b + c }", "6"},
+		{"match [1, 2] { [1, x] => x, _ => 0 }", "2"},
+		{"match [3, 2] { [1, x] => x, _ => 0 }", "0"},
+		{"match \"ab\" { [a, b] => 1, _ => 2 }", "2"},
+		{shape + `area({"type": "circle", "r": 2})`, "12"},
+		{shape + `area({"type": "rect", "w": 2, "h": 5, "fill": "red"})`, "10"},
+		{shape + `area({"type": "rect", "w": 2})`, "null"},
+		{shape + `area([1])`, "null"},
+		{"match 15 { x if x > 10 => \"big\", x => \"small\" }", "big"},
+		{"match 5 { x if x > 10 => \"big\", x => \"small\" }", "small"},
+		{"match [4, 2] { [a, b] if a < b => \"asc\", [a, b] => \"desc\" }", "desc"},
+		{"match 1 { 1 => { let y = 2\n y * 10 } }", "20"},
+		{"match 1 { 1 => {} }", "null"},
+		{"let x = 1; match 2 { x => x }; x", "1"},
+		{"let n = 0; match 3 { v => { n = v } }; n", "3"},
+		{"let n = 0; match 3 { v => { n += v; n++ } }; n", "4"},
+		{"let n = 0; for (i, v in [1, 2, 3]) { match v { 2 => { continue }, x => { n += x } } }; n", "4"},
+		{"let i = 0; while (true) { i++; match i { 3 => { break }, _ => {} } }; i", "3"},
+		{"let f = fn(v) { match v { 0 => { return \"early\" } }; \"late\" }; f(0)", "early"},
+		{"let f = match 3 { n => fn() { n * 2 } }; f()", "6"},
+		{"let fact = fn(n) { match n { 0 => 1, _ => n * fact(n - 1) } }; fact(5)", "120"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("[test: %d] wrong result for %q. got=%v, want=%s", i, tt.input, evaluated, tt.expected)
+		}
+	}
+}
+
+func TestMatchExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match 3 { 1 => 1, 2 => 2 }", "no match arm matches 3"},
+		{`match "x" { "y" => 1 }`, `no match arm matches "x"`},
+		{"match [1, 2] { [a] => a }", "no match arm matches [1, 2]"},
+		{"match 5 { x if x > 10 => x }", "no match arm matches 5"},
+		{"match 1 { len => len }", "Identifier len has same name as builtin"},
+		{"match 1 { x if x + true => 1 }", "type mismatch: INTEGER + BOOLEAN"},
+		{`match {"a": 1} { {null: x} => x }`, "unusable as hash key: NULL"},
+		{"match 1 { 1 => { break } }", "break not in for statement"},
+		{"match y { _ => 1 }", "Identifier not found: y"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("[test: %d] no error for %q. got=%T (%+v)", i, tt.input, evaluated, evaluated)
+			continue
+		}
+		if err.Message != tt.expected {
+			t.Errorf("[test: %d] wrong error for %q. expected=%q, got=%q", i, tt.input, tt.expected, err.Message)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package evaluator
+
+import (
+	"math"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func init() {
+	members := map[string]object.Object{
+		"pi":  &object.Float{Value: math.Pi},
+		"e":   &object.Float{Value: math.E},
+		"inf": &object.Float{Value: math.Inf(1)},
+		"nan": &object.Float{Value: math.NaN()},
+
+		"abs":   &object.Builtin{Fn: mathAbs},
+		"floor": integerPreserving("floor", math.Floor),
+		"ceil":  integerPreserving("ceil", math.Ceil),
+		"round": integerPreserving("round", math.Round),
+		"trunc": integerPreserving("trunc", math.Trunc),
+		"pow":   &object.Builtin{Fn: mathPow},
+		"log":   &object.Builtin{Fn: mathLog},
+		"atan2": &object.Builtin{Fn: mathAtan2},
+		"min":   &object.Builtin{Fn: mathMinMax("min", func(a, b float64) bool { return a < b })},
+		"max":   &object.Builtin{Fn: mathMinMax("max", func(a, b float64) bool { return a > b })},
+		"clamp": &object.Builtin{Fn: mathClamp},
+	}
+
+	for name, fn := range map[string]func(float64) float64{
+		"sqrt": math.Sqrt,
+		"exp":  math.Exp,
+		"sin":  math.Sin,
+		"cos":  math.Cos,
+		"tan":  math.Tan,
+		"asin": math.Asin,
+		"acos": math.Acos,
+		"atan": math.Atan,
+	} {
+		members[name] = floatFunction(name, fn)
+	}
+
+	registerModule(&object.Module{Name: "math", Members: members})
+}
+
+func numberArgs(name string, want int, args []object.Object) ([]float64, *object.Error) {
+	if len(args) != want {
+		return nil, newError("wrong number of arguments. got=%d, want=%d", len(args), want)
+	}
+
+	nums := make([]float64, len(args))
+	for i, arg := range args {
+		num, ok := toFloat(arg)
+		if !ok {
+			return nil, newError("argument to `%s` must be INTEGER or FLOAT, got %s", name, arg.Type())
+		}
+		nums[i] = num
+	}
+
+	return nums, nil
+}
+
+func floatFunction(name string, fn func(float64) float64) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			nums, err := numberArgs(name, 1, args)
+			if err != nil {
+				return err
+			}
+
+			return &object.Float{Value: fn(nums[0])}
+		},
+	}
+}
+
+// integerPreserving returns integers unchanged and applies fn to floats
+func integerPreserving(name string, fn func(float64) float64) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			nums, err := numberArgs(name, 1, args)
+			if err != nil {
+				return err
+			}
+
+			if isInteger(args[0]) {
+				return args[0]
+			}
+
+			return &object.Float{Value: fn(nums[0])}
+		},
+	}
+}
+
+func mathAbs(args ...object.Object) object.Object {
+	nums, err := numberArgs("abs", 1, args)
+	if err != nil {
+		return err
+	}
+
+	if isInteger(args[0]) {
+		if nums[0] < 0 {
+			return evalMinusPrefixOperatorExpression(args[0])
+		}
+		return args[0]
+	}
+
+	return &object.Float{Value: math.Abs(nums[0])}
+}
+
+func mathPow(args ...object.Object) object.Object {
+	if _, err := numberArgs("pow", 2, args); err != nil {
+		return err
+	}
+
+	return evalInfixExpression("**", args[0], args[1])
+}
+
+func mathLog(args ...object.Object) object.Object {
+	if len(args) == 2 {
+		nums, err := numberArgs("log", 2, args)
+		if err != nil {
+			return err
+		}
+		return &object.Float{Value: math.Log(nums[0]) / math.Log(nums[1])}
+	}
+
+	nums, err := numberArgs("log", 1, args)
+	if err != nil {
+		return err
+	}
+
+	return &object.Float{Value: math.Log(nums[0])}
+}
+
+func mathAtan2(args ...object.Object) object.Object {
+	nums, err := numberArgs("atan2", 2, args)
+	if err != nil {
+		return err
+	}
+
+	return &object.Float{Value: math.Atan2(nums[0], nums[1])}
+}
+
+// mathMinMax accepts either several numbers or a single array of numbers.
+// The chosen argument is returned as is, so integers stay integers.
+func mathMinMax(name string, better func(a, b float64) bool) object.BuiltinFunction {
+	return func(args ...object.Object) object.Object {
+		if len(args) == 1 {
+			if arr, ok := args[0].(*object.Array); ok {
+				args = arr.Elements
+			}
+		}
+
+		if len(args) == 0 {
+			return newError("`%s` needs at least one argument", name)
+		}
+
+		var best object.Object
+		var bestVal float64
+
+		for _, arg := range args {
+			val, ok := toFloat(arg)
+			if !ok {
+				return newError("argument to `%s` must be INTEGER or FLOAT, got %s", name, arg.Type())
+			}
+
+			if best == nil || better(val, bestVal) {
+				best, bestVal = arg, val
+			}
+		}
+
+		return best
+	}
+}
+
+func mathClamp(args ...object.Object) object.Object {
+	nums, err := numberArgs("clamp", 3, args)
+	if err != nil {
+		return err
+	}
+
+	if nums[1] > nums[2] {
+		return newError("lower bound of `clamp` is greater than upper bound")
+	}
+
+	switch {
+	case nums[0] < nums[1]:
+		return args[1]
+	case nums[0] > nums[2]:
+		return args[2]
+	default:
+		return args[0]
+	}
+}
@@ -0,0 +1,97 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func TestPowerOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"2 ** 10", 1024},
+		{"2 ** 0", 1},
+		{"-2 ** 2", -4},
+		{"(-2) ** 3", -8},
+		{"2 ** 3 ** 2", 512},
+		{"2 * 3 ** 2", 18},
+		{"2 ** -1", 0.5},
+		{"2.0 ** 3", 8.0},
+		{"9 ** 0.5", 3.0},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, i, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, i, evaluated, expected)
+		}
+	}
+}
+
+func TestMathModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"math.abs(-5)", 5},
+		{"math.abs(-5.5)", 5.5},
+		{"math.floor(5)", 5},
+		{"math.floor(5.7)", 5.0},
+		{"math.floor(-5.2)", -6.0},
+		{"math.ceil(5.2)", 6.0},
+		{"math.round(2.5)", 3.0},
+		{"math.round(-2.5)", -3.0},
+		{"math.trunc(-2.7)", -2.0},
+		{"math.sqrt(16)", 4.0},
+		{"math.pow(2, 8)", 256},
+		{"math.pow(2.0, 0.5)", math.Sqrt2},
+		{"math.exp(0)", 1.0},
+		{"math.log(math.e)", 1.0},
+		{"math.log(8, 2)", 3.0},
+		{"math.sin(0)", 0.0},
+		{"math.cos(math.pi)", -1.0},
+		{"math.atan2(1, 1)", math.Pi / 4},
+		{"math.min(3, 1, 2)", 1},
+		{"math.max(3, 1.5, 2)", 3},
+		{"math.max([1, 7.5, 2])", 7.5},
+		{"math.clamp(15, 0, 10)", 10},
+		{"math.clamp(-1.5, 0, 10)", 0},
+		{"math.clamp(5, 0, 10)", 5},
+		{"math.pi", math.Pi},
+		{"math.inf > 1000000", true},
+		{"math.nan == math.nan", false},
+		{`math.sqrt("4")`, "argument to `sqrt` must be INTEGER or FLOAT, got STRING"},
+		{"math.min()", "`min` needs at least one argument"},
+		{"math.clamp(1, 10, 0)", "lower bound of `clamp` is greater than upper bound"},
+		{"math.abs(1, 2)", "wrong number of arguments. got=2, want=1"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, i, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, i, evaluated, expected)
+		case bool:
+			testBooleanObject(t, i, evaluated, expected)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("[test: %d] no error object returned. got=%T(%+v)", i, evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("[test: %d] wrong error message. expected=%q, got=%q", i, expected, errObj.Message)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package evaluator
+
+import (
+	"sort"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+// Modules group related builtins under a single name, e.g. os.env("HOME").
+// They are resolved after the environment and the builtins, so a binding with
+// the same name shadows the module.
+var modules = map[string]*object.Module{}
+
+func registerModule(module *object.Module) {
+	modules[module.Name] = module
+}
+
+// LookupModule is for tools such as the language server that list members
+func LookupModule(name string) (*object.Module, bool) {
+	module, ok := modules[name]
+	return module, ok
+}
+
+func ModuleNames() []string {
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func hashValue(hash *object.Hash, key string) (object.Object, bool) {
+	pair, ok := hash.Pairs[(&object.String{Value: key}).HashKey()]
+	if !ok {
+		return nil, false
+	}
+
+	return pair.Value, true
+}
+
+func hashString(hash *object.Hash, key string) (string, bool) {
+	value, ok := hashValue(hash, key)
+	if !ok {
+		return "", false
+	}
+
+	str, ok := value.(*object.String)
+	if !ok {
+		return "", false
+	}
+
+	return str.Value, true
+}
@@ -0,0 +1,198 @@
+package evaluator
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+var exit = os.Exit
+
+// exitHook runs before os.exit ends the process
+var exitHook func()
+
+func init() {
+	registerModule(&object.Module{
+		Name: "os",
+		Members: map[string]object.Object{
+			"args":   &object.Array{},
+			"env":    &object.Builtin{Fn: osEnv},
+			"setenv": &object.Builtin{Fn: osSetenv},
+			"exit":   &object.Builtin{Fn: osExit},
+			"exec":   &object.Builtin{Fn: osExec},
+		},
+	})
+}
+
+// SetArgs exposes the arguments following the script path as os.args
+func SetArgs(args []string) {
+	elements := make([]object.Object, len(args))
+	for i, arg := range args {
+		elements[i] = &object.String{Value: arg}
+	}
+
+	modules["os"].Members["args"] = &object.Array{Elements: elements}
+}
+
+// SetExitHook has hook run before os.exit ends the process, so what is written
+// after the program ends, like coverage reports, isn't lost. A nil hook
+// removes it.
+func SetExitHook(hook func()) {
+	exitHook = hook
+}
+
+func osEnv(args ...object.Object) object.Object {
+	switch len(args) {
+	case 0:
+		env := make(map[string]object.Object)
+		for _, kv := range os.Environ() {
+			name, value, _ := strings.Cut(kv, "=")
+			env[name] = &object.String{Value: value}
+		}
+		return object.NewHash(env)
+	case 1:
+		name, ok := args[0].(*object.String)
+		if !ok {
+			return newError("argument to `env` must be STRING, got %s", args[0].Type())
+		}
+
+		value, ok := os.LookupEnv(name.Value)
+		if !ok {
+			return NULL
+		}
+		return &object.String{Value: value}
+	default:
+		return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+	}
+}
+
+func osSetenv(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+
+	name, ok := args[0].(*object.String)
+	if !ok {
+		return newError("argument to `setenv` must be STRING, got %s", args[0].Type())
+	}
+
+	value := convertToString(args[1])
+	if value == nil {
+		return newError("argument to `setenv` not supported, got %s", args[1].Type())
+	}
+
+	if err := os.Setenv(name.Value, value.(*object.String).Value); err != nil {
+		return newError("setenv: %s", err)
+	}
+
+	return EMPTY
+}
+
+func osExit(args ...object.Object) object.Object {
+	code := 0
+
+	switch len(args) {
+	case 0:
+	case 1:
+		integer, ok := args[0].(*object.Integer)
+		if !ok {
+			return newError("argument to `exit` must be INTEGER, got %s", args[0].Type())
+		}
+		code = int(integer.Value)
+	default:
+		return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+	}
+
+	if exitHook != nil {
+		exitHook()
+	}
+	exit(code)
+
+	return EMPTY
+}
+
+// osExec runs a command to completion. A command that runs and fails is not an
+// error, its exit status is reported in the result hash.
+func osExec(args ...object.Object) object.Object {
+	if len(args) < 1 || len(args) > 3 {
+		return newError("wrong number of arguments. got=%d, want=1 to 3", len(args))
+	}
+
+	name, ok := args[0].(*object.String)
+	if !ok {
+		return newError("argument to `exec` must be STRING, got %s", args[0].Type())
+	}
+
+	var cmdArgs []string
+	if len(args) > 1 {
+		arr, ok := args[1].(*object.Array)
+		if !ok {
+			return newError("arguments to `exec` must be ARRAY, got %s", args[1].Type())
+		}
+
+		for _, ele := range arr.Elements {
+			str := convertToString(ele)
+			if str == nil {
+				return newError("argument to `exec` not supported, got %s", ele.Type())
+			}
+			cmdArgs = append(cmdArgs, str.(*object.String).Value)
+		}
+	}
+
+	cmd := exec.Command(name.Value, cmdArgs...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if len(args) > 2 {
+		opts, ok := args[2].(*object.Hash)
+		if !ok {
+			return newError("options to `exec` must be HASH, got %s", args[2].Type())
+		}
+
+		if stdin, ok := hashString(opts, "stdin"); ok {
+			cmd.Stdin = strings.NewReader(stdin)
+		}
+
+		if cwd, ok := hashString(opts, "cwd"); ok {
+			cmd.Dir = cwd
+		}
+
+		if pair, ok := opts.Pairs[(&object.String{Value: "env"}).HashKey()]; ok {
+			env, ok := pair.Value.(*object.Hash)
+			if !ok {
+				return newError("env option to `exec` must be HASH, got %s", pair.Value.Type())
+			}
+
+			cmd.Env = os.Environ()
+			for _, kv := range env.Pairs {
+				value := convertToString(kv.Value)
+				if value == nil {
+					return newError("env value to `exec` not supported, got %s", kv.Value.Type())
+				}
+				cmd.Env = append(cmd.Env, kv.Key.Inspect()+"="+value.(*object.String).Value)
+			}
+		}
+	}
+
+	status := 0
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return newError("exec: %s", err)
+		}
+		status = exitErr.ExitCode()
+	}
+
+	return object.NewHash(map[string]object.Object{
+		"stdout": &object.String{Value: stdout.String()},
+		"stderr": &object.String{Value: stderr.String()},
+		"status": &object.Integer{Value: int64(status)},
+	})
+}
@@ -0,0 +1,95 @@
+package evaluator
+
+import (
+	"os"
+	"testing"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func TestOSArgs(t *testing.T) {
+	SetArgs([]string{"input.txt", "-v"})
+	defer SetArgs(nil)
+
+	evaluated := testEval(`os.args`)
+	array, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if array.Inspect() != "[input.txt, -v]" {
+		t.Errorf("wrong args. got=%s", array.Inspect())
+	}
+
+	testIntegerObject(t, 0, testEval(`len(os.args)`), 2)
+}
+
+func TestOSEnv(t *testing.T) {
+	t.Setenv("CIXAC_TEST_VAR", "hello")
+
+	testStringObject(t, testEval(`os.env("CIXAC_TEST_VAR")`), "hello")
+	testNullObject(t, testEval(`os.env("CIXAC_TEST_UNSET_VAR")`))
+	testStringObject(t, testEval(`os.setenv("CIXAC_TEST_VAR", 42); os.env("CIXAC_TEST_VAR")`), "42")
+	testStringObject(t, testEval(`os.env()["CIXAC_TEST_VAR"]`), "42")
+	testStringObject(t, testEval(`let env = os.env("CIXAC_TEST_VAR"); os.env("CIXAC_TEST_VAR")`), "42")
+}
+
+func TestOSExit(t *testing.T) {
+	var code int
+	exit = func(c int) { code = c }
+	defer func() { exit = os.Exit }()
+
+	testEval(`os.exit(3)`)
+	if code != 3 {
+		t.Errorf("wrong exit code. got=%d, want=3", code)
+	}
+
+	code = 0
+	hooked := false
+	SetExitHook(func() {
+		hooked = true
+		if code != 0 {
+			t.Errorf("hook ran after exit")
+		}
+	})
+	defer SetExitHook(nil)
+
+	testEval(`os.exit(4)`)
+	if !hooked || code != 4 {
+		t.Errorf("hook not run before exit. hooked=%t, code=%d", hooked, code)
+	}
+}
+
+func TestOSExec(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`os.exec("echo", ["hello", 1])["stdout"]`, "hello 1\n"},
+		{`os.exec("sh", ["-c", "echo oops >&2; exit 2"])["stderr"]`, "oops\n"},
+		{`os.exec("sh", ["-c", "exit 2"])["status"]`, 2},
+		{`os.exec("cat", [], {"stdin": "piped"})["stdout"]`, "piped"},
+		{`os.exec("pwd", [], {"cwd": "/"})["stdout"]`, "/\n"},
+		{`os.exec("sh", ["-c", "echo $GREETING"], {"env": {"GREETING": "hi"}})["stdout"]`, "hi\n"},
+		{`os.exec("cixac-no-such-command")`, `exec: exec: "cixac-no-such-command": executable file not found in $PATH`},
+		{`os.exec("echo", "hello")`, "arguments to `exec` must be ARRAY, got STRING"},
+		{`os.nope`, "os has no member nope"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, i, evaluated, int64(expected))
+		case string:
+			if errObj, ok := evaluated.(*object.Error); ok {
+				if errObj.Message != expected {
+					t.Errorf("[test: %d] wrong error message. expected=%q, got=%q", i, expected, errObj.Message)
+				}
+				continue
+			}
+			testStringObject(t, evaluated, expected)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package evaluator
+
+import (
+	"regexp"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func init() {
+	object.ApplyFunction = applyFunction
+}
+
+func reBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	pattern, ok := args[0].(*object.String)
+	if !ok {
+		return newError("argument to `re` must be STRING, got %s", args[0].Type())
+	}
+
+	re, err := regexp.Compile(pattern.Value)
+	if err != nil {
+		return newError("invalid regex %q: %s", pattern.Value, err)
+	}
+
+	return &object.Regex{Value: re}
+}
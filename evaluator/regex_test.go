@@ -0,0 +1,76 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func TestRegex(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`re("\d+")`, `re("\\d+")`},
+		{`re("\d+").pattern()`, `\d+`},
+		{`re("^\d+$").match("123")`, true},
+		{`re("^\d+$").match("12a")`, false},
+		{`re("\d+").find("abc 42 and 7")`, "42"},
+		{`re("\d+").find("abc") == null`, true},
+		{`re("\d+").findAll("abc 42 and 7")`, `[42, 7]`},
+		{`re("\d+").findAll("1 2 3", 2)`, `[1, 2]`},
+		{`re("\d+").findAll("none")`, `[]`},
+		{`re("(?P<year>\d{4})-(?P<month>\d{2})").groups("on 2024-03")["year"]`, "2024"},
+		{`re("(?P<year>\d{4})-(?P<month>\d{2})").groups("on 2024-03")["month"]`, "03"},
+		{`re("(\w+)@(\w+)").groups("me@host")[0]`, "me@host"},
+		{`re("(\w+)@(\w+)").groups("me@host")[2]`, "host"},
+		{`re("a(x)?b").groups("ab")[1] == null`, true},
+		{`re("\d+").groups("abc") == null`, true},
+		{`re("(\w+)@(\w+)").replace("me@host", "$2 at $1")`, "host at me"},
+		{`re("\d+").replace("a1b22", fn(m) { return "<" + m + ">" })`, "a<1>b<22>"},
+		{`re("(?P<n>\d)(\w)").replace("1a 2b", fn(m, g) { return g["n"] + g[2].upper() })`, "1A 2B"},
+		{`re("\d+").replace("a1b2", len)`, "a1b1"},
+		{`re(",\s*").split("a, b,c,   d")`, `[a, b, c, d]`},
+		{`re(",").split("a,b,c", 2)`, `[a, b,c]`},
+		{`"hello world".matches(re("o\s+w"))`, true},
+		{`"hello".matches(re("^h.*x$"))`, false},
+		{`"a-b-c".replace(re("-"), "+")`, "a+b+c"},
+		{`"a-b-c".replace("-", "")`, "abc"},
+		{`"x1y22".replace(re("\d+"), fn(m) { return len(m) })`, "x1y2"},
+		{`re("(")`, "invalid regex \"(\": error parsing regexp: missing closing ): `(`"},
+		{`re(5)`, "argument to `re` must be STRING, got INTEGER"},
+		{`re("a").match(5)`, "argument to `match` must be STRING, got INTEGER"},
+		{`re("a").replace("a", 5)`, "replacement must be STRING or FUNCTION, got INTEGER"},
+		{`re("a").replace("a", fn(m) { return 1 + "a" - 1 })`, "unknown operator: STRING - STRING"},
+		{`"abc".matches("b")`, "argument to `matches` must be REGEX, got STRING"},
+		{`"abc".replace(1, "b")`, "pattern passed to `replace` must be STRING or REGEX, got INTEGER"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, i, evaluated, expected)
+		case string:
+			if errObj, ok := evaluated.(*object.Error); ok {
+				if errObj.Message != expected {
+					t.Errorf("[test: %d] wrong error message. expected=%q, got=%q", i, expected, errObj.Message)
+				}
+				continue
+			}
+
+			got := ""
+			switch obj := evaluated.(type) {
+			case *object.String:
+				got = obj.Value
+			case nil:
+			default:
+				got = obj.Inspect()
+			}
+			if got != expected {
+				t.Errorf("[test: %d] wrong value. expected=%q, got=%q", i, expected, got)
+			}
+		}
+	}
+}
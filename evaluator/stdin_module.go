@@ -0,0 +1,129 @@
+package evaluator
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+// InputReader is the source for input() and the stdin module. The REPL
+// replaces the default so reads go through its line editor instead of
+// competing with it for os.Stdin.
+type InputReader interface {
+	ReadLine(prompt string) (string, error)
+	ReadAll() (string, error)
+}
+
+type bufferedInput struct {
+	r   *bufio.Reader
+	out io.Writer
+}
+
+func NewInputReader(r io.Reader, prompts io.Writer) InputReader {
+	return &bufferedInput{r: bufio.NewReader(r), out: prompts}
+}
+
+func (b *bufferedInput) ReadLine(prompt string) (string, error) {
+	if prompt != "" {
+		io.WriteString(b.out, prompt)
+	}
+
+	line, err := b.r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+func (b *bufferedInput) ReadAll() (string, error) {
+	all, err := io.ReadAll(b.r)
+	return string(all), err
+}
+
+var stdin = NewInputReader(os.Stdin, os.Stdout)
+
+func SetStdin(r InputReader) {
+	stdin = r
+}
+
+func init() {
+	registerModule(&object.Module{
+		Name: "stdin",
+		Members: map[string]object.Object{
+			"readLine": &object.Builtin{Fn: stdinReadLine},
+			"readAll":  &object.Builtin{Fn: stdinReadAll},
+			"lines":    &object.Builtin{Fn: stdinLines},
+		},
+	})
+}
+
+func readLine(prompt string) object.Object {
+	line, err := stdin.ReadLine(prompt)
+	if errors.Is(err, io.EOF) {
+		return NULL
+	}
+	if err != nil {
+		return newError("could not read from stdin: %s", err)
+	}
+
+	return &object.String{Value: line}
+}
+
+func inputBuiltin(args ...object.Object) object.Object {
+	switch len(args) {
+	case 0:
+		return readLine("")
+	case 1:
+		prompt := convertToString(args[0])
+		if prompt == nil {
+			return newError("argument to `input` not supported, got %s", args[0].Type())
+		}
+		return readLine(prompt.(*object.String).Value)
+	default:
+		return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+	}
+}
+
+func stdinReadLine(args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError("wrong number of arguments. got=%d, want=0", len(args))
+	}
+
+	return readLine("")
+}
+
+func stdinReadAll(args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError("wrong number of arguments. got=%d, want=0", len(args))
+	}
+
+	all, err := stdin.ReadAll()
+	if err != nil {
+		return newError("could not read from stdin: %s", err)
+	}
+
+	return &object.String{Value: all}
+}
+
+// stdinLines reads lazily so filters can process input as it arrives
+func stdinLines(args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError("wrong number of arguments. got=%d, want=0", len(args))
+	}
+
+	return &object.Iterator{
+		Next: func() (object.Object, bool) {
+			line := readLine("")
+			if line == NULL {
+				return nil, false
+			}
+			return line, true
+		},
+	}
+}
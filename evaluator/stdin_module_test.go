@@ -0,0 +1,61 @@
+package evaluator
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func TestStdinBuiltins(t *testing.T) {
+	tests := []struct {
+		stdin    string
+		input    string
+		expected any
+	}{
+		{"alice\n", `input()`, "alice"},
+		{"alice\r\n", `input()`, "alice"},
+		{"bob", `input()`, "bob"},
+		{"", `input()`, nil},
+		{"one\ntwo\n", `stdin.readLine(); stdin.readLine()`, "two"},
+		{"one\ntwo\n", `stdin.readLine(); stdin.readLine(); stdin.readLine()`, nil},
+		{"one\ntwo\n", `stdin.readLine(); stdin.readAll()`, "two\n"},
+		{"a\nb\nc", `let out = ""; for (i, line in stdin.lines()) { out = out + i + line }; out`, "0a1b2c"},
+		{"a\nb\nc", `let out = ""; for (i, line in stdin.lines()) { if (i == 1) { break }; out = out + line }; out`, "a"},
+		{"", `input(1, 2)`, "wrong number of arguments. got=2, want=0 or 1"},
+	}
+
+	for i, tt := range tests {
+		SetStdin(NewInputReader(strings.NewReader(tt.stdin), &bytes.Buffer{}))
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case nil:
+			testNullObject(t, evaluated)
+		case string:
+			if errObj, ok := evaluated.(*object.Error); ok {
+				if errObj.Message != expected {
+					t.Errorf("[test: %d] wrong error message. expected=%q, got=%q", i, expected, errObj.Message)
+				}
+				continue
+			}
+			testStringObject(t, evaluated, expected)
+		}
+	}
+
+	SetStdin(NewInputReader(os.Stdin, os.Stdout))
+}
+
+func TestInputPrompt(t *testing.T) {
+	var prompts bytes.Buffer
+	SetStdin(NewInputReader(strings.NewReader("42\n"), &prompts))
+	defer SetStdin(NewInputReader(os.Stdin, os.Stdout))
+
+	testStringObject(t, testEval(`input("age: ")`), "42")
+
+	if prompts.String() != "age: " {
+		t.Errorf("wrong prompt. got=%q", prompts.String())
+	}
+}
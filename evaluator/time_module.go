@@ -0,0 +1,365 @@
+package evaluator
+
+import (
+	"time"
+	_ "time/tzdata" // bundled so zone conversion works without system zoneinfo
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+// Clock is the time source for the time module. Tests replace it with a
+// fixed clock so now() and sleep() are deterministic.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time        { return time.Now() }
+func (systemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+var clock Clock = systemClock{}
+
+func SetClock(c Clock) {
+	clock = c
+}
+
+var timeLayouts = map[string]string{
+	"RFC3339":  time.RFC3339,
+	"RFC1123":  time.RFC1123,
+	"Kitchen":  time.Kitchen,
+	"DateTime": time.DateTime,
+	"DateOnly": time.DateOnly,
+	"TimeOnly": time.TimeOnly,
+}
+
+func init() {
+	members := map[string]object.Object{
+		"now":      &object.Builtin{Fn: timeNow},
+		"unix":     &object.Builtin{Fn: timeUnix},
+		"fromUnix": &object.Builtin{Fn: timeFromUnix},
+		"parse":    &object.Builtin{Fn: timeParse},
+		"format":   &object.Builtin{Fn: timeFormat},
+		"duration": &object.Builtin{Fn: timeDuration},
+		"add":      &object.Builtin{Fn: timeAdd},
+		"sub":      &object.Builtin{Fn: timeSub},
+		"diff":     &object.Builtin{Fn: timeDiff},
+		"inZone":   &object.Builtin{Fn: timeInZone},
+		"sleep":    &object.Builtin{Fn: timeSleep},
+
+		"millisecond": &object.Duration{Value: time.Millisecond},
+		"second":      &object.Duration{Value: time.Second},
+		"minute":      &object.Duration{Value: time.Minute},
+		"hour":        &object.Duration{Value: time.Hour},
+	}
+
+	for name := range timeLayouts {
+		members[name] = &object.String{Value: name}
+	}
+
+	registerModule(&object.Module{Name: "time", Members: members})
+}
+
+func timeNow(args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError("wrong number of arguments. got=%d, want=0", len(args))
+	}
+
+	return &object.Time{Value: clock.Now()}
+}
+
+func timeUnix(args ...object.Object) object.Object {
+	switch len(args) {
+	case 0:
+		return &object.Integer{Value: clock.Now().Unix()}
+	case 1:
+		t, ok := args[0].(*object.Time)
+		if !ok {
+			return newError("argument to `unix` must be TIME, got %s", args[0].Type())
+		}
+		return &object.Integer{Value: t.Value.Unix()}
+	default:
+		return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+	}
+}
+
+func timeFromUnix(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	secs, ok := args[0].(*object.Integer)
+	if !ok {
+		return newError("argument to `fromUnix` must be INTEGER, got %s", args[0].Type())
+	}
+
+	return &object.Time{Value: time.Unix(secs.Value, 0).UTC()}
+}
+
+// layoutArg accepts one of the named layouts or a Go reference layout
+func layoutArg(name string, arg object.Object) (string, *object.Error) {
+	str, ok := arg.(*object.String)
+	if !ok {
+		return "", newError("layout passed to `%s` must be STRING, got %s", name, arg.Type())
+	}
+
+	if layout, ok := timeLayouts[str.Value]; ok {
+		return layout, nil
+	}
+
+	return str.Value, nil
+}
+
+func timeParse(args ...object.Object) object.Object {
+	if len(args) < 1 || len(args) > 3 {
+		return newError("wrong number of arguments. got=%d, want=1 to 3", len(args))
+	}
+
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return newError("first argument to `parse` must be STRING, got %s", args[0].Type())
+	}
+
+	layout := time.RFC3339
+	if len(args) > 1 {
+		var err *object.Error
+		if layout, err = layoutArg("parse", args[1]); err != nil {
+			return err
+		}
+	}
+
+	loc := time.UTC
+	if len(args) > 2 {
+		var err *object.Error
+		if loc, err = locationArg("parse", args[2]); err != nil {
+			return err
+		}
+	}
+
+	t, err := time.ParseInLocation(layout, str.Value, loc)
+	if err != nil {
+		return newError("could not parse time %q: %s", str.Value, err)
+	}
+
+	return &object.Time{Value: t}
+}
+
+func timeFormat(args ...object.Object) object.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+
+	t, ok := args[0].(*object.Time)
+	if !ok {
+		return newError("first argument to `format` must be TIME, got %s", args[0].Type())
+	}
+
+	layout := time.RFC3339
+	if len(args) == 2 {
+		var err *object.Error
+		if layout, err = layoutArg("format", args[1]); err != nil {
+			return err
+		}
+	}
+
+	return &object.String{Value: t.Value.Format(layout)}
+}
+
+// timeDuration builds a duration from milliseconds or a string such as "1h30m"
+func timeDuration(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		return &object.Duration{Value: time.Duration(arg.Value) * time.Millisecond}
+	case *object.String:
+		d, err := time.ParseDuration(arg.Value)
+		if err != nil {
+			return newError("could not parse duration %q", arg.Value)
+		}
+		return &object.Duration{Value: d}
+	default:
+		return newError("argument to `duration` must be INTEGER or STRING, got %s", arg.Type())
+	}
+}
+
+func timeAdd(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+
+	return evalTimeInfixExpression("+", args[0], args[1])
+}
+
+func timeSub(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	if args[1].Type() != object.DURATION_OBJ {
+		return newError("second argument to `sub` must be DURATION, got %s", args[1].Type())
+	}
+
+	return evalTimeInfixExpression("-", args[0], args[1])
+}
+
+func timeDiff(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	if args[0].Type() != object.TIME_OBJ || args[1].Type() != object.TIME_OBJ {
+		return newError("arguments to `diff` must be TIME, got %s and %s", args[0].Type(), args[1].Type())
+	}
+
+	return evalTimeInfixExpression("-", args[0], args[1])
+}
+
+func locationArg(name string, arg object.Object) (*time.Location, *object.Error) {
+	str, ok := arg.(*object.String)
+	if !ok {
+		return nil, newError("zone passed to `%s` must be STRING, got %s", name, arg.Type())
+	}
+
+	loc, err := time.LoadLocation(str.Value)
+	if err != nil {
+		return nil, newError("unknown time zone %q", str.Value)
+	}
+
+	return loc, nil
+}
+
+func timeInZone(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+
+	t, ok := args[0].(*object.Time)
+	if !ok {
+		return newError("first argument to `inZone` must be TIME, got %s", args[0].Type())
+	}
+
+	loc, err := locationArg("inZone", args[1])
+	if err != nil {
+		return err
+	}
+
+	return &object.Time{Value: t.Value.In(loc)}
+}
+
+func timeSleep(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		clock.Sleep(time.Duration(arg.Value) * time.Millisecond)
+	case *object.Duration:
+		clock.Sleep(arg.Value)
+	default:
+		return newError("argument to `sleep` must be INTEGER or DURATION, got %s", arg.Type())
+	}
+
+	return EMPTY
+}
+
+func isTimeOperand(obj object.Object) bool {
+	return obj.Type() == object.TIME_OBJ || obj.Type() == object.DURATION_OBJ
+}
+
+// isTimeOperation reports whether left and right are both times or
+// durations, or a duration and the integer it is scaled by. Other pairs, like
+// a time and null, are compared like any other values.
+func isTimeOperation(left, right object.Object) bool {
+	if isTimeOperand(left) && isTimeOperand(right) {
+		return true
+	}
+
+	return (left.Type() == object.DURATION_OBJ && right.Type() == object.INTEGER_OBJ) ||
+		(left.Type() == object.INTEGER_OBJ && right.Type() == object.DURATION_OBJ)
+}
+
+func evalTimeInfixExpression(operator string, left, right object.Object) object.Object {
+	switch l := left.(type) {
+	case *object.Time:
+		switch r := right.(type) {
+		case *object.Time:
+			return evalTimeComparison(operator, l.Value, r.Value, left, right)
+		case *object.Duration:
+			switch operator {
+			case "+":
+				return &object.Time{Value: l.Value.Add(r.Value)}
+			case "-":
+				return &object.Time{Value: l.Value.Add(-r.Value)}
+			}
+		}
+	case *object.Duration:
+		switch r := right.(type) {
+		case *object.Duration:
+			switch operator {
+			case "+":
+				return &object.Duration{Value: l.Value + r.Value}
+			case "-":
+				return &object.Duration{Value: l.Value - r.Value}
+			case "<":
+				return nativeBoolToBooleanObject(l.Value < r.Value)
+			case ">":
+				return nativeBoolToBooleanObject(l.Value > r.Value)
+			case "<=":
+				return nativeBoolToBooleanObject(l.Value <= r.Value)
+			case ">=":
+				return nativeBoolToBooleanObject(l.Value >= r.Value)
+			case "==":
+				return nativeBoolToBooleanObject(l.Value == r.Value)
+			case "!=":
+				return nativeBoolToBooleanObject(l.Value != r.Value)
+			}
+		case *object.Time:
+			if operator == "+" {
+				return &object.Time{Value: r.Value.Add(l.Value)}
+			}
+		case *object.Integer:
+			switch operator {
+			case "*":
+				return &object.Duration{Value: l.Value * time.Duration(r.Value)}
+			case "/":
+				if r.Value == 0 {
+					return newError("division by zero")
+				}
+				return &object.Duration{Value: l.Value / time.Duration(r.Value)}
+			}
+		}
+	case *object.Integer:
+		if r, ok := right.(*object.Duration); ok && operator == "*" {
+			return &object.Duration{Value: time.Duration(l.Value) * r.Value}
+		}
+	}
+
+	if left.Type() != right.Type() {
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	}
+	return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+func evalTimeComparison(operator string, l, r time.Time, left, right object.Object) object.Object {
+	switch operator {
+	case "-":
+		return &object.Duration{Value: l.Sub(r)}
+	case "<":
+		return nativeBoolToBooleanObject(l.Before(r))
+	case ">":
+		return nativeBoolToBooleanObject(l.After(r))
+	case "<=":
+		return nativeBoolToBooleanObject(!l.After(r))
+	case ">=":
+		return nativeBoolToBooleanObject(!l.Before(r))
+	case "==":
+		return nativeBoolToBooleanObject(l.Equal(r))
+	case "!=":
+		return nativeBoolToBooleanObject(!l.Equal(r))
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
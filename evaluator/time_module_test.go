@@ -0,0 +1,114 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+type fakeClock struct {
+	now   time.Time
+	slept time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.slept += d
+	c.now = c.now.Add(d)
+}
+
+func TestTimeModule(t *testing.T) {
+	fake := &fakeClock{now: time.Date(2024, time.March, 10, 14, 30, 0, 0, time.UTC)}
+	SetClock(fake)
+	defer SetClock(systemClock{})
+
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"time.now()", "2024-03-10T14:30:00Z"},
+		{"time.unix()", 1710081000},
+		{"time.unix(time.fromUnix(0))", 0},
+		{"time.now().year()", 2024},
+		{"time.now().month()", 3},
+		{"time.now().weekday()", "Sunday"},
+		{`time.parse("2024-01-02T03:04:05Z")`, "2024-01-02T03:04:05Z"},
+		{`time.parse("02/01/2024", "02/01/2006").month()`, 1},
+		{`time.parse("2024-01-02", time.DateOnly, "Europe/Paris")`, "2024-01-02T00:00:00+01:00"},
+		{`time.format(time.now(), "2006-01-02")`, "2024-03-10"},
+		{`time.format(time.now(), time.Kitchen)`, "2:30PM"},
+		{`time.format(time.now())`, "2024-03-10T14:30:00Z"},
+		{`time.duration("1h30m")`, "1h30m0s"},
+		{`time.duration(1500)`, "1.5s"},
+		{`time.duration("90m").hours()`, 1.5},
+		{`time.duration(2500).ms()`, 2500},
+		{"time.add(time.now(), time.hour)", "2024-03-10T15:30:00Z"},
+		{"time.sub(time.now(), 2 * time.minute)", "2024-03-10T14:28:00Z"},
+		{"time.diff(time.add(time.now(), time.second), time.now())", "1s"},
+		{"time.now() + time.hour * 24", "2024-03-11T14:30:00Z"},
+		{"time.now() - time.fromUnix(1710080940)", "1m0s"},
+		{"time.hour / 4", "15m0s"},
+		{`time.inZone(time.now(), "America/New_York")`, "2024-03-10T10:30:00-04:00"},
+		{`time.inZone(time.now(), "Asia/Tokyo").hour()`, 23},
+		{`time.inZone(time.now(), "Asia/Tokyo").zone()`, "Asia/Tokyo"},
+		{`time.now() == time.inZone(time.now(), "Asia/Tokyo")`, true},
+		{"time.now() < time.now() + time.second", true},
+		{"time.now() >= time.now()", true},
+		{"time.now() > time.now()", false},
+		{"time.minute < time.hour", true},
+		{"time.minute == time.duration(60000)", true},
+		{"time.now() == null", false},
+		{"time.now() != null", true},
+		{"null == time.now()", false},
+		{"time.second != null", true},
+		{"time.second == null", false},
+		{`let t = null; if (t == null) { t = time.now() }; t != null`, true},
+		{`"took " + time.second`, "took 1s"},
+		{`time.parse("nope")`, `could not parse time "nope": parsing time "nope" as "2006-01-02T15:04:05Z07:00": cannot parse "nope" as "2006"`},
+		{`time.inZone(time.now(), "Mars/Olympus")`, `unknown time zone "Mars/Olympus"`},
+		{`time.duration("soon")`, `could not parse duration "soon"`},
+		{"time.now() * 2", "type mismatch: TIME * INTEGER"},
+		{"time.second * 1.5", "type mismatch: DURATION * FLOAT"},
+		{"time.now() < null", "type mismatch: TIME < NULL"},
+		{"time.now() + time.now()", "unknown operator: TIME + TIME"},
+		{"time.sub(time.now(), time.now())", "second argument to `sub` must be DURATION, got TIME"},
+		{"time.hour / 0", "division by zero"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, i, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, i, evaluated, expected)
+		case bool:
+			testBooleanObject(t, i, evaluated, expected)
+		case string:
+			if errObj, ok := evaluated.(*object.Error); ok {
+				if errObj.Message != expected {
+					t.Errorf("[test: %d] wrong error message. expected=%q, got=%q", i, expected, errObj.Message)
+				}
+				continue
+			}
+			if evaluated == nil || evaluated.Inspect() != expected {
+				t.Errorf("[test: %d] wrong value. expected=%q, got=%+v", i, expected, evaluated)
+			}
+		}
+	}
+}
+
+func TestTimeSleep(t *testing.T) {
+	fake := &fakeClock{now: time.Unix(0, 0).UTC()}
+	SetClock(fake)
+	defer SetClock(systemClock{})
+
+	evaluated := testEval(`time.sleep(250); time.sleep(time.second); time.unix()`)
+	testIntegerObject(t, 0, evaluated, 1)
+
+	if fake.slept != 1250*time.Millisecond {
+		t.Errorf("slept for wrong duration. got=%s", fake.slept)
+	}
+}
@@ -0,0 +1,296 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/object"
+)
+
+// typeNames are the names typeof returns, as written in type annotations
+var typeNames = map[object.ObjectType]string{
+	object.INTEGER_OBJ:  "int",
+	object.BIGINT_OBJ:   "int",
+	object.FLOAT_OBJ:    "float",
+	object.DECIMAL_OBJ:  "decimal",
+	object.STRING_OBJ:   "string",
+	object.BOOLEAN_OBJ:  "bool",
+	object.NULL_OBJ:     "null",
+	object.ARRAY_OBJ:    "array",
+	object.HASH_OBJ:     "hash",
+	object.FUNCTION_OBJ: "fn",
+	object.BUILTIN_OBJ:  "fn",
+	object.TIME_OBJ:     "time",
+	object.DURATION_OBJ: "duration",
+	object.REGEX_OBJ:    "regex",
+	object.MODULE_OBJ:   "module",
+	object.ITERATOR_OBJ: "iterator",
+	object.RESPONSE_OBJ: "response",
+	object.REQUEST_OBJ:  "request",
+}
+
+func typeName(obj object.Object) string {
+	if name, ok := typeNames[obj.Type()]; ok {
+		return name
+	}
+	return strings.ToLower(string(obj.Type()))
+}
+
+func isTypeName(name string) bool {
+	if name == "any" {
+		return true
+	}
+	for _, known := range typeNames {
+		if known == name {
+			return true
+		}
+	}
+	return false
+}
+
+func evalIsExpression(node *ast.IsExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	matches, err := matchesType(left, node.Type)
+	if err != nil {
+		return err
+	}
+	return nativeBoolToBooleanObject(matches)
+}
+
+// matchesType reports whether obj has the type of an annotation. The
+// elements of arrays and hashes are checked too, and a function matches
+// a function type with as many parameters.
+func matchesType(obj object.Object, typ ast.TypeExpr) (bool, *object.Error) {
+	switch t := typ.(type) {
+	case *ast.NamedType:
+		if !isTypeName(t.Name) {
+			return false, newError("unknown type %s", t.Name)
+		}
+		return t.Name == "any" || t.Name == typeName(obj), nil
+
+	case *ast.UnionType:
+		for _, member := range t.Types {
+			matches, err := matchesType(obj, member)
+			if err != nil || matches {
+				return matches, err
+			}
+		}
+		return false, nil
+
+	case *ast.ArrayType:
+		array, ok := obj.(*object.Array)
+		if !ok {
+			return false, nil
+		}
+		for _, elem := range array.Elements {
+			if matches, err := matchesType(elem, t.Element); err != nil || !matches {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case *ast.HashType:
+		hash, ok := obj.(*object.Hash)
+		if !ok {
+			return false, nil
+		}
+		for _, pair := range hash.Pairs {
+			if matches, err := matchesType(pair.Key, t.Key); err != nil || !matches {
+				return false, err
+			}
+			if matches, err := matchesType(pair.Value, t.Value); err != nil || !matches {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case *ast.FunctionType:
+		switch fn := obj.(type) {
+		case *object.Function:
+			return len(fn.Parameters) == len(t.Parameters), nil
+		case *object.Builtin:
+			return true, nil
+		}
+		return false, nil
+	}
+
+	return false, nil
+}
+
+func typeofBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	return &object.String{Value: typeName(args[0])}
+}
+
+func conversionError(to string, arg object.Object) *object.Error {
+	if str, ok := arg.(*object.String); ok {
+		return newError("can't convert %q to %s", str.Value, to)
+	}
+	return newError("can't convert %s to %s", typeName(arg), to)
+}
+
+// intBuiltin converts to an integer. Floats and decimals are truncated
+// toward zero, strings must hold a base 10 integer and true is 1.
+func intBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer, *object.BigInteger:
+		return arg
+	case *object.Float:
+		if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+			return conversionError("int", arg)
+		}
+		value, _ := big.NewFloat(math.Trunc(arg.Value)).Int(nil)
+		return normalizeBigInteger(value)
+	case *object.Decimal:
+		whole, _, _ := strings.Cut(arg.Inspect(), ".")
+		value, _ := new(big.Int).SetString(whole, 10)
+		return normalizeBigInteger(value)
+	case *object.String:
+		value, ok := new(big.Int).SetString(strings.TrimSpace(arg.Value), 10)
+		if !ok {
+			return conversionError("int", arg)
+		}
+		return normalizeBigInteger(value)
+	case *object.Boolean:
+		if arg.Value {
+			return &object.Integer{Value: 1}
+		}
+		return &object.Integer{Value: 0}
+	}
+
+	return conversionError("int", args[0])
+}
+
+// floatBuiltin converts to a float. Strings must hold a decimal number and
+// true is 1.
+func floatBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Float:
+		return arg
+	case *object.Integer, *object.BigInteger:
+		value, _ := toFloat(arg)
+		return &object.Float{Value: value}
+	case *object.Decimal:
+		value, _ := strconv.ParseFloat(arg.Inspect(), 64)
+		return &object.Float{Value: value}
+	case *object.String:
+		value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return conversionError("float", arg)
+		}
+		return &object.Float{Value: value}
+	case *object.Boolean:
+		if arg.Value {
+			return &object.Float{Value: 1}
+		}
+		return &object.Float{Value: 0}
+	}
+
+	return conversionError("float", args[0])
+}
+
+// strBuiltin converts any value to the string + would join it as, and
+// other values to how they print
+func strBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	if str := convertToString(args[0]); str != nil {
+		return str
+	}
+	return &object.String{Value: args[0].Inspect()}
+}
+
+// boolBuiltin converts to a boolean. Numbers are true unless zero, null is
+// false and strings must be "true" or "false". Unlike in a condition, where
+// only false and null are false, other values can't be converted.
+func boolBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Boolean:
+		return nativeBoolToBooleanObject(arg.Value)
+	case *object.Null:
+		return FALSE
+	case *object.Integer:
+		return nativeBoolToBooleanObject(arg.Value != 0)
+	case *object.BigInteger:
+		return nativeBoolToBooleanObject(arg.Value.Sign() != 0)
+	case *object.Float:
+		return nativeBoolToBooleanObject(arg.Value != 0)
+	case *object.Decimal:
+		return nativeBoolToBooleanObject(arg.Value.Sign() != 0)
+	case *object.String:
+		switch strings.TrimSpace(arg.Value) {
+		case "true":
+			return TRUE
+		case "false":
+			return FALSE
+		}
+	}
+
+	return conversionError("bool", args[0])
+}
+
+// arrayBuiltin converts to a new array. A string becomes its characters, a
+// hash its [key, value] pairs in no particular order, an iterator the
+// values left in it and null an empty array.
+func arrayBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	elements := []object.Object{}
+
+	switch arg := args[0].(type) {
+	case *object.Array:
+		elements = append(elements, arg.Elements...)
+	case *object.String:
+		for i := 0; i < len(arg.Value); {
+			_, size := utf8.DecodeRuneInString(arg.Value[i:])
+			elements = append(elements, &object.String{Value: arg.Value[i : i+size]})
+			i += size
+		}
+	case *object.Hash:
+		for _, pair := range arg.Pairs {
+			elements = append(elements, &object.Array{Elements: []object.Object{pair.Key, pair.Value}})
+		}
+	case *object.Iterator:
+		for {
+			value, ok := arg.Next()
+			if !ok {
+				break
+			}
+			if isError(value) {
+				return value
+			}
+			elements = append(elements, value)
+		}
+	case *object.Null:
+	default:
+		return conversionError("array", args[0])
+	}
+
+	return &object.Array{Elements: elements}
+}
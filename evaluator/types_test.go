@@ -0,0 +1,161 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func TestTypeof(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"typeof(1)", "int"},
+		{"typeof(2 ** 80)", "int"},
+		{"typeof(1.5)", "float"},
+		{"typeof(1.5d)", "decimal"},
+		{`typeof("s")`, "string"},
+		{"typeof(true)", "bool"},
+		{"typeof(null)", "null"},
+		{"typeof([])", "array"},
+		{"typeof({})", "hash"},
+		{"typeof(fn() {})", "fn"},
+		{"typeof(len)", "fn"},
+		{"typeof(math)", "module"},
+		{`typeof(re("a"))`, "regex"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("[test: %d] wrong result for %q. got=%v, want=%s", i, tt.input, evaluated, tt.expected)
+		}
+	}
+}
+
+func TestIsExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1 is int", true},
+		{"1 is float", false},
+		{"2 ** 80 is int", true},
+		{"1.5 is int | float", true},
+		{`"s" is int | null`, false},
+		{"null is int | null", true},
+		{"1 is any", true},
+		{"[1, 2] is [int]", true},
+		{`[1, "a"] is [int]`, false},
+		{`[1, "a"] is [int | string]`, true},
+		{"[] is [string]", true},
+		{"[1] is array", true},
+		{`{"a": 1} is {string: int}`, true},
+		{`{"a": 1} is {string: string}`, false},
+		{"fn(a, b) { a } is fn(int, int) -> int", true},
+		{"fn(a) { a } is fn(int, int)", false},
+		{"len is fn(string) -> int", true},
+		{"1 is int == true", true},
+		{"let x = 5; if (x is int) { 1 } else { 2 }", true},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+		if integer, ok := evaluated.(*object.Integer); ok {
+			evaluated = nativeBoolToBooleanObject(integer.Value == 1)
+		}
+		testBooleanObject(t, i, evaluated, tt.expected)
+	}
+
+	evaluated := testEval("1 is bogus")
+	if err, ok := evaluated.(*object.Error); !ok || err.Message != "unknown type bogus" {
+		t.Errorf("expected unknown type error, got=%v", evaluated)
+	}
+}
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"int(7)", "7"},
+		{`int("12")`, "12"},
+		{`int(" -12 ")`, "-12"},
+		{`int("99999999999999999999")`, "99999999999999999999"},
+		{"int(3.9)", "3"},
+		{"int(-3.9)", "-3"},
+		{"int(100000000000000000000.0)", "100000000000000000000"},
+		{"int(12.99d)", "12"},
+		{"int(-0.5d)", "0"},
+		{"int(true)", "1"},
+		{"int(false)", "0"},
+		{"float(2)", "2.0000"},
+		{`float("1.5")`, "1.5000"},
+		{`float("1e3")`, "1000.0000"},
+		{"float(0.25d)", "0.2500"},
+		{"float(true)", "1.0000"},
+		{"str(1)", "1"},
+		{"str(1.5)", "1.5"},
+		{"str(2.50d)", "2.50"},
+		{"str(true)", "true"},
+		{"str(null)", "null"},
+		{"str([1, 2])", "[1, 2]"},
+		{`str("s")`, "s"},
+		{"bool(1)", "true"},
+		{"bool(0)", "false"},
+		{"bool(0.0)", "false"},
+		{"bool(0d)", "false"},
+		{"bool(null)", "false"},
+		{`bool("true")`, "true"},
+		{`bool("false")`, "false"},
+		{"array([1, 2])", "[1, 2]"},
+		{`array("héllo")`, "[h, é, l, l, o]"},
+		{`array({"a": 1})`, "[[a, 1]]"},
+		{"array(null)", "[]"},
+		{"let a = [1]; let b = array(a); b.push(2); a", "[1]"},
+		{"let str = 5; str", "5"},
+		{"let f = fn(array) { len(array) }; f([1, 2])", "2"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("[test: %d] wrong result for %q. got=%v, want=%s", i, tt.input, evaluated, tt.expected)
+		}
+	}
+}
+
+func TestConversionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`int("x")`, `can't convert "x" to int`},
+		{`int("1.5")`, `can't convert "1.5" to int`},
+		{`int("")`, `can't convert "" to int`},
+		{"int(null)", "can't convert null to int"},
+		{"int([1])", "can't convert array to int"},
+		{`float("x")`, `can't convert "x" to float`},
+		{`float("nan")`, `can't convert "nan" to float`},
+		{"float({})", "can't convert hash to float"},
+		{`bool("yes")`, `can't convert "yes" to bool`},
+		{"bool([])", "can't convert array to bool"},
+		{"array(1)", "can't convert int to array"},
+		{"int(1, 2)", "wrong number of arguments. got=2, want=1"},
+		{"typeof()", "wrong number of arguments. got=0, want=1"},
+		{"let typeof = 1", "Identifier typeof has same name as builtin"},
+	}
+
+	for i, tt := range tests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("[test: %d] no error for %q. got=%T (%+v)", i, tt.input, evaluated, evaluated)
+			continue
+		}
+		if err.Message != tt.expected {
+			t.Errorf("[test: %d] wrong error for %q. expected=%q, got=%q", i, tt.input, tt.expected, err.Message)
+		}
+	}
+}
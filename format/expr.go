@@ -0,0 +1,439 @@
+package format
+
+import (
+	"sort"
+
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/parser"
+	"github.com/joshuahenriques/cixac/token"
+)
+
+// primary is the precedence of expressions that are never split by an
+// operator, such as literals and names
+const primary = parser.INDEX + 1
+
+func (p *printer) expr(e ast.Expression) {
+	switch e := e.(type) {
+	case *ast.Identifier:
+		p.write(e.Value)
+
+	case *ast.IntegerLiteral, *ast.BigIntegerLiteral, *ast.FloatLiteral, *ast.DecimalLiteral, *ast.Boolean, *ast.Null:
+		p.write(e.TokenLiteral())
+
+	case *ast.StringLiteral:
+		p.write(`"` + e.Value + `"`)
+
+	case *ast.PrefixExpression:
+		p.write(e.Operator)
+		// a space would do as well, but -(-x) reads better than - -x
+		p.operand(e.Right, precedence(e.Right) <= parser.PREFIX && !isPrefix(e.Right) ||
+			e.Operator == "-" && firstChar(e.Right) == '-')
+
+	case *ast.PostfixExpression:
+		p.operand(e.Left, precedence(e.Left) < primary)
+		p.write(e.Operator)
+
+	case *ast.InfixExpression:
+		left, right := infixParens(e)
+		p.operand(e.Left, left)
+		p.write(" " + e.Operator + " ")
+		p.operand(e.Right, right)
+
+	case *ast.IsExpression:
+		p.operand(e.Left, precedence(e.Left) < parser.EQUALS)
+		p.write(" is ")
+		p.typ(e.Type)
+
+	case *ast.CallExpression:
+		p.operand(e.Function, precedence(e.Function) < parser.CALL)
+		p.arguments(e)
+
+	case *ast.BuiltinExpression:
+		p.operand(e.Left, precedence(e.Left) < parser.CALL)
+		p.write(".")
+		p.expr(e.Builtin.Function)
+		p.arguments(e.Builtin)
+
+	case *ast.PropertyExpression:
+		p.operand(e.Left, precedence(e.Left) < parser.CALL)
+		p.write("." + e.Property.Value)
+
+	case *ast.IndexExpression:
+		p.operand(e.Left, precedence(e.Left) < parser.CALL)
+		p.write("[")
+		p.expr(e.Index)
+		p.write("]")
+
+	case *ast.ArrayLiteral:
+		p.list(e.Token, "]", e.Elements, func(i int) { p.expr(e.Elements[i]) })
+
+	case *ast.HashLiteral:
+		keys := make([]ast.Expression, 0, len(e.Pairs))
+		for key := range e.Pairs {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return less(start(keys[i]), start(keys[j])) })
+
+		p.list(e.Token, "}", keys, func(i int) {
+			p.expr(keys[i])
+			p.write(": ")
+			p.expr(e.Pairs[keys[i]])
+		})
+
+	case *ast.FunctionLiteral:
+		p.write("fn")
+		p.signature(e)
+		p.block(e.Body)
+
+	case *ast.IfExpression:
+		for i, c := range e.Conditions {
+			if i != 0 {
+				p.write(" else ")
+			}
+			p.write("if (")
+			p.expr(c.Condition)
+			p.write(") ")
+			p.block(c.Consequence)
+		}
+		if e.Alternative != nil {
+			p.write(" else ")
+			p.block(e.Alternative)
+		}
+
+	case *ast.MatchExpression:
+		p.match(e)
+	}
+}
+
+// operand prints e, in parentheses when paren is set
+func (p *printer) operand(e ast.Expression, paren bool) {
+	if paren {
+		p.write("(")
+	}
+	p.expr(e)
+	if paren {
+		p.write(")")
+	}
+}
+
+// infixParens reports whether the operands of e need parentheses to keep
+// their place. Operators associate to the left, except for **.
+func infixParens(e *ast.InfixExpression) (left, right bool) {
+	prec := parser.Precedence(e.Token.Type)
+
+	left = precedence(e.Left) < prec
+	right = precedence(e.Right) <= prec && !isPrefix(e.Right)
+	if e.Token.Type == token.POW {
+		left = precedence(e.Left) <= prec
+		right = precedence(e.Right) < parser.PREFIX
+	}
+
+	return left, right
+}
+
+// precedence is how tightly e holds together, it needs parentheses as the
+// operand of an operator that binds tighter
+func precedence(e ast.Expression) int {
+	switch e := e.(type) {
+	case *ast.InfixExpression:
+		return parser.Precedence(e.Token.Type)
+	case *ast.IsExpression:
+		return parser.EQUALS
+	case *ast.PrefixExpression:
+		return parser.PREFIX
+	case *ast.PostfixExpression:
+		return parser.POSTFIX
+	case *ast.CallExpression, *ast.BuiltinExpression, *ast.PropertyExpression:
+		return parser.CALL
+	case *ast.IndexExpression:
+		return parser.INDEX
+	}
+
+	return primary
+}
+
+// isPrefix reports whether e is a prefix expression, which always ends where
+// its operand does
+func isPrefix(e ast.Expression) bool {
+	_, ok := e.(*ast.PrefixExpression)
+	return ok
+}
+
+// firstChar is the first character e is printed with
+func firstChar(e ast.Expression) byte {
+	switch e := e.(type) {
+	case *ast.InfixExpression:
+		if left, _ := infixParens(e); left {
+			return '('
+		}
+		return firstChar(e.Left)
+	case *ast.IsExpression:
+		return firstOperandChar(e.Left, precedence(e.Left) < parser.EQUALS)
+	case *ast.PostfixExpression:
+		return firstOperandChar(e.Left, precedence(e.Left) < primary)
+	case *ast.CallExpression:
+		return firstOperandChar(e.Function, precedence(e.Function) < parser.CALL)
+	case *ast.BuiltinExpression:
+		return firstOperandChar(e.Left, precedence(e.Left) < parser.CALL)
+	case *ast.PropertyExpression:
+		return firstOperandChar(e.Left, precedence(e.Left) < parser.CALL)
+	case *ast.IndexExpression:
+		return firstOperandChar(e.Left, precedence(e.Left) < parser.CALL)
+	case *ast.PrefixExpression:
+		return e.Operator[0]
+	case *ast.StringLiteral:
+		return '"'
+	}
+
+	if literal := e.TokenLiteral(); literal != "" {
+		return literal[0]
+	}
+	return 0
+}
+
+func firstOperandChar(e ast.Expression, paren bool) byte {
+	if paren {
+		return '('
+	}
+	return firstChar(e)
+}
+
+// start is the first token of e in the source, leaving out parentheses
+func start(e ast.Expression) token.Token {
+	switch e := e.(type) {
+	case *ast.InfixExpression:
+		return start(e.Left)
+	case *ast.IsExpression:
+		return start(e.Left)
+	case *ast.PostfixExpression:
+		return start(e.Left)
+	case *ast.CallExpression:
+		return start(e.Function)
+	case *ast.BuiltinExpression:
+		return start(e.Left)
+	case *ast.PropertyExpression:
+		return start(e.Left)
+	case *ast.IndexExpression:
+		return start(e.Left)
+	case *ast.Identifier:
+		return e.Token
+	case *ast.IntegerLiteral:
+		return e.Token
+	case *ast.BigIntegerLiteral:
+		return e.Token
+	case *ast.FloatLiteral:
+		return e.Token
+	case *ast.DecimalLiteral:
+		return e.Token
+	case *ast.StringLiteral:
+		return e.Token
+	case *ast.Boolean:
+		return e.Token
+	case *ast.Null:
+		return e.Token
+	case *ast.PrefixExpression:
+		return e.Token
+	case *ast.ArrayLiteral:
+		return e.Token
+	case *ast.HashLiteral:
+		return e.Token
+	case *ast.FunctionLiteral:
+		return e.Token
+	case *ast.IfExpression:
+		return e.Token
+	case *ast.MatchExpression:
+		return e.Token
+	}
+
+	return token.Token{}
+}
+
+func less(a, b token.Token) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
+
+func (p *printer) arguments(call *ast.CallExpression) {
+	p.list(call.Token, ")", call.Arguments, func(i int) { p.expr(call.Arguments[i]) })
+}
+
+// list prints items between open and closing with item. They go on one
+// line, unless the first of them started on a line after open, then each
+// gets its own.
+func (p *printer) list(open token.Token, closing string, items []ast.Expression, item func(i int)) {
+	p.write(open.Literal)
+
+	n := len(items)
+	if n == 0 || start(items[0]).Line == open.Line {
+		for i := 0; i < n; i++ {
+			if i != 0 {
+				p.write(", ")
+			}
+			item(i)
+		}
+		p.write(closing)
+		return
+	}
+
+	p.indent++
+	for i := 0; i < n; i++ {
+		p.newline()
+		item(i)
+		if i != n-1 {
+			p.write(",")
+		}
+	}
+	p.indent--
+
+	p.newline()
+	p.write(closing)
+}
+
+func (p *printer) signature(fl *ast.FunctionLiteral) {
+	p.write("(")
+	for i, param := range fl.Parameters {
+		if i != 0 {
+			p.write(", ")
+		}
+		p.write(param.Value)
+		if i < len(fl.ParameterTypes) && fl.ParameterTypes[i] != nil {
+			p.write(": ")
+			p.typ(fl.ParameterTypes[i])
+		}
+	}
+	p.write(") ")
+
+	if fl.ReturnType != nil {
+		p.write("-> ")
+		p.typ(fl.ReturnType)
+		p.write(" ")
+	}
+}
+
+func (p *printer) match(me *ast.MatchExpression) {
+	p.write("match ")
+	p.expr(me.Value)
+	p.write(" {")
+
+	if len(me.Arms) == 0 {
+		p.write("}")
+		return
+	}
+
+	p.indent++
+	for _, arm := range me.Arms {
+		p.newline()
+		p.pattern(arm.Pattern)
+		if arm.Guard != nil {
+			p.write(" if ")
+			p.expr(arm.Guard)
+		}
+		p.write(" => ")
+
+		// the parser wraps an arm that isn't a block in one
+		if arm.Body.Token.Type == token.LBRACE {
+			p.block(arm.Body)
+		} else {
+			p.expr(arm.Body.Statements[0].(*ast.ExpressionStatement).Expression)
+			p.write(",")
+		}
+	}
+	p.indent--
+
+	p.newline()
+	p.write("}")
+}
+
+func (p *printer) pattern(pattern ast.Pattern) {
+	switch pt := pattern.(type) {
+	case *ast.LiteralPattern:
+		p.expr(pt.Value)
+
+	case *ast.WildcardPattern:
+		p.write("_")
+
+	case *ast.BindingPattern:
+		p.write(pt.Name.Value)
+
+	case *ast.ArrayPattern:
+		p.write("[")
+		for i, element := range pt.Elements {
+			if i != 0 {
+				p.write(", ")
+			}
+			p.pattern(element)
+		}
+		if pt.Rest != nil {
+			if len(pt.Elements) != 0 {
+				p.write(", ")
+			}
+			p.write("...")
+			if binding, ok := pt.Rest.(*ast.BindingPattern); ok {
+				p.write(binding.Name.Value)
+			}
+		}
+		p.write("]")
+
+	case *ast.HashPattern:
+		p.write("{")
+		for i, key := range pt.Keys {
+			if i != 0 {
+				p.write(", ")
+			}
+			p.expr(key)
+			p.write(": ")
+			p.pattern(pt.Values[i])
+		}
+		p.write("}")
+	}
+}
+
+func (p *printer) typ(t ast.TypeExpr) {
+	switch t := t.(type) {
+	case *ast.NamedType:
+		p.write(t.Name)
+
+	case *ast.ArrayType:
+		p.write("[")
+		p.typ(t.Element)
+		p.write("]")
+
+	case *ast.HashType:
+		p.write("{")
+		p.typ(t.Key)
+		p.write(": ")
+		p.typ(t.Value)
+		p.write("}")
+
+	case *ast.FunctionType:
+		p.write("fn(")
+		for i, param := range t.Parameters {
+			if i != 0 {
+				p.write(", ")
+			}
+			p.typ(param)
+		}
+		p.write(")")
+		if t.Return != nil {
+			p.write(" -> ")
+			p.typ(t.Return)
+		}
+
+	case *ast.UnionType:
+		for i, member := range t.Types {
+			if i != 0 {
+				p.write(" | ")
+			}
+			// the return type of a function would take in the rest of the union
+			if ft, ok := member.(*ast.FunctionType); ok && ft.Return != nil {
+				p.write("(")
+				p.typ(ft)
+				p.write(")")
+			} else {
+				p.typ(member)
+			}
+		}
+	}
+}
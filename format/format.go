@@ -0,0 +1,392 @@
+// Package format prints Cixac source in its canonical layout.
+//
+// The program is parsed and printed back from its AST, so the layout doesn't
+// depend on how the source was written: one statement per line, blocks open
+// on the line of their statement and close on a line of their own, one tab
+// per indentation level, single spaces around operators and only the
+// parentheses the precedence of the operators needs. Array, hash and argument
+// lists stay on one line unless their first element starts on a new line, then
+// they get an element per line.
+//
+// Comments can only stand between statements. The lexer keeps them as trivia,
+// and they are printed before the statement that follows them, after the
+// statement they share a line with or after the { they share a line with. A
+// single blank line between statements is kept.
+package format
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/parser"
+	"github.com/joshuahenriques/cixac/token"
+)
+
+// Source returns src in canonical form. Source that doesn't parse is
+// returned as an error listing the diagnostics.
+func Source(src []byte) ([]byte, error) {
+	program, err := parse(string(src))
+	if err != nil {
+		return nil, err
+	}
+
+	// the lexer skips a #! line, it is kept as it is
+	var shebang string
+	if bytes.HasPrefix(src, []byte("#!")) {
+		line, _, _ := bytes.Cut(src, []byte("\n"))
+		shebang = string(bytes.TrimRight(line, "\r")) + "\n"
+	}
+
+	tokens, comments := lex(string(src))
+	p := &printer{tokens: tokens, comments: comments}
+	p.statements(program.Statements, tokens[len(tokens)-1])
+	if p.out.Len() != 0 {
+		p.out.WriteByte('\n')
+	}
+	out := p.out.String()
+
+	if !sameProgram(program, comments, out) {
+		return nil, errors.New("formatting changed the program")
+	}
+
+	return []byte(shebang + out), nil
+}
+
+func parse(src string) (*ast.Program, error) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+
+	if diagnostics := p.Diagnostics(); len(diagnostics) != 0 {
+		msgs := make([]string, len(diagnostics))
+		for i, d := range diagnostics {
+			msgs[i] = d.String()
+		}
+		return nil, errors.New(strings.Join(msgs, "\n"))
+	}
+
+	return program, nil
+}
+
+// lex returns the tokens of src other than comments, ending with EOF, and
+// the comments
+func lex(src string) ([]token.Token, []token.Comment) {
+	l := lexer.New(src)
+
+	var tokens []token.Token
+	for {
+		tok := l.NextToken()
+		switch tok.Type {
+		case token.COMMENT, token.COMMENT_START, token.COMMENT_END:
+			continue
+		}
+
+		tokens = append(tokens, tok)
+		if tok.Type == token.EOF {
+			return tokens, l.Comments()
+		}
+	}
+}
+
+// sameProgram is a safety net: formatting only ever changes the layout, so
+// out parses to the same tree with the same comments
+func sameProgram(program *ast.Program, comments []token.Comment, out string) bool {
+	formatted, err := parse(out)
+	if err != nil || dump(formatted) != dump(program) {
+		return false
+	}
+
+	_, outComments := lex(out)
+	if len(outComments) != len(comments) {
+		return false
+	}
+	for i := range comments {
+		if outComments[i].Text != strings.TrimRight(comments[i].Text, " \t") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dump prints the tree of program without the positions of its tokens
+func dump(program *ast.Program) string {
+	var out strings.Builder
+	ast.Fprint(&out, program)
+
+	lines := strings.Split(out.String(), "\n")
+	for i, line := range lines {
+		if open := strings.LastIndex(line, " ("); open != -1 && strings.HasSuffix(line, ")") {
+			lines[i] = line[:open]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type printer struct {
+	out strings.Builder
+	bol bool // at the beginning of a line, before its indentation
+
+	indent   int
+	tokens   []token.Token
+	comments []token.Comment
+	next     int // index of the first comment not printed yet
+}
+
+func (p *printer) write(s string) {
+	if p.bol {
+		p.out.WriteString(strings.Repeat("\t", p.indent))
+		p.bol = false
+	}
+	p.out.WriteString(s)
+}
+
+func (p *printer) newline() {
+	p.out.WriteByte('\n')
+	p.bol = true
+}
+
+// startLine starts the line of an item that begins on source line line,
+// keeping one blank line when there was one after the item that ended on prev
+func (p *printer) startLine(line, prev int) {
+	if p.out.Len() != 0 {
+		p.newline()
+	}
+	if prev != 0 && line > prev+1 {
+		p.newline()
+	}
+}
+
+// statements prints stmts one per line, each with the comments before it and
+// on its line, and then the comments left before end
+func (p *printer) statements(stmts []ast.Statement, end token.Token) {
+	prev := 0
+	for i, stmt := range stmts {
+		start := statementStart(stmt)
+		prev = p.leadingComments(start, prev)
+
+		p.startLine(start.Line, prev)
+		p.statement(stmt)
+
+		limit := end
+		if i+1 < len(stmts) {
+			limit = statementStart(stmts[i+1])
+			if needsSemicolon(stmt, stmts[i+1]) {
+				p.write(";")
+			}
+		}
+
+		prev = p.endLine(limit)
+		for p.next < len(p.comments) && before(p.comments[p.next], limit) && p.comments[p.next].Line <= prev {
+			p.write(" " + p.comment())
+		}
+	}
+
+	p.leadingComments(end, prev)
+}
+
+// leadingComments prints the comments before tok, each on a line of its own
+// unless it shares a line with the one before. It returns the line the last
+// of them ends on, or prev when there are none.
+func (p *printer) leadingComments(tok token.Token, prev int) int {
+	for p.next < len(p.comments) && before(p.comments[p.next], tok) {
+		c := p.comments[p.next]
+		if prev != 0 && c.Line == prev {
+			p.write(" ")
+		} else {
+			p.startLine(c.Line, prev)
+		}
+		p.write(p.comment())
+		prev = c.Line + strings.Count(c.Text, "\n")
+	}
+
+	return prev
+}
+
+// comment returns the text of the next comment and moves past it
+func (p *printer) comment() string {
+	c := p.comments[p.next]
+	p.next++
+	return strings.TrimRight(c.Text, " \t")
+}
+
+// endLine is the source line of the last token before limit
+func (p *printer) endLine(limit token.Token) int {
+	i := sort.Search(len(p.tokens), func(i int) bool {
+		tok := p.tokens[i]
+		return tok.Line > limit.Line || (tok.Line == limit.Line && tok.Column >= limit.Column)
+	})
+	if i == 0 {
+		return 0
+	}
+
+	last := p.tokens[i-1]
+	return last.Line + strings.Count(last.Literal, "\n")
+}
+
+func before(c token.Comment, tok token.Token) bool {
+	return c.Line < tok.Line || (c.Line == tok.Line && c.Column < tok.Column)
+}
+
+func (p *printer) statement(stmt ast.Statement) {
+	switch s := stmt.(type) {
+	case *ast.LetStatement:
+		p.let(s)
+
+	case *ast.ReassignStatement:
+		p.reassign(s)
+
+	case *ast.ReturnStatement:
+		p.write("return ")
+		p.expr(s.ReturnValue)
+
+	case *ast.ExpressionStatement:
+		p.expr(s.Expression)
+
+	case *ast.DeferStatement:
+		p.write("defer ")
+		p.expr(s.Call)
+
+	case *ast.BreakStatement:
+		p.write("break")
+
+	case *ast.ContinueStatement:
+		p.write("continue")
+
+	case *ast.WhileStatement:
+		p.write("while (")
+		p.expr(s.Condition)
+		p.write(") ")
+		p.block(s.Body)
+
+	case *ast.ForInLoopStatement:
+		p.write("for (" + s.KeyIndex.Value + ", " + s.ValueElement.Value + " in ")
+		p.expr(s.Iterable)
+		p.write(") ")
+		p.block(s.Body)
+
+	case *ast.ForLoopStatement:
+		p.write("for (")
+		p.let(s.Initialization)
+		p.write("; ")
+		p.expr(s.Condition)
+		p.write("; ")
+		switch update := s.Update.(type) {
+		case *ast.ReassignStatement:
+			p.reassign(update)
+		case ast.Expression:
+			p.expr(update)
+		}
+		p.write(") ")
+		p.block(s.Body)
+
+	case *ast.FunctionDeclaration:
+		p.write("fn " + s.Name.Value)
+		p.signature(s.Function)
+		p.block(s.Function.Body)
+	}
+}
+
+func (p *printer) let(s *ast.LetStatement) {
+	if s.Name.Const {
+		p.write("const ")
+	} else {
+		p.write("let ")
+	}
+
+	p.write(s.Name.Value)
+	if s.Type != nil {
+		p.write(": ")
+		p.typ(s.Type)
+	}
+	p.write(" = ")
+	p.expr(s.Value)
+}
+
+func (p *printer) reassign(s *ast.ReassignStatement) {
+	p.write(s.Name.Value + " " + s.Token.Literal + " ")
+	p.expr(s.Value)
+}
+
+// block prints the statements of b indented between braces, keeping the
+// comments on the line of the { on that line
+func (p *printer) block(b *ast.BlockStatement) {
+	p.write("{")
+
+	first := b.End
+	if len(b.Statements) != 0 {
+		first = statementStart(b.Statements[0])
+	}
+
+	opened := false
+	for p.next < len(p.comments) && before(p.comments[p.next], first) && p.comments[p.next].Line == b.Token.Line {
+		p.write(" " + p.comment())
+		opened = true
+	}
+
+	if !opened && len(b.Statements) == 0 && (p.next == len(p.comments) || !before(p.comments[p.next], b.End)) {
+		p.write("}")
+		return
+	}
+
+	p.indent++
+	p.statements(b.Statements, b.End)
+	p.indent--
+
+	p.newline()
+	p.write("}")
+}
+
+func statementStart(stmt ast.Statement) token.Token {
+	switch s := stmt.(type) {
+	case *ast.LetStatement:
+		return s.Token
+	case *ast.ReassignStatement:
+		return s.Name.Token
+	case *ast.ReturnStatement:
+		return s.Token
+	case *ast.ExpressionStatement:
+		return s.Token
+	case *ast.DeferStatement:
+		return s.Token
+	case *ast.BreakStatement:
+		return s.Token
+	case *ast.ContinueStatement:
+		return s.Token
+	case *ast.WhileStatement:
+		return s.Token
+	case *ast.ForInLoopStatement:
+		return s.Token
+	case *ast.ForLoopStatement:
+		return s.Token
+	case *ast.FunctionDeclaration:
+		return s.Token
+	}
+
+	return token.Token{}
+}
+
+// needsSemicolon reports whether next would continue the expression stmt
+// ends with if it only started on a new line, as in `f()` then `(a + b).g()`
+func needsSemicolon(stmt, next ast.Statement) bool {
+	switch stmt.(type) {
+	case *ast.LetStatement, *ast.ReassignStatement, *ast.ReturnStatement, *ast.ExpressionStatement, *ast.DeferStatement:
+	default:
+		return false
+	}
+
+	es, ok := next.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+
+	switch firstChar(es.Expression) {
+	case '(', '[', '-':
+		return true
+	}
+	return false
+}
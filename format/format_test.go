@@ -0,0 +1,158 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let   x=5;", "let x = 5\n"},
+		{"let add=fn(x,y){\nx+y\n}", "let add = fn(x, y) {\n\tx + y\n}\n"},
+		{"if(a>2){print( -1 )} else {\n      print(!true)\n}", "if (a > 2) {\n\tprint(-1)\n} else {\n\tprint(!true)\n}\n"},
+		{"let h = {\"a\" : 1,\"b\":[1,2 ,3]}", "let h = {\"a\": 1, \"b\": [1, 2, 3]}\n"},
+		{"for (let i=0;i<5;i++) {\n  print(h[\"a\"], arr[0] , \"s\"[0])\n}", "for (let i = 0; i < 5; i++) {\n\tprint(h[\"a\"], arr[0], \"s\"[0])\n}\n"},
+		{"f({\n\"x\": 1,\n\"y\": -2\n})", "f({\n\t\"x\": 1,\n\t\"y\": -2\n})\n"},
+		{"while (i < 10) {}\ni -= 1", "while (i < 10) {}\ni -= 1\n"},
+		{"arr.push( 2 ).len()", "arr.push(2).len()\n"},
+		{"\n\nlet a = 1\n\n\n\nlet b = 2\n\n", "let a = 1\n\nlet b = 2\n"},
+		{"let s = \"a  b\n  c\"", "let s = \"a  b\n  c\"\n"},
+		{"fn add(a:int,b : int)->int {a+b}", "fn add(a: int, b: int) -> int {\n\ta + b\n}\n"},
+		{"let h:{string:[int|null]}={}", "let h: {string: [int | null]} = {}\n"},
+		{"#!/usr/bin/env cixac\n\nprint( 1 )", "#!/usr/bin/env cixac\nprint(1)\n"},
+		{"match 5 {5=>1,_=>2}", "match 5 {\n\t5 => 1,\n\t_ => 2,\n}\n"},
+		{"match v {\n[a, ... rest]=>{\nrest\n}\n{\"k\":k} if k>1 => -k\n}", "match v {\n\t[a, ...rest] => {\n\t\trest\n\t}\n\t{\"k\": k} if k > 1 => -k,\n}\n"},
+		{"match v {[a, ..._] => a, [] => null}", "match v {\n\t[a, ...] => a,\n\t[] => null,\n}\n"},
+		{"let x = (1 + 2) * 3 - (4 - 5)", "let x = (1 + 2) * 3 - (4 - 5)\n"},
+		{"let y = ((a * b)) + c", "let y = a * b + c\n"},
+		{"print(2 ** (3 ** 2), (2 ** 3) ** 2)", "print(2 ** 3 ** 2, (2 ** 3) ** 2)\n"},
+		{"print(-(2 ** 2), (-2) ** 2, 2 ** -1)", "print(-2 ** 2, (-2) ** 2, 2 ** -1)\n"},
+		{"print(-(-x), !(x is int), (-a).abs(), a == (b is int))", "print(-(-x), !(x is int), (-a).abs(), a == (b is int))\n"},
+		{"let f: (fn(int) -> int) | null = null", "let f: (fn(int) -> int) | null = null\n"},
+		{"f(); (a + b).g()\nlet a = 1; -a; [1][0]", "f();\n(a + b).g()\nlet a = 1;\n-a;\n[1][0]\n"},
+		{"a; b", "a\nb\n"},
+		{"let add = fn(x, y) { x + y }(1, 2)", "let add = fn(x, y) {\n\tx + y\n}(1, 2)\n"},
+		{"while (true) {}", "while (true) {}\n"},
+	}
+
+	for _, tt := range tests {
+		out, err := Source([]byte(tt.input))
+		if err != nil {
+			t.Errorf("Source(%q) error: %s", tt.input, err)
+			continue
+		}
+
+		if string(out) != tt.expected {
+			t.Errorf("Source(%q) wrong.\nexpected=%q\ngot=%q", tt.input, tt.expected, out)
+		}
+	}
+}
+
+func TestSourceLayouts(t *testing.T) {
+	expected := `fn max(a, b) {
+	if (a > b) {
+		return a
+	} else if (a == b) {
+		return a
+	} else {
+		return b
+	}
+}
+
+for (let i = 0; i < 3; i++) {
+	print(max(i, 1))
+}
+`
+
+	layouts := []string{
+		"fn max(a,b)\n{\n  if (a > b)\n  {\n    return a\n  }\n  else if (a == b)\n  {\n    return a\n  }\n  else\n  {\n    return b\n  }\n}\n\nfor (let i = 0; i < 3; i++)\n{\n  print(max(i, 1))\n}",
+		"fn max(a, b) { if (a > b) { return a } else if (a == b) { return a } else { return b } }\n\nfor (let i = 0; i < 3; i++) { print(max(i, 1)) }",
+		"fn max(a, b) {\n\tif (a > b) { return a; }\n\telse if (a == b) { return a; }\n\telse { return b; }\n}\n\n\n\nfor (let i=0;i<3;i++) {\nprint(max(i,1));\n}\n",
+		"fn max(a, b)\n{ if ((a) > (b))\n{ return a } else\nif (a == b) { return (a) }\nelse\n{\nreturn b } }\n\nfor (let i = 0; i < 3; i++) { print(max(i, 1)); }",
+	}
+
+	for i, input := range layouts {
+		out, err := Source([]byte(input))
+		if err != nil {
+			t.Errorf("[layout: %d] Source error: %s", i, err)
+			continue
+		}
+
+		if string(out) != expected {
+			t.Errorf("[layout: %d] wrong.\nexpected=%q\ngot=%q", i, expected, out)
+		}
+	}
+}
+
+func TestSourceComments(t *testing.T) {
+	input := `   // header
+let add = fn(x, y) {
+  x + y   // sum
+}
+
+
+/* block
+   comment */
+let z = 3 /* inline */
+// trailing`
+
+	expected := `// header
+let add = fn(x, y) {
+	x + y // sum
+}
+
+/* block
+   comment */
+let z = 3 /* inline */
+// trailing
+`
+
+	out, err := Source([]byte(input))
+	if err != nil {
+		t.Fatalf("Source error: %s", err)
+	}
+
+	if string(out) != expected {
+		t.Fatalf("comments wrong.\nexpected=%q\ngot=%q", expected, out)
+	}
+}
+
+func TestSourceIdempotent(t *testing.T) {
+	input := `let fib = fn(n) { if (n < 2) { return n } fib(n-1)+fib(n - 2) }
+let data = {"list": [1, -2, [3, 4]], "nested": {"k": !false}}
+for (key, val in data) {
+        if (val == null) { continue }   // skip
+  print(key + ":" + val)
+}
+defer print("done")
+const pi = 3.14; let d = 10.50d
+`
+
+	once, err := Source([]byte(input))
+	if err != nil {
+		t.Fatalf("Source error: %s", err)
+	}
+
+	twice, err := Source(once)
+	if err != nil {
+		t.Fatalf("Source error on formatted output: %s", err)
+	}
+
+	if string(once) != string(twice) {
+		t.Fatalf("formatting not idempotent.\nfirst=%q\nsecond=%q", once, twice)
+	}
+}
+
+func TestSourceParseError(t *testing.T) {
+	_, err := Source([]byte("let x = ;\nlet y = 2"))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	if !strings.Contains(err.Error(), "1:9: error:") {
+		t.Errorf("error should carry the position. got=%q", err)
+	}
+}
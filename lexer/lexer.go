@@ -11,24 +11,54 @@ type Lexer struct {
 	position     int  // current position in input (points to current char)
 	readPosition int  // current reading position in input (after current char)
 	ch           byte // current char under examination
+	line         int  // line of the current char
+	column       int  // column of the current char
+
+	comments []token.Comment
+
+	unterminated bool // the input ended inside a string or block comment
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
+
+	// a #! line lets Unix run a script with cixac, it isn't part of the program
+	if l.ch == '#' && l.peekChar() == '!' {
+		for l.ch != '\n' && l.ch != 0 {
+			l.readChar()
+		}
+	}
+
 	return l
 }
 
-func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
+// Comments returns the comments read so far in source order
+func (l *Lexer) Comments() []token.Comment {
+	return l.comments
+}
 
+func (l *Lexer) NextToken() token.Token {
 	l.skipWhitespace()
 
+	line, column := l.line, l.column
+	tok := l.readToken()
+	tok.Line, tok.Column = line, column
+
+	return tok
+}
+
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
+
 	switch l.ch {
 	case '=':
-		if l.peekChar() == '=' {
+		switch l.peekChar() {
+		case '=':
 			tok = l.newTwoCharToken(token.EQ)
-		} else {
+		case '>':
+			tok = l.newTwoCharToken(token.FAT_ARROW)
+		default:
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '+':
@@ -46,6 +76,8 @@ func (l *Lexer) NextToken() token.Token {
 			tok = l.newTwoCharToken(token.DECR)
 		case '=':
 			tok = l.newTwoCharToken(token.SUB_ASSIGN)
+		case '>':
+			tok = l.newTwoCharToken(token.ARROW)
 		default:
 			tok = newToken(token.MINUS, l.ch)
 		}
@@ -61,17 +93,23 @@ func (l *Lexer) NextToken() token.Token {
 			tok = l.newTwoCharToken(token.COMMENT_END)
 		case '=':
 			tok = l.newTwoCharToken(token.MUL_ASSIGN)
+		case '*':
+			tok = l.newTwoCharToken(token.POW)
 		default:
 			tok = newToken(token.ASTERISK, l.ch)
 		}
 	case '/':
 		switch l.peekChar() {
 		case '/':
+			start, line, column := l.position, l.line, l.column
 			tok = l.newTwoCharToken(token.COMMENT)
 			l.skipComment()
+			l.addComment(start, line, column)
 		case '*':
+			start, line, column := l.position, l.line, l.column
 			tok = l.newTwoCharToken(token.COMMENT_START)
 			l.skipMultiComment()
+			l.addComment(start, line, column)
 			return tok
 		case '=':
 			tok = l.newTwoCharToken(token.DIV_ASSIGN)
@@ -90,7 +128,7 @@ func (l *Lexer) NextToken() token.Token {
 		if l.peekChar() == '|' {
 			tok = l.newTwoCharToken(token.OR)
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
+			tok = newToken(token.PIPE, l.ch)
 		}
 	case '<':
 		if l.peekChar() == '=' {
@@ -126,7 +164,11 @@ func (l *Lexer) NextToken() token.Token {
 	case ':':
 		tok = newToken(token.COLON, l.ch)
 	case '.':
-		if !isDigit(l.peekChar()) {
+		if strings.HasPrefix(l.input[l.position:], "...") {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else if !isDigit(l.peekChar()) {
 			tok = newToken(token.PERIOD, l.ch)
 		} else {
 			return l.readIdentOrNumber()
@@ -166,6 +208,13 @@ func (l *Lexer) peekChar() byte {
 }
 
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
@@ -181,7 +230,7 @@ func (l *Lexer) readIdentOrNumber() token.Token {
 	for isAlphaNum(l.ch) {
 		if isLetter(l.ch) && l.peekChar() == '.' {
 			l.readChar()
-			return token.Token{Literal: l.input[position:l.position], Type: token.LookupIdent(l.input[position:l.position])}
+			break
 		}
 		l.readChar()
 	}
@@ -190,6 +239,9 @@ func (l *Lexer) readIdentOrNumber() token.Token {
 
 	if isNumber(input) {
 		return l.readNumber(input)
+	} else if isDecimal(input) {
+		tok := l.readNumber(input[:len(input)-1])
+		return token.Token{Type: token.DECIMAL, Literal: tok.Literal + "d"}
 	} else {
 		return token.Token{Literal: input, Type: token.LookupIdent(input)}
 	}
@@ -204,6 +256,15 @@ func isNumber(input string) bool {
 	return true
 }
 
+// decimal literals are numbers with a d suffix, e.g. 12.50d
+func isDecimal(input string) bool {
+	if len(input) < 2 || input[len(input)-1] != 'd' || isLetter(input[0]) {
+		return false
+	}
+
+	return isNumber(input[:len(input)-1])
+}
+
 func (l *Lexer) readNumber(num string) token.Token {
 	var literal strings.Builder
 	var isFloat bool
@@ -242,7 +303,11 @@ func (l *Lexer) readString() string {
 	position := l.position + 1
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
+		if l.ch == '"' {
+			break
+		}
+		if l.ch == 0 {
+			l.unterminated = true
 			break
 		}
 	}
@@ -261,12 +326,34 @@ func (l *Lexer) skipComment() {
 	}
 }
 
+// addComment records the comment from start up to the current char, which
+// is the last char of a line comment or the * that starts */
+func (l *Lexer) addComment(start, line, column int) {
+	end := l.position + 1
+	if l.ch == '*' && l.peekChar() == '/' {
+		end++
+	}
+	end = min(end, len(l.input))
+
+	text := strings.TrimRight(l.input[start:end], "\r\n")
+	l.comments = append(l.comments, token.Comment{Text: text, Line: line, Column: column})
+}
+
 func (l *Lexer) skipMultiComment() {
 	l.readChar()
 	l.readChar()
-	for l.ch != '*' || l.peekChar() != '/' {
+	for (l.ch != '*' || l.peekChar() != '/') && l.ch != 0 {
 		l.readChar()
 	}
+	if l.ch == 0 {
+		l.unterminated = true
+	}
+}
+
+// Unterminated reports whether the input read so far ended inside a string
+// or a block comment
+func (l *Lexer) Unterminated() bool {
+	return l.unterminated
 }
 
 func isAlphaNum(ch byte) bool {
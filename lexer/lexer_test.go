@@ -69,6 +69,10 @@ func TestNextToken(t *testing.T) {
 
   for (key, val in iterable) { }
   map.add() 
+  2 ** 3
+  12.50d 10d
+  -> |
+  match x { [a, ...b] => a }
 `
 
 	tests := []struct {
@@ -264,6 +268,25 @@ func TestNextToken(t *testing.T) {
 		{token.IDENT, "add"},
 		{token.LPAREN, "("},
 		{token.RPAREN, ")"},
+		{token.INT, "2"},
+		{token.POW, "**"},
+		{token.INT, "3"},
+		{token.DECIMAL, "12.50d"},
+		{token.DECIMAL, "10d"},
+		{token.ARROW, "->"},
+		{token.PIPE, "|"},
+		{token.MATCH, "match"},
+		{token.IDENT, "x"},
+		{token.LBRACE, "{"},
+		{token.LBRACKET, "["},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "b"},
+		{token.RBRACKET, "]"},
+		{token.FAT_ARROW, "=>"},
+		{token.IDENT, "a"},
+		{token.RBRACE, "}"},
 		{token.EOF, ""},
 	}
 
@@ -283,3 +306,107 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := `let x = 5;
+fn add(a, b) {
+	return a.len() + "s";
+}
+/* open`
+
+	tests := []struct {
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"let", 1, 1},
+		{"x", 1, 5},
+		{"=", 1, 7},
+		{"5", 1, 9},
+		{";", 1, 10},
+		{"fn", 2, 1},
+		{"add", 2, 4},
+		{"(", 2, 7},
+		{"a", 2, 8},
+		{",", 2, 9},
+		{"b", 2, 11},
+		{")", 2, 12},
+		{"{", 2, 14},
+		{"return", 3, 2},
+		{"a", 3, 9},
+		{".", 3, 10},
+		{"len", 3, 11},
+		{"(", 3, 14},
+		{")", 3, 15},
+		{"+", 3, 17},
+		{"s", 3, 19},
+		{";", 3, 22},
+		{"}", 4, 1},
+		{"/*", 5, 1},
+		{"", 5, 8},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - position of %q wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLiteral, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+}
+
+func TestShebang(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+		expectedLine    int
+	}{
+		{"#!/usr/bin/env cixac\nlet x = 1", "let", 2},
+		{"#!/usr/bin/env cixac", "", 1},
+		{"let x = 1 #!", "let", 1},
+	}
+
+	for i, tt := range tests {
+		tok := New(tt.input).NextToken()
+
+		if tok.Literal != tt.expectedLiteral || tok.Line != tt.expectedLine {
+			t.Errorf("tests[%d] - first token wrong. expected=%q at line %d, got=%q at line %d",
+				i, tt.expectedLiteral, tt.expectedLine, tok.Literal, tok.Line)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `let x = 5 // five
+/* multi
+line */
+// last`
+
+	expected := []token.Comment{
+		{Text: "// five", Line: 1, Column: 11},
+		{Text: "/* multi\nline */", Line: 2, Column: 1},
+		{Text: "// last", Line: 4, Column: 1},
+	}
+
+	l := New(input)
+	for l.NextToken().Type != token.EOF {
+	}
+
+	comments := l.Comments()
+	if len(comments) != len(expected) {
+		t.Fatalf("wrong number of comments. expected=%d, got=%d", len(expected), len(comments))
+	}
+
+	for i, c := range comments {
+		if c != expected[i] {
+			t.Errorf("comments[%d] wrong. expected=%+v, got=%+v", i, expected[i], c)
+		}
+	}
+}
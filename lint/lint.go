@@ -0,0 +1,386 @@
+// Package lint reports suspicious code in Cixac programs.
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/parser"
+	"github.com/joshuahenriques/cixac/token"
+)
+
+const (
+	RuleUnused      = "unused"       // let and const bindings that are never read
+	RuleShadow      = "shadow"       // bindings that hide one of an enclosing function or loop
+	RuleUnreachable = "unreachable"  // statements after return, break or continue
+	RuleFuncCompare = "func-compare" // == and != on functions, which compare identity
+)
+
+var Rules = []string{RuleUnused, RuleShadow, RuleUnreachable, RuleFuncCompare}
+
+type Issue struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", i.Line, i.Column, i.Rule, i.Message)
+}
+
+// Config turns rules on and off, rules that aren't listed are on
+type Config struct {
+	Rules map[string]bool `json:"rules"`
+}
+
+// LoadConfig reads a JSON config such as {"rules": {"shadow": false}}
+func LoadConfig(path string) (Config, error) {
+	var config Config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("%s: %s", path, err)
+	}
+
+	for rule := range config.Rules {
+		if !isRule(rule) {
+			return config, fmt.Errorf("%s: unknown rule %q", path, rule)
+		}
+	}
+
+	return config, nil
+}
+
+func (c Config) Enabled(rule string) bool {
+	enabled, ok := c.Rules[rule]
+	return !ok || enabled
+}
+
+func isRule(name string) bool {
+	for _, rule := range Rules {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Source lints a program, returning the issues sorted by position. A
+// `// cixac:ignore rule, ...` comment silences the listed rules, or all of
+// them when none are listed, on its own line or, when the comment is alone on
+// its line, on the next one.
+func Source(src string, config Config) ([]Issue, error) {
+	l := lexer.New(src)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if diagnostics := p.Diagnostics(); len(diagnostics) != 0 {
+		msgs := make([]string, len(diagnostics))
+		for i, d := range diagnostics {
+			msgs[i] = d.String()
+		}
+		return nil, errors.New(strings.Join(msgs, "\n"))
+	}
+
+	c := &checker{}
+	c.open()
+	c.statements(program.Statements)
+	c.close()
+
+	ignored := ignores(src, l.Comments())
+
+	var issues []Issue
+	for _, issue := range c.issues {
+		if !config.Enabled(issue.Rule) || ignored[issue.Line][issue.Rule] || ignored[issue.Line][""] {
+			continue
+		}
+		issues = append(issues, issue)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Column < issues[j].Column
+	})
+
+	return issues, nil
+}
+
+// ignores maps lines to the rules silenced on them, "" standing for all rules
+func ignores(src string, comments []token.Comment) map[int]map[string]bool {
+	lines := strings.Split(src, "\n")
+	ignored := map[int]map[string]bool{}
+
+	for _, comment := range comments {
+		text, ok := strings.CutPrefix(comment.Text, "//")
+		if !ok {
+			continue
+		}
+		text, ok = strings.CutPrefix(strings.TrimSpace(text), "cixac:ignore")
+		if !ok {
+			continue
+		}
+
+		rules := map[string]bool{}
+		for _, rule := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			rules[rule] = true
+		}
+		if len(rules) == 0 {
+			rules[""] = true
+		}
+
+		ignored[comment.Line] = rules
+		if line := lines[comment.Line-1]; strings.TrimSpace(line[:comment.Column-1]) == "" {
+			ignored[comment.Line+1] = rules
+		}
+	}
+
+	return ignored
+}
+
+type binding struct {
+	name     *ast.Identifier
+	function bool // bound to a function literal
+	checked  bool // reported when unused
+	used     bool
+}
+
+// scope follows the evaluator, where only functions and for loops get their
+// own environment
+type scope struct {
+	bindings map[string]*binding
+	pending  []*ast.Identifier // references to names that may be declared later
+}
+
+type checker struct {
+	scopes []*scope
+	issues []Issue
+}
+
+func (c *checker) report(tok token.Token, rule, format string, a ...any) {
+	c.issues = append(c.issues, Issue{Rule: rule, Message: fmt.Sprintf(format, a...), Line: tok.Line, Column: tok.Column})
+}
+
+func (c *checker) open() {
+	c.scopes = append(c.scopes, &scope{bindings: map[string]*binding{}})
+}
+
+// close resolves references to names declared after them, as functions run
+// after the whole scope is declared, and reports unused bindings
+func (c *checker) close() {
+	s := c.scopes[len(c.scopes)-1]
+	c.scopes = c.scopes[:len(c.scopes)-1]
+
+	for _, ref := range s.pending {
+		if b, ok := s.bindings[ref.Value]; ok {
+			b.used = true
+		} else if len(c.scopes) != 0 {
+			parent := c.scopes[len(c.scopes)-1]
+			parent.pending = append(parent.pending, ref)
+		}
+	}
+
+	for _, b := range s.bindings {
+		if b.checked && !b.used && !strings.HasPrefix(b.name.Value, "_") {
+			c.report(b.name.Token, RuleUnused, "%s declared and not used", b.name.Value)
+		}
+	}
+}
+
+func (c *checker) lookup(name string) *binding {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if b, ok := c.scopes[i].bindings[name]; ok {
+			return b
+		}
+	}
+	return nil
+}
+
+func (c *checker) declare(name *ast.Identifier, function, checked bool) {
+	if name == nil {
+		return
+	}
+
+	for i := len(c.scopes) - 2; i >= 0; i-- {
+		if outer, ok := c.scopes[i].bindings[name.Value]; ok {
+			c.report(name.Token, RuleShadow, "declaration of %s shadows declaration at %d:%d",
+				name.Value, outer.name.Token.Line, outer.name.Token.Column)
+			break
+		}
+	}
+
+	c.scopes[len(c.scopes)-1].bindings[name.Value] = &binding{name: name, function: function, checked: checked}
+}
+
+func (c *checker) use(ident *ast.Identifier) {
+	if b := c.lookup(ident.Value); b != nil {
+		b.used = true
+		return
+	}
+
+	s := c.scopes[len(c.scopes)-1]
+	s.pending = append(s.pending, ident)
+}
+
+func (c *checker) statements(stmts []ast.Statement) {
+	reported := false
+
+	for i, stmt := range stmts {
+		if i > 0 && !reported && jumps(stmts[i-1]) {
+			c.report(ast.Start(stmt), RuleUnreachable, "unreachable code")
+			reported = true
+		}
+		c.node(stmt)
+	}
+}
+
+func jumps(stmt ast.Statement) bool {
+	switch stmt.(type) {
+	case *ast.ReturnStatement, *ast.BreakStatement, *ast.ContinueStatement:
+		return true
+	}
+	return false
+}
+
+func isNil(node ast.Node) bool {
+	v := reflect.ValueOf(node)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+func (c *checker) node(node ast.Node) {
+	if node == nil || isNil(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *ast.LetStatement:
+		c.node(n.Value)
+		_, function := n.Value.(*ast.FunctionLiteral)
+		c.declare(n.Name, function, true)
+
+	case *ast.FunctionDeclaration:
+		c.declare(n.Name, true, false)
+		c.node(n.Function)
+
+	case *ast.FunctionLiteral:
+		c.open()
+		for _, param := range n.Parameters {
+			c.scopes[len(c.scopes)-1].bindings[param.Value] = &binding{name: param}
+		}
+		if n.Body != nil {
+			c.statements(n.Body.Statements)
+		}
+		c.close()
+
+	case *ast.ForLoopStatement:
+		c.open()
+		if n.Initialization != nil {
+			c.node(n.Initialization.Value)
+			c.declare(n.Initialization.Name, false, false)
+		}
+		c.node(n.Condition)
+		c.node(n.Update)
+		c.node(n.Body)
+		c.close()
+
+	case *ast.ForInLoopStatement:
+		c.node(n.Iterable)
+		c.open()
+		c.declare(n.KeyIndex, false, false)
+		c.declare(n.ValueElement, false, false)
+		c.node(n.Body)
+		c.close()
+
+	case *ast.MatchExpression:
+		c.node(n.Value)
+		for _, arm := range n.Arms {
+			c.open()
+			ast.Inspect(arm.Pattern, func(node ast.Node) bool {
+				if binding, ok := node.(*ast.BindingPattern); ok {
+					c.declare(binding.Name, false, false)
+				}
+				return true
+			})
+			c.node(arm.Guard)
+			c.node(arm.Body)
+			c.close()
+		}
+
+	case *ast.BlockStatement:
+		c.statements(n.Statements)
+
+	case *ast.ReassignStatement:
+		// = only writes the name, the compound assignments read it too
+		if n.Token.Type != token.ASSIGN && n.Name != nil {
+			c.use(n.Name)
+		}
+		c.node(n.Value)
+
+	case *ast.Identifier:
+		c.use(n)
+
+	case *ast.InfixExpression:
+		if n.Operator == "==" || n.Operator == "!=" {
+			if name, ok := c.function(n.Left); ok {
+				c.report(n.Token, RuleFuncCompare, "comparing function %s with %s compares identity", name, n.Operator)
+			} else if name, ok := c.function(n.Right); ok {
+				c.report(n.Token, RuleFuncCompare, "comparing function %s with %s compares identity", name, n.Operator)
+			}
+		}
+		c.node(n.Left)
+		c.node(n.Right)
+
+	case *ast.BuiltinExpression:
+		// the method name isn't a reference
+		c.node(n.Left)
+		if n.Builtin != nil {
+			for _, arg := range n.Builtin.Arguments {
+				c.node(arg)
+			}
+		}
+
+	case *ast.PropertyExpression:
+		c.node(n.Left)
+
+	case *ast.HashLiteral:
+		for key, value := range n.Pairs {
+			c.node(key)
+			c.node(value)
+		}
+
+	default:
+		ast.Inspect(node, func(child ast.Node) bool {
+			if child == node {
+				return true
+			}
+			c.node(child)
+			return false
+		})
+	}
+}
+
+// function reports whether expr is known to be a function
+func (c *checker) function(expr ast.Expression) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.FunctionLiteral:
+		return "literal", true
+	case *ast.Identifier:
+		if b := c.lookup(e.Value); b != nil && b.function {
+			return e.Value, true
+		}
+	}
+	return "", false
+}
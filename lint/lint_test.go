@@ -0,0 +1,92 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 5", []string{"1:5: unused: x declared and not used"}},
+		{"let x = 5; print(x)", nil},
+		{"let _x = 5", nil},
+		{"let x = 5; x = 6", []string{"1:5: unused: x declared and not used"}},
+		{"let x = 5; x += 1", nil},
+		{"let f = fn() { g() }; let g = fn() { 1 }; f()", nil},
+		{"let arr = [1]; arr.push(2)", nil},
+		{"let x = 1; let f = fn() { let x = 2; x }; f(); x", []string{"1:31: shadow: declaration of x shadows declaration at 1:5"}},
+		{"let f = fn(x) { x }; let x = 1; f(x)", nil},
+		{"for (let i = 0; i < 2; i++) { for (let i = 0; i < 2; i++) { print(i) } }", []string{"1:40: shadow: declaration of i shadows declaration at 1:10"}},
+		{"for (let i = 0; i < 2; i++) { print(i) }\nfor (let i = 0; i < 2; i++) { print(i) }", nil},
+		{"let f = fn() {\n\treturn 1\n\tprint(2)\n\tprint(3)\n}\nf()", []string{"3:2: unreachable: unreachable code"}},
+		{"while (true) { break; print(1) }", []string{"1:23: unreachable: unreachable code"}},
+		{"fn a() { 1 }\nfn b() { 2 }\nif (a == b) { 1 }", []string{"3:7: func-compare: comparing function a with == compares identity"}},
+		{"let a = fn() { 1 }; a != null", []string{"1:23: func-compare: comparing function a with != compares identity"}},
+		{"let a = 1; let b = 2; a == b", nil},
+		{"let v = [1]; match v { [x, ...rest] => x + len(rest), _ => 0 }", nil},
+		{"let y = 1; match 2 { n if n > y => n, {\"k\": [k]} => k }", nil},
+		{"let x = 1; match 2 { x => x }; x", []string{"1:22: shadow: declaration of x shadows declaration at 1:5"}},
+		{"match 1 { 1 => { return 1; print(2) } }", []string{"1:28: unreachable: unreachable code"}},
+		{"let x = 5 // cixac:ignore unused", nil},
+		{"// cixac:ignore\nlet x = 5", nil},
+		{"// cixac:ignore shadow\nlet x = 5", []string{"2:5: unused: x declared and not used"}},
+		{"let x = 5 // cixac:ignore\nlet y = 5", []string{"2:5: unused: y declared and not used"}},
+	}
+
+	for _, tt := range tests {
+		issues, err := Source(tt.input, Config{})
+		if err != nil {
+			t.Errorf("Source(%q) error: %s", tt.input, err)
+			continue
+		}
+
+		if len(issues) != len(tt.expected) {
+			t.Errorf("Source(%q) wrong number of issues. expected=%q, got=%v", tt.input, tt.expected, issues)
+			continue
+		}
+
+		for i, issue := range issues {
+			if issue.String() != tt.expected[i] {
+				t.Errorf("Source(%q) issue %d wrong. expected=%q, got=%q", tt.input, i, tt.expected[i], issue)
+			}
+		}
+	}
+}
+
+func TestConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "lint.json")
+	os.WriteFile(path, []byte(`{"rules": {"unused": false}}`), 0644)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig error: %s", err)
+	}
+
+	issues, err := Source("let x = 5\nreturn 1\n2", config)
+	if err != nil {
+		t.Fatalf("Source error: %s", err)
+	}
+
+	if len(issues) != 1 || issues[0].Rule != RuleUnreachable {
+		t.Errorf("expected only the unreachable issue. got=%v", issues)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte(`{"rules": {"unknown": false}}`), 0644)
+
+	if _, err := LoadConfig(bad); err == nil || err.Error() != bad+`: unknown rule "unknown"` {
+		t.Errorf("expected an unknown rule error. got=%v", err)
+	}
+}
+
+func TestSourceParseError(t *testing.T) {
+	if _, err := Source("let = 5", Config{}); err == nil {
+		t.Errorf("expected a parse error")
+	}
+}
@@ -0,0 +1,635 @@
+package lsp
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/evaluator"
+	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/object"
+	"github.com/joshuahenriques/cixac/parser"
+	"github.com/joshuahenriques/cixac/token"
+)
+
+// pos is a 1-based line and byte column, the way tokens record positions
+type pos struct {
+	line, col int
+}
+
+func tokenPos(tok token.Token) pos { return pos{tok.Line, tok.Column} }
+
+func (p pos) before(other pos) bool {
+	return p.line < other.line || (p.line == other.line && p.col < other.col)
+}
+
+var endOfFile = pos{math.MaxInt, math.MaxInt}
+
+const (
+	symbolVariable = iota
+	symbolConstant
+	symbolFunction
+	symbolParameter
+)
+
+// symbol is a declared name, visible from its declaration to the end of the
+// block that declares it
+type symbol struct {
+	name   string
+	kind   int
+	at     pos
+	detail string
+	scope  pos // start of the declaring block, the program starts at 0:0
+	end    pos
+}
+
+type document struct {
+	uri         string
+	lines       []string
+	program     *ast.Program
+	diagnostics []Diagnostic
+	declared    []*symbol
+}
+
+func newDocument(uri, text string) *document {
+	d := &document{uri: uri, lines: strings.Split(text, "\n")}
+
+	p := parser.New(lexer.New(text))
+	d.program = p.ParseProgram()
+
+	d.diagnostics = []Diagnostic{}
+	for _, diag := range p.Diagnostics() {
+		d.diagnostics = append(d.diagnostics, Diagnostic{
+			Range:    d.wordRange(pos{diag.Line, diag.Column}),
+			Severity: severities[diag.Severity],
+			Source:   "cixac",
+			Message:  diag.Message,
+		})
+	}
+
+	d.collectSymbols()
+
+	return d
+}
+
+// severities are the protocol's severities of the parser's
+var severities = map[parser.Severity]int{
+	parser.SeverityError: SeverityError,
+}
+
+func (d *document) collectSymbols() {
+	var blocks []*ast.BlockStatement
+	loopInits := map[*ast.LetStatement]bool{}
+
+	ast.Inspect(d.program, func(node ast.Node) bool {
+		if block, ok := node.(*ast.BlockStatement); ok {
+			blocks = append(blocks, block)
+		}
+		return true
+	})
+
+	// the innermost block around a position, blocks are collected outside in
+	enclosing := func(at pos) (pos, pos) {
+		scope, end := pos{}, endOfFile
+		for _, block := range blocks {
+			start, stop := blockRange(block)
+			if start.before(at) && at.before(stop) && scope.before(start) {
+				scope, end = start, stop
+			}
+		}
+		return scope, end
+	}
+
+	declare := func(name *ast.Identifier, kind int, detail string, scope, end pos) {
+		if name == nil {
+			return
+		}
+		d.declared = append(d.declared, &symbol{
+			name:   name.Value,
+			kind:   kind,
+			at:     tokenPos(name.Token),
+			detail: detail,
+			scope:  scope,
+			end:    end,
+		})
+	}
+
+	ast.Inspect(d.program, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.LetStatement:
+			if loopInits[n] || n.Name == nil {
+				return true
+			}
+			scope, end := enclosing(tokenPos(n.Name.Token))
+			declare(n.Name, letKind(n), letDetail(n), scope, end)
+
+		case *ast.FunctionDeclaration:
+			if n.Name == nil {
+				return true
+			}
+			scope, end := enclosing(tokenPos(n.Name.Token))
+			declare(n.Name, symbolFunction, functionDetail(n.Name.Value, n.Function), scope, end)
+
+		case *ast.FunctionLiteral:
+			if n.Body == nil {
+				return true
+			}
+			start, stop := blockRange(n.Body)
+			for _, param := range n.Parameters {
+				declare(param, symbolParameter, "parameter "+param.Value, start, stop)
+			}
+
+		case *ast.ForInLoopStatement:
+			if n.Body == nil {
+				return true
+			}
+			start, stop := blockRange(n.Body)
+			declare(n.KeyIndex, symbolVariable, "let "+identName(n.KeyIndex), start, stop)
+			declare(n.ValueElement, symbolVariable, "let "+identName(n.ValueElement), start, stop)
+
+		case *ast.MatchExpression:
+			for _, arm := range n.Arms {
+				if arm.Body == nil {
+					continue
+				}
+				_, stop := blockRange(arm.Body)
+				ast.Inspect(arm.Pattern, func(node ast.Node) bool {
+					if binding, ok := node.(*ast.BindingPattern); ok {
+						declare(binding.Name, symbolVariable, "let "+binding.Name.Value, tokenPos(binding.Token), stop)
+					}
+					return true
+				})
+			}
+
+		case *ast.ForLoopStatement:
+			if n.Body == nil || n.Initialization == nil || n.Initialization.Name == nil {
+				return true
+			}
+			loopInits[n.Initialization] = true
+			start, stop := blockRange(n.Body)
+			declare(n.Initialization.Name, symbolVariable, letDetail(n.Initialization), start, stop)
+		}
+
+		return true
+	})
+}
+
+// blockRange is where a block starts and ends. A block left open runs to the
+// end of the file, the body of a match arm written without braces ends at
+// its last token.
+func blockRange(block *ast.BlockStatement) (pos, pos) {
+	end := tokenPos(block.End)
+	if block.End.Type != token.RBRACE && block.Token.Type == token.LBRACE {
+		end = endOfFile
+	}
+
+	return tokenPos(block.Token), end
+}
+
+func identName(ident *ast.Identifier) string {
+	if ident == nil {
+		return ""
+	}
+	return ident.Value
+}
+
+func letKind(stmt *ast.LetStatement) int {
+	if stmt.Name.Const {
+		return symbolConstant
+	}
+	return symbolVariable
+}
+
+func letDetail(stmt *ast.LetStatement) string {
+	keyword := "let"
+	if stmt.Name.Const {
+		keyword = "const"
+	}
+
+	if fn, ok := stmt.Value.(*ast.FunctionLiteral); ok && fn != nil {
+		return keyword + " " + functionDetail(stmt.Name.Value, fn)
+	}
+
+	value := ""
+	if stmt.Value != nil && !isNilNode(stmt.Value) {
+		value = stmt.Value.String()
+	}
+	if len(value) > 80 {
+		value = value[:77] + "..."
+	}
+
+	name := stmt.Name.Value
+	if stmt.Type != nil {
+		name += ": " + stmt.Type.String()
+	}
+
+	return keyword + " " + name + " = " + value
+}
+
+func functionDetail(name string, fn *ast.FunctionLiteral) string {
+	if fn == nil {
+		return "fn " + name + "()"
+	}
+
+	params := make([]string, len(fn.Parameters))
+	for i, param := range fn.Parameters {
+		params[i] = param.Value
+		if i < len(fn.ParameterTypes) && fn.ParameterTypes[i] != nil {
+			params[i] += ": " + fn.ParameterTypes[i].String()
+		}
+	}
+
+	detail := "fn " + name + "(" + strings.Join(params, ", ") + ")"
+	if fn.ReturnType != nil {
+		detail += " -> " + fn.ReturnType.String()
+	}
+
+	return detail
+}
+
+// isNilNode reports nodes that hold a nil pointer, which the parser returns
+// for expressions it could not parse
+func isNilNode(node ast.Node) bool {
+	found := false
+	ast.Inspect(node, func(ast.Node) bool {
+		found = true
+		return false
+	})
+	return !found
+}
+
+// resolve finds the declaration of name that is visible at a position,
+// preferring the innermost scope and then the latest declaration
+func (d *document) resolve(name string, at pos) *symbol {
+	var best *symbol
+	for _, sym := range d.declared {
+		if sym.name != name || at.before(sym.at) || sym.end.before(at) {
+			continue
+		}
+		if best == nil || best.scope.before(sym.scope) || (best.scope == sym.scope && best.at.before(sym.at)) {
+			best = sym
+		}
+	}
+
+	return best
+}
+
+func (d *document) visible(at pos) []*symbol {
+	byName := map[string]*symbol{}
+	for _, sym := range d.declared {
+		if _, ok := byName[sym.name]; !ok {
+			if found := d.resolve(sym.name, at); found != nil {
+				byName[sym.name] = found
+			}
+		}
+	}
+
+	syms := make([]*symbol, 0, len(byName))
+	for _, sym := range byName {
+		syms = append(syms, sym)
+	}
+
+	return syms
+}
+
+func isIdentChar(ch byte) bool {
+	return ch == '_' || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9')
+}
+
+// word is the identifier around a position and, when it follows a dot,
+// the identifier before the dot
+type word struct {
+	text      string
+	start     pos
+	end       pos
+	afterDot  bool
+	qualifier string
+}
+
+// wordAt returns the identifier touching the position. With prefixOnly it
+// stops at the position, which is what completion needs
+func (d *document) wordAt(at pos, prefixOnly bool) word {
+	if at.line < 1 || at.line > len(d.lines) {
+		return word{start: at, end: at}
+	}
+
+	line := d.lines[at.line-1]
+	col := min(max(at.col-1, 0), len(line))
+
+	start := col
+	for start > 0 && isIdentChar(line[start-1]) {
+		start--
+	}
+	end := col
+	if !prefixOnly {
+		for end < len(line) && isIdentChar(line[end]) {
+			end++
+		}
+	}
+
+	w := word{text: line[start:end], start: pos{at.line, start + 1}, end: pos{at.line, end + 1}}
+
+	dot := start - 1
+	for dot >= 0 && (line[dot] == ' ' || line[dot] == '\t') {
+		dot--
+	}
+	if dot >= 0 && line[dot] == '.' {
+		w.afterDot = true
+		qualEnd := dot
+		for qualEnd > 0 && (line[qualEnd-1] == ' ' || line[qualEnd-1] == '\t') {
+			qualEnd--
+		}
+		qualStart := qualEnd
+		for qualStart > 0 && isIdentChar(line[qualStart-1]) {
+			qualStart--
+		}
+		w.qualifier = line[qualStart:qualEnd]
+	}
+
+	return w
+}
+
+func (d *document) completions(position Position) []CompletionItem {
+	at := d.fromPosition(position)
+	w := d.wordAt(at, true)
+
+	items := []CompletionItem{}
+
+	if w.afterDot {
+		if module, ok := evaluator.LookupModule(w.qualifier); ok && d.resolve(w.qualifier, at) == nil {
+			for name, member := range module.Members {
+				kind := CompletionConstant
+				if _, ok := member.(*object.Builtin); ok {
+					kind = CompletionFunction
+				}
+				items = append(items, CompletionItem{Label: name, Kind: kind, Detail: module.Name + "." + name})
+			}
+		} else {
+			for name, types := range methodOwners() {
+				items = append(items, CompletionItem{Label: name, Kind: CompletionMethod, Detail: strings.Join(types, ", ")})
+			}
+		}
+	} else {
+		for _, sym := range d.visible(at) {
+			items = append(items, CompletionItem{Label: sym.name, Kind: completionKind(sym.kind), Detail: sym.detail})
+		}
+		for _, name := range evaluator.BuiltinNames() {
+			items = append(items, CompletionItem{Label: name, Kind: CompletionFunction, Detail: "builtin"})
+		}
+		for _, name := range evaluator.ModuleNames() {
+			items = append(items, CompletionItem{Label: name, Kind: CompletionModule, Detail: "module"})
+		}
+		for _, name := range token.Keywords() {
+			items = append(items, CompletionItem{Label: name, Kind: CompletionKeyword})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+
+	return items
+}
+
+func completionKind(kind int) int {
+	switch kind {
+	case symbolFunction:
+		return CompletionFunction
+	case symbolConstant:
+		return CompletionConstant
+	default:
+		return CompletionVariable
+	}
+}
+
+// methodOwners maps the methods of arrays, hashes and strings to the types
+// that have them
+func methodOwners() map[string][]string {
+	owners := map[string][]string{}
+	for _, builtins := range []struct {
+		typ     string
+		methods map[string]object.Builtin
+	}{
+		{object.ARRAY_OBJ, object.ArrayBuiltins},
+		{object.HASH_OBJ, object.HashBuiltins},
+		{object.STRING_OBJ, object.StringBuiltins},
+	} {
+		for name := range builtins.methods {
+			owners[name] = append(owners[name], builtins.typ)
+		}
+	}
+
+	return owners
+}
+
+func (d *document) hover(position Position) *Hover {
+	at := d.fromPosition(position)
+	w := d.wordAt(at, false)
+	if w.text == "" {
+		return nil
+	}
+
+	var text string
+
+	switch {
+	case w.afterDot:
+		if module, ok := evaluator.LookupModule(w.qualifier); ok && d.resolve(w.qualifier, at) == nil {
+			member, ok := module.Members[w.text]
+			if !ok {
+				return nil
+			}
+			if _, ok := member.(*object.Builtin); ok {
+				text = "builtin " + module.Name + "." + w.text
+			} else {
+				text = module.Name + "." + w.text + " = " + member.Inspect()
+			}
+		} else if types, ok := methodOwners()[w.text]; ok {
+			text = "method " + w.text + " of " + strings.Join(types, ", ")
+		} else {
+			return nil
+		}
+
+	default:
+		if sym := d.resolve(w.text, at); sym != nil {
+			text = sym.detail
+		} else if slices.Contains(evaluator.BuiltinNames(), w.text) {
+			text = "builtin " + w.text
+		} else if _, ok := evaluator.LookupModule(w.text); ok {
+			text = "module " + w.text
+		} else {
+			return nil
+		}
+	}
+
+	r := d.toRange(w.start, w.end)
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: "```cixac\n" + text + "\n```"},
+		Range:    &r,
+	}
+}
+
+func (d *document) definition(position Position) *Location {
+	at := d.fromPosition(position)
+	w := d.wordAt(at, false)
+	if w.text == "" || w.afterDot {
+		return nil
+	}
+
+	sym := d.resolve(w.text, at)
+	if sym == nil {
+		return nil
+	}
+
+	return &Location{URI: d.uri, Range: d.toRange(sym.at, pos{sym.at.line, sym.at.col + len(sym.name)})}
+}
+
+func (d *document) symbols() []DocumentSymbol {
+	return d.documentSymbols(d.program.Statements)
+}
+
+func (d *document) documentSymbols(stmts []ast.Statement) []DocumentSymbol {
+	syms := []DocumentSymbol{}
+
+	for _, stmt := range stmts {
+		if stmt == nil || isNilNode(stmt) {
+			continue
+		}
+
+		switch n := stmt.(type) {
+		case *ast.LetStatement:
+			if n.Name == nil {
+				continue
+			}
+			kind := SymbolVariable
+			if n.Name.Const {
+				kind = SymbolConstant
+			}
+			name := d.nameRange(n.Name)
+			sym := DocumentSymbol{
+				Name:           n.Name.Value,
+				Detail:         letDetail(n),
+				Kind:           kind,
+				Range:          Range{Start: d.toPosition(tokenPos(n.Token)), End: name.End},
+				SelectionRange: name,
+			}
+			if fn, ok := n.Value.(*ast.FunctionLiteral); ok && fn != nil && fn.Body != nil {
+				sym.Kind = SymbolFunction
+				sym.Range.End = d.blockEnd(fn.Body)
+				sym.Children = d.documentSymbols(fn.Body.Statements)
+			}
+			syms = append(syms, sym)
+
+		case *ast.FunctionDeclaration:
+			if n.Name == nil || n.Function == nil || n.Function.Body == nil {
+				continue
+			}
+			syms = append(syms, DocumentSymbol{
+				Name:           n.Name.Value,
+				Detail:         functionDetail(n.Name.Value, n.Function),
+				Kind:           SymbolFunction,
+				Range:          Range{Start: d.toPosition(tokenPos(n.Token)), End: d.blockEnd(n.Function.Body)},
+				SelectionRange: d.nameRange(n.Name),
+				Children:       d.documentSymbols(n.Function.Body.Statements),
+			})
+
+		default:
+			// declarations inside loops and conditionals belong to the
+			// enclosing function
+			ast.Inspect(stmt, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.BlockStatement:
+					syms = append(syms, d.documentSymbols(node.Statements)...)
+					return false
+				case *ast.ForLoopStatement:
+					if node.Initialization != nil {
+						syms = append(syms, d.documentSymbols([]ast.Statement{node.Initialization})...)
+					}
+					if node.Body != nil {
+						syms = append(syms, d.documentSymbols(node.Body.Statements)...)
+					}
+					return false
+				case *ast.FunctionLiteral:
+					return false
+				}
+				return true
+			})
+		}
+	}
+
+	return syms
+}
+
+func (d *document) nameRange(name *ast.Identifier) Range {
+	at := tokenPos(name.Token)
+	return d.toRange(at, pos{at.line, at.col + len(name.Value)})
+}
+
+func (d *document) blockEnd(block *ast.BlockStatement) Position {
+	_, end := blockRange(block)
+	if end == endOfFile {
+		return d.toPosition(pos{len(d.lines), len(d.lines[len(d.lines)-1]) + 1})
+	}
+	return d.toPosition(pos{end.line, end.col + 1})
+}
+
+// wordRange covers the token starting at a position, or a single character
+func (d *document) wordRange(at pos) Range {
+	end := pos{at.line, at.col + 1}
+	if at.line >= 1 && at.line <= len(d.lines) {
+		line := d.lines[at.line-1]
+		col := at.col - 1
+		for col < len(line) && isIdentChar(line[col]) {
+			col++
+		}
+		if col+1 > end.col {
+			end.col = col + 1
+		}
+	}
+
+	return d.toRange(at, end)
+}
+
+func (d *document) toRange(start, end pos) Range {
+	return Range{Start: d.toPosition(start), End: d.toPosition(end)}
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// toPosition converts a byte column into UTF-16 code units
+func (d *document) toPosition(at pos) Position {
+	if at.line < 1 || at.line > len(d.lines) {
+		return Position{Line: max(at.line-1, 0)}
+	}
+
+	line := d.lines[at.line-1]
+	col := min(max(at.col-1, 0), len(line))
+
+	units := 0
+	for _, r := range line[:col] {
+		units += utf16Len(r)
+	}
+
+	return Position{Line: at.line - 1, Character: units}
+}
+
+func (d *document) fromPosition(position Position) pos {
+	if position.Line < 0 || position.Line >= len(d.lines) {
+		return pos{position.Line + 1, position.Character + 1}
+	}
+
+	line := d.lines[position.Line]
+	units, col := 0, 0
+	for col < len(line) && units < position.Character {
+		r, size := utf8.DecodeRuneInString(line[col:])
+		units += utf16Len(r)
+		col += size
+	}
+
+	return pos{position.Line + 1, col + 1}
+}
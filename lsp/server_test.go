@@ -0,0 +1,421 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testDoc = `let total = 10
+const limit = 5
+fn add(a, b) {
+	let total = a + b
+	return total
+}
+let nums = [1, 2]
+print(total + limit)
+nums.`
+
+type session struct {
+	input bytes.Buffer
+	id    int
+}
+
+func (s *session) send(method string, params any) int {
+	s.id++
+	s.write(map[string]any{"jsonrpc": "2.0", "id": s.id, "method": method, "params": params})
+	return s.id
+}
+
+func (s *session) notify(method string, params any) {
+	s.write(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (s *session) write(msg any) {
+	data, _ := json.Marshal(msg)
+	fmt.Fprintf(&s.input, "Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+type received struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+// run plays the session against a server and returns what it wrote
+func (s *session) run(t *testing.T) ([]received, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	err := NewServer(&s.input, &out).Run()
+
+	var msgs []received
+	reader := bufio.NewReader(&out)
+	for {
+		srv := &Server{in: reader}
+		data, readErr := srv.readMessage()
+		if readErr != nil {
+			break
+		}
+
+		var msg received
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("server wrote invalid JSON: %s", data)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, err
+}
+
+func result[T any](t *testing.T, msgs []received, id int) T {
+	t.Helper()
+
+	var val T
+	for _, msg := range msgs {
+		if msg.ID != nil && *msg.ID == id {
+			if msg.Error != nil {
+				t.Fatalf("request %d failed: %s", id, msg.Error.Message)
+			}
+			if err := json.Unmarshal(msg.Result, &val); err != nil {
+				t.Fatalf("could not decode result of request %d: %s", id, msg.Result)
+			}
+			return val
+		}
+	}
+
+	t.Fatalf("no response to request %d", id)
+	return val
+}
+
+func openSession(text string) *session {
+	s := &session{}
+	s.send("initialize", map[string]any{"capabilities": map[string]any{}})
+	s.notify("initialized", map[string]any{})
+	s.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": "file:///test.cx", "languageId": "cixac", "version": 1, "text": text},
+	})
+	return s
+}
+
+func (s *session) close() {
+	s.send("shutdown", nil)
+	s.notify("exit", nil)
+}
+
+func at(line, character int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": "file:///test.cx"},
+		"position":     map[string]any{"line": line, "character": character},
+	}
+}
+
+func TestInitializeAndShutdown(t *testing.T) {
+	s := &session{}
+	initID := s.send("initialize", map[string]any{})
+	unknownID := s.send("workspace/unknown", map[string]any{})
+	shutdownID := s.send("shutdown", nil)
+	s.notify("exit", nil)
+
+	msgs, err := s.run(t)
+	if err != nil {
+		t.Fatalf("Run returned error: %s", err)
+	}
+
+	init := result[map[string]map[string]any](t, msgs, initID)
+	for _, capability := range []string{"completionProvider", "hoverProvider", "definitionProvider", "documentSymbolProvider"} {
+		if _, ok := init["capabilities"][capability]; !ok {
+			t.Errorf("capability %s not announced", capability)
+		}
+	}
+
+	for _, msg := range msgs {
+		if msg.ID != nil && *msg.ID == unknownID {
+			if msg.Error == nil || msg.Error.Code != codeMethodNotFound {
+				t.Errorf("unknown method not rejected. got=%+v", msg.Error)
+			}
+		}
+		if msg.ID != nil && *msg.ID == shutdownID && string(msg.Result) != "null" {
+			t.Errorf("shutdown result wrong. got=%s", msg.Result)
+		}
+	}
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	s := &session{}
+	s.send("initialize", map[string]any{})
+	s.notify("exit", nil)
+
+	if _, err := s.run(t); err != ErrNoShutdown {
+		t.Errorf("wrong error. expected=%v, got=%v", ErrNoShutdown, err)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	s := openSession(testDoc)
+	s.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": "file:///test.cx", "version": 2},
+		"contentChanges": []map[string]any{{"text": "let x = 1\nlet y 2"}},
+	})
+	s.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": "file:///test.cx", "version": 3},
+		"contentChanges": []map[string]any{{"text": "let x = 1"}},
+	})
+	s.close()
+
+	msgs, err := s.run(t)
+	if err != nil {
+		t.Fatalf("Run returned error: %s", err)
+	}
+
+	var published []PublishDiagnosticsParams
+	for _, msg := range msgs {
+		if msg.Method == "textDocument/publishDiagnostics" {
+			var params PublishDiagnosticsParams
+			json.Unmarshal(msg.Params, &params)
+			published = append(published, params)
+		}
+	}
+
+	if len(published) != 3 {
+		t.Fatalf("wrong number of diagnostics notifications. got=%d", len(published))
+	}
+
+	expected := []Diagnostic{
+		{Range: Range{Start: Position{8, 5}, End: Position{8, 5}}, Severity: SeverityError, Source: "cixac", Message: "expected next token to be IDENT, got EOF instead"},
+	}
+	if got := published[0].Diagnostics; fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("wrong diagnostics after open.\nexpected=%+v\ngot=%+v", expected, got)
+	}
+
+	second := published[1].Diagnostics
+	if len(second) == 0 || second[0].Range.Start != (Position{1, 6}) || !strings.Contains(second[0].Message, "expected next token to be =") {
+		t.Errorf("wrong diagnostics after change. got=%+v", second)
+	}
+
+	if len(published[2].Diagnostics) != 0 {
+		t.Errorf("diagnostics not cleared. got=%+v", published[2].Diagnostics)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	s := openSession(testDoc)
+	methodsID := s.send("textDocument/completion", at(8, 5))
+	scopeID := s.send("textDocument/completion", at(4, 8))
+	moduleID := s.send("textDocument/completion", map[string]any{})
+	s.close()
+
+	msgs, err := s.run(t)
+	if err != nil {
+		t.Fatalf("Run returned error: %s", err)
+	}
+
+	labels := func(list CompletionList) map[string]CompletionItem {
+		items := map[string]CompletionItem{}
+		for _, item := range list.Items {
+			items[item.Label] = item
+		}
+		return items
+	}
+
+	methods := labels(result[CompletionList](t, msgs, methodsID))
+	for _, name := range []string{"push", "keys", "split", "contains"} {
+		if methods[name].Kind != CompletionMethod {
+			t.Errorf("method %s not completed after '.'", name)
+		}
+	}
+	if methods["contains"].Detail != "ARRAY, HASH" {
+		t.Errorf("wrong detail for contains. got=%q", methods["contains"].Detail)
+	}
+	if _, ok := methods["total"]; ok {
+		t.Errorf("variables completed after '.'")
+	}
+
+	scope := labels(result[CompletionList](t, msgs, scopeID))
+	for name, detail := range map[string]string{
+		"a":     "parameter a",
+		"b":     "parameter b",
+		"total": "let total = (a + b)",
+		"limit": "const limit = 5",
+		"add":   "fn add(a, b)",
+		"len":   "builtin",
+		"math":  "module",
+	} {
+		if scope[name].Detail != detail {
+			t.Errorf("wrong completion for %s. expected=%q, got=%q", name, detail, scope[name].Detail)
+		}
+	}
+	if _, ok := scope["nums"]; ok {
+		t.Errorf("nums is completed before it is declared")
+	}
+	if scope["return"].Kind != CompletionKeyword {
+		t.Errorf("keywords not completed")
+	}
+
+	for _, msg := range msgs {
+		if msg.ID != nil && *msg.ID == moduleID && (msg.Error == nil || msg.Error.Code != codeInvalidParams) {
+			t.Errorf("completion for an unknown document not rejected")
+		}
+	}
+}
+
+func TestModuleMemberCompletion(t *testing.T) {
+	s := openSession("let x = math.")
+	id := s.send("textDocument/completion", at(0, 13))
+	s.close()
+
+	msgs, _ := s.run(t)
+
+	items := map[string]int{}
+	for _, item := range result[CompletionList](t, msgs, id).Items {
+		items[item.Label] = item.Kind
+	}
+
+	if items["sqrt"] != CompletionFunction || items["pi"] != CompletionConstant {
+		t.Errorf("math members not completed. got=%v", items)
+	}
+	if _, ok := items["push"]; ok {
+		t.Errorf("array methods completed for a module")
+	}
+}
+
+func TestHover(t *testing.T) {
+	s := openSession(testDoc)
+
+	tests := []struct {
+		id       int
+		expected string
+	}{
+		{s.send("textDocument/hover", at(1, 8)), "const limit = 5"},
+		{s.send("textDocument/hover", at(4, 10)), "let total = (a + b)"},
+		{s.send("textDocument/hover", at(7, 9)), "let total = 10"},
+		{s.send("textDocument/hover", at(7, 2)), "builtin print"},
+		{s.send("textDocument/hover", at(2, 7)), "parameter a"},
+		{s.send("textDocument/hover", at(2, 3)), "fn add(a, b)"},
+		{s.send("textDocument/hover", at(6, 12)), ""},
+	}
+	s.close()
+
+	msgs, _ := s.run(t)
+
+	for i, tt := range tests {
+		hover := result[*Hover](t, msgs, tt.id)
+
+		if tt.expected == "" {
+			if hover != nil {
+				t.Errorf("[test: %d] expected no hover. got=%+v", i, hover)
+			}
+			continue
+		}
+
+		if hover == nil || hover.Contents.Value != "```cixac\n"+tt.expected+"\n```" {
+			t.Errorf("[test: %d] wrong hover. expected=%q, got=%+v", i, tt.expected, hover)
+		}
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	s := openSession(testDoc)
+
+	tests := []struct {
+		id       int
+		expected *Range
+	}{
+		{s.send("textDocument/definition", at(4, 9)), &Range{Position{3, 5}, Position{3, 10}}},
+		{s.send("textDocument/definition", at(7, 7)), &Range{Position{0, 4}, Position{0, 9}}},
+		{s.send("textDocument/definition", at(7, 16)), &Range{Position{1, 6}, Position{1, 11}}},
+		{s.send("textDocument/definition", at(3, 13)), &Range{Position{2, 7}, Position{2, 8}}},
+		{s.send("textDocument/definition", at(8, 1)), &Range{Position{6, 4}, Position{6, 8}}},
+		{s.send("textDocument/definition", at(7, 2)), nil},
+	}
+	s.close()
+
+	msgs, _ := s.run(t)
+
+	for i, tt := range tests {
+		loc := result[*Location](t, msgs, tt.id)
+
+		if tt.expected == nil {
+			if loc != nil {
+				t.Errorf("[test: %d] expected no definition. got=%+v", i, loc)
+			}
+			continue
+		}
+
+		if loc == nil || loc.URI != "file:///test.cx" || loc.Range != *tt.expected {
+			t.Errorf("[test: %d] wrong definition. expected=%+v, got=%+v", i, tt.expected, loc)
+		}
+	}
+}
+
+func TestMatchBindings(t *testing.T) {
+	s := openSession(`let x = 1
+let r = match [2, 3] {
+	[x, ...rest] => x + len(rest),
+	n => n + x
+}
+print(x)`)
+
+	tests := []struct {
+		id       int
+		expected *Range
+	}{
+		{s.send("textDocument/definition", at(2, 17)), &Range{Position{2, 2}, Position{2, 3}}},
+		{s.send("textDocument/definition", at(2, 25)), &Range{Position{2, 8}, Position{2, 12}}},
+		{s.send("textDocument/definition", at(3, 6)), &Range{Position{3, 1}, Position{3, 2}}},
+		{s.send("textDocument/definition", at(3, 10)), &Range{Position{0, 4}, Position{0, 5}}},
+		{s.send("textDocument/definition", at(5, 6)), &Range{Position{0, 4}, Position{0, 5}}},
+	}
+	hover := s.send("textDocument/hover", at(2, 2))
+	s.close()
+
+	msgs, _ := s.run(t)
+
+	for i, tt := range tests {
+		loc := result[*Location](t, msgs, tt.id)
+		if loc == nil || loc.Range != *tt.expected {
+			t.Errorf("[test: %d] wrong definition. expected=%+v, got=%+v", i, tt.expected, loc)
+		}
+	}
+
+	if h := result[*Hover](t, msgs, hover); h == nil || h.Contents.Value != "```cixac\nlet x\n```" {
+		t.Errorf("wrong hover for a binding. got=%+v", h)
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	s := openSession(testDoc + "\nfor (let i = 0; i < 2; i++) { let inLoop = i }")
+	id := s.send("textDocument/documentSymbol", map[string]any{"textDocument": map[string]any{"uri": "file:///test.cx"}})
+	s.close()
+
+	msgs, _ := s.run(t)
+	syms := result[[]DocumentSymbol](t, msgs, id)
+
+	var describe func(syms []DocumentSymbol) string
+	describe = func(syms []DocumentSymbol) string {
+		parts := []string{}
+		for _, sym := range syms {
+			part := fmt.Sprintf("%s:%d", sym.Name, sym.Kind)
+			if len(sym.Children) > 0 {
+				part += "[" + describe(sym.Children) + "]"
+			}
+			parts = append(parts, part)
+		}
+		return strings.Join(parts, " ")
+	}
+
+	expected := "total:13 limit:14 add:12[total:13] nums:13 i:13 inLoop:13"
+	if got := describe(syms); got != expected {
+		t.Errorf("wrong symbols.\nexpected=%q\ngot=%q", expected, got)
+	}
+
+	add := syms[2]
+	if add.Range.Start != (Position{2, 0}) || add.Range.End != (Position{5, 1}) || add.SelectionRange.Start != (Position{2, 3}) {
+		t.Errorf("wrong range for add. got=%+v", add)
+	}
+}
@@ -0,0 +1,347 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"strings"
+)
+
+type RoundingMode string
+
+const (
+	ROUND_HALF_EVEN RoundingMode = "half_even"
+	ROUND_HALF_UP   RoundingMode = "half_up"
+	ROUND_HALF_DOWN RoundingMode = "half_down"
+	ROUND_UP        RoundingMode = "up"
+	ROUND_DOWN      RoundingMode = "down"
+	ROUND_CEILING   RoundingMode = "ceiling"
+	ROUND_FLOOR     RoundingMode = "floor"
+)
+
+// DecimalDivisionScale is the minimum number of fractional digits kept when
+// dividing decimals with the / operator
+const DecimalDivisionScale = 16
+
+var roundingModes = map[RoundingMode]bool{
+	ROUND_HALF_EVEN: true,
+	ROUND_HALF_UP:   true,
+	ROUND_HALF_DOWN: true,
+	ROUND_UP:        true,
+	ROUND_DOWN:      true,
+	ROUND_CEILING:   true,
+	ROUND_FLOOR:     true,
+}
+
+// Decimal is an exact base 10 number, Value * 10^-Scale
+type Decimal struct {
+	Value *big.Int
+	Scale int32
+}
+
+func (d *Decimal) Type() ObjectType { return DECIMAL_OBJ }
+func (d *Decimal) Inspect() string {
+	digits := new(big.Int).Abs(d.Value).String()
+
+	var out strings.Builder
+	if d.Value.Sign() < 0 {
+		out.WriteString("-")
+	}
+
+	if d.Scale <= 0 {
+		out.WriteString(digits)
+		out.WriteString(strings.Repeat("0", int(-d.Scale)))
+		return out.String()
+	}
+
+	if len(digits) <= int(d.Scale) {
+		digits = strings.Repeat("0", int(d.Scale)-len(digits)+1) + digits
+	}
+
+	point := len(digits) - int(d.Scale)
+	out.WriteString(digits[:point])
+	out.WriteString(".")
+	out.WriteString(digits[point:])
+
+	return out.String()
+}
+
+// Decimals that are numerically equal, like 1.5 and 1.50, share a hash key
+func (d *Decimal) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(d.normalize().Inspect()))
+
+	return HashKey{Type: d.Type(), Value: h.Sum64()}
+}
+
+func (d *Decimal) Methods(name string) (Object, bool) {
+	builtin, ok := DecimalBuiltins[name]
+	if !ok {
+		return nil, false
+	}
+
+	return &builtin, true
+}
+
+func ParseDecimal(str string) (*Decimal, bool) {
+	str = strings.TrimSuffix(strings.TrimSpace(str), "d")
+
+	intPart, fracPart, _ := strings.Cut(str, ".")
+	if strings.ContainsAny(fracPart, "+-") || intPart+fracPart == "" {
+		return nil, false
+	}
+
+	value, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return nil, false
+	}
+
+	return &Decimal{Value: value, Scale: int32(len(fracPart))}, true
+}
+
+func NewDecimal(value *big.Int) *Decimal {
+	return &Decimal{Value: new(big.Int).Set(value)}
+}
+
+func (d *Decimal) normalize() *Decimal {
+	value := new(big.Int).Set(d.Value)
+	scale := d.Scale
+
+	ten := big.NewInt(10)
+	rem := new(big.Int)
+	for scale > 0 {
+		quo, r := new(big.Int).QuoRem(value, ten, rem)
+		if r.Sign() != 0 {
+			break
+		}
+		value = quo
+		scale--
+	}
+
+	return &Decimal{Value: value, Scale: scale}
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// rescale returns the unscaled value of d at a larger or equal scale
+func (d *Decimal) rescale(scale int32) *big.Int {
+	return new(big.Int).Mul(d.Value, pow10(scale-d.Scale))
+}
+
+func align(a, b *Decimal) (*big.Int, *big.Int, int32) {
+	scale := max(a.Scale, b.Scale)
+	return a.rescale(scale), b.rescale(scale), scale
+}
+
+func (d *Decimal) Add(other *Decimal) *Decimal {
+	a, b, scale := align(d, other)
+	return &Decimal{Value: a.Add(a, b), Scale: scale}
+}
+
+func (d *Decimal) Sub(other *Decimal) *Decimal {
+	a, b, scale := align(d, other)
+	return &Decimal{Value: a.Sub(a, b), Scale: scale}
+}
+
+func (d *Decimal) Mul(other *Decimal) *Decimal {
+	return &Decimal{Value: new(big.Int).Mul(d.Value, other.Value), Scale: d.Scale + other.Scale}
+}
+
+func (d *Decimal) Neg() *Decimal {
+	return &Decimal{Value: new(big.Int).Neg(d.Value), Scale: d.Scale}
+}
+
+func (d *Decimal) Cmp(other *Decimal) int {
+	a, b, _ := align(d, other)
+	return a.Cmp(b)
+}
+
+// Mod takes the sign of the divisor, like the % operator on integers
+func (d *Decimal) Mod(other *Decimal) (*Decimal, error) {
+	a, b, scale := align(d, other)
+	if b.Sign() == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+
+	rem := new(big.Int).Rem(a, b)
+	if rem.Sign() != 0 && rem.Sign() != b.Sign() {
+		rem.Add(rem, b)
+	}
+
+	return &Decimal{Value: rem, Scale: scale}, nil
+}
+
+func (d *Decimal) Quo(other *Decimal, scale int32, mode RoundingMode) (*Decimal, error) {
+	if other.Value.Sign() == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+
+	num := new(big.Int).Set(d.Value)
+	den := new(big.Int).Set(other.Value)
+
+	if exp := scale + other.Scale - d.Scale; exp >= 0 {
+		num.Mul(num, pow10(exp))
+	} else {
+		den.Mul(den, pow10(-exp))
+	}
+
+	value, err := roundQuo(num, den, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Decimal{Value: value, Scale: scale}, nil
+}
+
+func (d *Decimal) Round(scale int32, mode RoundingMode) (*Decimal, error) {
+	if scale >= d.Scale {
+		return &Decimal{Value: d.rescale(scale), Scale: scale}, nil
+	}
+
+	value, err := roundQuo(d.Value, pow10(d.Scale-scale), mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Decimal{Value: value, Scale: scale}, nil
+}
+
+func roundQuo(num, den *big.Int, mode RoundingMode) (*big.Int, error) {
+	if !roundingModes[mode] {
+		return nil, fmt.Errorf("unknown rounding mode %q", mode)
+	}
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() == 0 {
+		return quo, nil
+	}
+
+	negative := (num.Sign() < 0) != (den.Sign() < 0)
+
+	// compare the remainder against half of the divisor
+	half := new(big.Int).Abs(rem)
+	half.Mul(half, big.NewInt(2))
+	cmpHalf := half.Cmp(new(big.Int).Abs(den))
+
+	var awayFromZero bool
+	switch mode {
+	case ROUND_UP:
+		awayFromZero = true
+	case ROUND_DOWN:
+		awayFromZero = false
+	case ROUND_CEILING:
+		awayFromZero = !negative
+	case ROUND_FLOOR:
+		awayFromZero = negative
+	case ROUND_HALF_UP:
+		awayFromZero = cmpHalf >= 0
+	case ROUND_HALF_DOWN:
+		awayFromZero = cmpHalf > 0
+	case ROUND_HALF_EVEN:
+		awayFromZero = cmpHalf > 0 || (cmpHalf == 0 && quo.Bit(0) == 1)
+	}
+
+	if awayFromZero {
+		if negative {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+
+	return quo, nil
+}
+
+var DecimalBuiltins = map[string]Builtin{
+	"round": {
+		Fn: func(args ...Object) Object {
+			if len(args) < 2 || len(args) > 3 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args)-1)
+			}
+
+			dec, scale, mode, err := decimalArgs("round", args[0], args[1], args[2:])
+			if err != nil {
+				return err
+			}
+
+			rounded, roundErr := dec.Round(scale, mode)
+			if roundErr != nil {
+				return newError("%s", roundErr)
+			}
+
+			return rounded
+		},
+	},
+	"div": {
+		Fn: func(args ...Object) Object {
+			if len(args) < 3 || len(args) > 4 {
+				return newError("wrong number of arguments. got=%d, want=2 or 3", len(args)-1)
+			}
+
+			dec, scale, mode, err := decimalArgs("div", args[0], args[2], args[3:])
+			if err != nil {
+				return err
+			}
+
+			var divisor *Decimal
+			switch arg := args[1].(type) {
+			case *Decimal:
+				divisor = arg
+			case *Integer:
+				divisor = NewDecimal(big.NewInt(arg.Value))
+			case *BigInteger:
+				divisor = NewDecimal(arg.Value)
+			default:
+				return newError("divisor to `div` must be DECIMAL or INTEGER, got %s", args[1].Type())
+			}
+
+			quo, quoErr := dec.Quo(divisor, scale, mode)
+			if quoErr != nil {
+				return newError("%s", quoErr)
+			}
+
+			return quo
+		},
+	},
+	"scale": {
+		Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args)-1)
+			}
+			if args[0].Type() != DECIMAL_OBJ {
+				return newError("argument to `scale` must be DECIMAL, got %s", args[0].Type())
+			}
+
+			return &Integer{Value: int64(args[0].(*Decimal).Scale)}
+		},
+	},
+}
+
+func decimalArgs(name string, receiver, scale Object, mode []Object) (*Decimal, int32, RoundingMode, *Error) {
+	dec, ok := receiver.(*Decimal)
+	if !ok {
+		return nil, 0, "", newError("argument to `%s` must be DECIMAL, got %s", name, receiver.Type())
+	}
+
+	scaleInt, ok := scale.(*Integer)
+	if !ok || scaleInt.Value < 0 {
+		return nil, 0, "", newError("scale for `%s` must be a non-negative INTEGER", name)
+	}
+	if scaleInt.Value > math.MaxInt32 {
+		return nil, 0, "", newError("scale for `%s` must be at most %d, got %d", name, math.MaxInt32, scaleInt.Value)
+	}
+
+	roundingMode := ROUND_HALF_EVEN
+	if len(mode) == 1 {
+		str, ok := mode[0].(*String)
+		if !ok {
+			return nil, 0, "", newError("rounding mode for `%s` must be STRING, got %s", name, mode[0].Type())
+		}
+		roundingMode = RoundingMode(str.Value)
+	}
+
+	return dec, int32(scaleInt.Value), roundingMode, nil
+}
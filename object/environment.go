@@ -1,5 +1,7 @@
 package object
 
+import "sort"
+
 type Environment struct {
 	store map[string]ObjectMeta
 	outer *Environment
@@ -52,3 +54,18 @@ func (e *Environment) ExistsOutsideScope(name string) bool {
 	_, ok := e.outer.store[name]
 	return ok
 }
+
+// Names returns the names set in this scope, sorted
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (e *Environment) Outer() *Environment {
+	return e.outer
+}
@@ -0,0 +1,112 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Propertied objects expose named fields through property access, e.g.
+// resp.status
+type Propertied interface {
+	Property(name string) (Object, bool)
+}
+
+type Response struct {
+	Status  int64
+	Headers *Hash
+	Body    string
+}
+
+func (r *Response) Type() ObjectType { return RESPONSE_OBJ }
+func (r *Response) Inspect() string  { return fmt.Sprintf("response %d", r.Status) }
+
+func (r *Response) Property(name string) (Object, bool) {
+	switch name {
+	case "status":
+		return &Integer{Value: r.Status}, true
+	case "headers":
+		return r.Headers, true
+	case "body":
+		return &String{Value: r.Body}, true
+	default:
+		return nil, false
+	}
+}
+
+func (r *Response) Methods(name string) (Object, bool) {
+	builtin, ok := ResponseBuiltins[name]
+	if !ok {
+		return nil, false
+	}
+
+	return &builtin, true
+}
+
+// Request is what http.serve handlers receive
+type Request struct {
+	Method  string
+	Path    string
+	Headers *Hash
+	Query   *Hash
+	Params  *Hash
+	Body    string
+}
+
+func (r *Request) Type() ObjectType { return REQUEST_OBJ }
+func (r *Request) Inspect() string  { return fmt.Sprintf("request %s %s", r.Method, r.Path) }
+
+func (r *Request) Property(name string) (Object, bool) {
+	switch name {
+	case "method":
+		return &String{Value: r.Method}, true
+	case "path":
+		return &String{Value: r.Path}, true
+	case "headers":
+		return r.Headers, true
+	case "query":
+		return r.Query, true
+	case "params":
+		return r.Params, true
+	case "body":
+		return &String{Value: r.Body}, true
+	default:
+		return nil, false
+	}
+}
+
+func (r *Request) Methods(name string) (Object, bool) {
+	builtin, ok := RequestBuiltins[name]
+	if !ok {
+		return nil, false
+	}
+
+	return &builtin, true
+}
+
+func jsonBody(kind ObjectType, body func(obj Object) string) Builtin {
+	return Builtin{
+		Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args)-1)
+			}
+			if args[0].Type() != kind {
+				return newError("argument to `json` must be %s, got %s", kind, args[0].Type())
+			}
+
+			val, err := ParseJSON([]byte(body(args[0])))
+			if err != nil {
+				return newError("invalid JSON in %s body: %s", strings.ToLower(string(kind)), err)
+			}
+
+			return val
+		},
+	}
+}
+
+var ResponseBuiltins = map[string]Builtin{
+	"json": jsonBody(RESPONSE_OBJ, func(obj Object) string { return obj.(*Response).Body }),
+}
+
+var RequestBuiltins = map[string]Builtin{
+	"json": jsonBody(REQUEST_OBJ, func(obj Object) string { return obj.(*Request).Body }),
+}
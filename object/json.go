@@ -0,0 +1,118 @@
+package object
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// ToJSON encodes strings, numbers, booleans, null, arrays and hashes. Hash
+// keys that are not strings use their printed form
+func ToJSON(obj Object) ([]byte, error) {
+	val, err := toJSONValue(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(val)
+}
+
+func toJSONValue(obj Object) (any, error) {
+	switch obj := obj.(type) {
+	case *String:
+		return obj.Value, nil
+	case *Integer:
+		return obj.Value, nil
+	case *BigInteger:
+		return json.Number(obj.Value.String()), nil
+	case *Float:
+		return obj.Value, nil
+	case *Decimal:
+		return json.Number(obj.Inspect()), nil
+	case *Boolean:
+		return obj.Value, nil
+	case *Null:
+		return nil, nil
+	case *Array:
+		arr := make([]any, len(obj.Elements))
+		for i, ele := range obj.Elements {
+			val, err := toJSONValue(ele)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = val
+		}
+		return arr, nil
+	case *Hash:
+		m := make(map[string]any, len(obj.Pairs))
+		for _, pair := range obj.Pairs {
+			key := pair.Key.Inspect()
+			if str, ok := pair.Key.(*String); ok {
+				key = str.Value
+			}
+
+			val, err := toJSONValue(pair.Value)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = val
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("can't encode %s as JSON", obj.Type())
+	}
+}
+
+// ParseJSON decodes a JSON document. Whole numbers become integers, growing
+// to big integers when they don't fit, and other numbers become floats
+func ParseJSON(data []byte) (Object, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var val any
+	if err := dec.Decode(&val); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+
+	return fromJSONValue(val), nil
+}
+
+func fromJSONValue(val any) Object {
+	switch val := val.(type) {
+	case string:
+		return &String{Value: val}
+	case json.Number:
+		if i, err := strconv.ParseInt(string(val), 10, 64); err == nil {
+			return &Integer{Value: i}
+		}
+		if b, ok := new(big.Int).SetString(string(val), 10); ok {
+			return &BigInteger{Value: b}
+		}
+		f, _ := val.Float64()
+		return &Float{Value: f}
+	case bool:
+		if val {
+			return TRUE
+		}
+		return FALSE
+	case []any:
+		arr := &Array{Elements: make([]Object, len(val))}
+		for i, ele := range val {
+			arr.Elements[i] = fromJSONValue(ele)
+		}
+		return arr
+	case map[string]any:
+		pairs := make(map[string]Object, len(val))
+		for key, ele := range val {
+			pairs[key] = fromJSONValue(ele)
+		}
+		return NewHash(pairs)
+	default:
+		return NULL
+	}
+}
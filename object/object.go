@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math/big"
 	"strings"
 
 	"github.com/joshuahenriques/cixac/ast"
@@ -13,7 +14,9 @@ type ObjectType string
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	BIGINT_OBJ       = "BIGINT"
 	FLOAT_OBJ        = "FLOAT"
+	DECIMAL_OBJ      = "DECIMAL"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	EMPTY_OBJ        = "EMPTY_OBJ"
@@ -26,6 +29,14 @@ const (
 	HASH_OBJ         = "HASH"
 	BREAK_OBJ        = "BREAK"
 	CONTINUE_OBJ     = "CONTINUE"
+	DEFER_STACK_OBJ  = "DEFER_STACK"
+	MODULE_OBJ       = "MODULE"
+	ITERATOR_OBJ     = "ITERATOR"
+	TIME_OBJ         = "TIME"
+	DURATION_OBJ     = "DURATION"
+	REGEX_OBJ        = "REGEX"
+	RESPONSE_OBJ     = "RESPONSE"
+	REQUEST_OBJ      = "REQUEST"
 )
 
 var (
@@ -63,6 +74,19 @@ func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (bi *BigInteger) Inspect() string  { return bi.Value.String() }
+func (bi *BigInteger) Type() ObjectType { return BIGINT_OBJ }
+func (bi *BigInteger) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(bi.Value.String()))
+
+	return HashKey{Type: bi.Type(), Value: h.Sum64()}
+}
+
 type Float struct {
 	Value float64
 }
@@ -119,6 +143,7 @@ type Function struct {
 	Parameters []*ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
+	Name       string // the name it was declared with, if any
 }
 
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
@@ -235,6 +260,39 @@ func (ho *Hash) Methods(name string) (Object, bool) {
 	return &builtin, true
 }
 
+func NewHash(pairs map[string]Object) *Hash {
+	hash := &Hash{Pairs: make(map[HashKey]HashPair, len(pairs))}
+
+	for key, value := range pairs {
+		str := &String{Value: key}
+		hash.Pairs[str.HashKey()] = HashPair{Key: str, Value: value}
+	}
+
+	return hash
+}
+
+type Module struct {
+	Name    string
+	Members map[string]Object
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return "module " + m.Name }
+
+func (m *Module) Methods(name string) (Object, bool) {
+	member, ok := m.Members[name]
+	return member, ok
+}
+
+// Iterator produces values on demand for `for in` loops. Next returns false
+// once the iterator is exhausted.
+type Iterator struct {
+	Next func() (Object, bool)
+}
+
+func (it *Iterator) Type() ObjectType { return ITERATOR_OBJ }
+func (it *Iterator) Inspect() string  { return "iterator" }
+
 type Break struct{}
 
 func (b *Break) Type() ObjectType { return BREAK_OBJ }
@@ -245,6 +303,19 @@ type Continue struct{}
 func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
 func (c *Continue) Inspect() string  { return "" }
 
+type DeferredCall struct {
+	Fn   Object
+	Args []Object
+}
+
+// DeferStack holds the calls deferred by a single function invocation.
+type DeferStack struct {
+	Calls []DeferredCall
+}
+
+func (ds *DeferStack) Type() ObjectType { return DEFER_STACK_OBJ }
+func (ds *DeferStack) Inspect() string  { return "" }
+
 func newError(format string, a ...interface{}) *Error {
 	return &Error{Message: fmt.Sprintf(format, a...)}
 }
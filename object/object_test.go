@@ -77,3 +77,17 @@ func TestFloatHashKey(t *testing.T) {
 		t.Errorf("floats with different content have same hash keys")
 	}
 }
+
+func TestDecimalHashKey(t *testing.T) {
+	a, _ := ParseDecimal("1.5")
+	b, _ := ParseDecimal("1.50")
+	c, _ := ParseDecimal("-1.5")
+
+	if a.HashKey() != b.HashKey() {
+		t.Errorf("equal decimals with different scales have different hash keys")
+	}
+
+	if a.HashKey() == c.HashKey() {
+		t.Errorf("decimals with different values have same hash keys")
+	}
+}
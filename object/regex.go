@@ -0,0 +1,224 @@
+package object
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ApplyFunction is set by the evaluator so builtins such as Regex.replace
+// can call back into Cixac functions
+var ApplyFunction func(fn Object, args []Object) Object
+
+type Regex struct {
+	Value *regexp.Regexp
+}
+
+func (r *Regex) Type() ObjectType { return REGEX_OBJ }
+func (r *Regex) Inspect() string  { return fmt.Sprintf("re(%q)", r.Value.String()) }
+
+func (r *Regex) Methods(name string) (Object, bool) {
+	builtin, ok := RegexBuiltins[name]
+	if !ok {
+		return nil, false
+	}
+
+	return &builtin, true
+}
+
+// regexArgs checks for a regex receiver followed by a string and up to
+// extra optional arguments
+func regexArgs(name string, args []Object, extra int) (*regexp.Regexp, string, *Error) {
+	if len(args) < 2 || len(args) > 2+extra {
+		return nil, "", newError("wrong number of arguments. got=%d, want=%d", len(args)-1, 1+extra)
+	}
+	if args[0].Type() != REGEX_OBJ {
+		return nil, "", newError("argument to `%s` must be REGEX, got %s", name, args[0].Type())
+	}
+	if args[1].Type() != STRING_OBJ {
+		return nil, "", newError("argument to `%s` must be STRING, got %s", name, args[1].Type())
+	}
+
+	return args[0].(*Regex).Value, args[1].(*String).Value, nil
+}
+
+func stringArray(strs []string) *Array {
+	arr := &Array{Elements: make([]Object, 0, len(strs))}
+	for _, str := range strs {
+		arr.Elements = append(arr.Elements, &String{Value: str})
+	}
+
+	return arr
+}
+
+// regexGroups maps group numbers, and the names of named groups, to the
+// captured text. Groups that did not take part in the match are NULL
+func regexGroups(re *regexp.Regexp, str string, loc []int) *Hash {
+	hash := NewHash(nil)
+	names := re.SubexpNames()
+
+	for i := 0; i < len(loc)/2; i++ {
+		var val Object = NULL
+		if loc[2*i] >= 0 {
+			val = &String{Value: str[loc[2*i]:loc[2*i+1]]}
+		}
+
+		num := &Integer{Value: int64(i)}
+		hash.Pairs[num.HashKey()] = HashPair{Key: num, Value: val}
+		if names[i] != "" {
+			name := &String{Value: names[i]}
+			hash.Pairs[name.HashKey()] = HashPair{Key: name, Value: val}
+		}
+	}
+
+	return hash
+}
+
+// regexReplace substitutes every match with either a template where $1 and
+// ${name} expand to groups, or the result of calling fn(match, groups)
+func regexReplace(re *regexp.Regexp, str string, repl Object) Object {
+	switch repl := repl.(type) {
+	case *String:
+		return &String{Value: re.ReplaceAllString(str, repl.Value)}
+
+	case *Function, *Builtin:
+		var out strings.Builder
+		last := 0
+
+		for _, loc := range re.FindAllStringSubmatchIndex(str, -1) {
+			args := []Object{&String{Value: str[loc[0]:loc[1]]}}
+			if fn, ok := repl.(*Function); ok && len(fn.Parameters) > 1 {
+				args = append(args, regexGroups(re, str, loc))
+			}
+
+			val := ApplyFunction(repl, args)
+			if val != nil && val.Type() == ERROR_OBJ {
+				return val
+			}
+
+			out.WriteString(str[last:loc[0]])
+			if s, ok := val.(*String); ok {
+				out.WriteString(s.Value)
+			} else if val != nil {
+				out.WriteString(val.Inspect())
+			}
+			last = loc[1]
+		}
+		out.WriteString(str[last:])
+
+		return &String{Value: out.String()}
+
+	default:
+		return newError("replacement must be STRING or FUNCTION, got %s", repl.Type())
+	}
+}
+
+var RegexBuiltins = map[string]Builtin{
+	"match": {
+		Fn: func(args ...Object) Object {
+			re, str, err := regexArgs("match", args, 0)
+			if err != nil {
+				return err
+			}
+
+			if !re.MatchString(str) {
+				return FALSE
+			}
+
+			return TRUE
+		},
+	},
+	"find": {
+		Fn: func(args ...Object) Object {
+			re, str, err := regexArgs("find", args, 0)
+			if err != nil {
+				return err
+			}
+
+			loc := re.FindStringIndex(str)
+			if loc == nil {
+				return NULL
+			}
+
+			return &String{Value: str[loc[0]:loc[1]]}
+		},
+	},
+	"findAll": {
+		Fn: func(args ...Object) Object {
+			re, str, err := regexArgs("findAll", args, 1)
+			if err != nil {
+				return err
+			}
+
+			n := -1
+			if len(args) == 3 {
+				limit, ok := args[2].(*Integer)
+				if !ok {
+					return newError("limit passed to `findAll` must be INTEGER, got %s", args[2].Type())
+				}
+				n = int(limit.Value)
+			}
+
+			return stringArray(re.FindAllString(str, n))
+		},
+	},
+	"groups": {
+		Fn: func(args ...Object) Object {
+			re, str, err := regexArgs("groups", args, 0)
+			if err != nil {
+				return err
+			}
+
+			loc := re.FindStringSubmatchIndex(str)
+			if loc == nil {
+				return NULL
+			}
+
+			return regexGroups(re, str, loc)
+		},
+	},
+	"replace": {
+		Fn: func(args ...Object) Object {
+			re, str, err := regexArgs("replace", args, 1)
+			if err != nil {
+				return err
+			}
+			if len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args)-1)
+			}
+
+			return regexReplace(re, str, args[2])
+		},
+	},
+	"split": {
+		Fn: func(args ...Object) Object {
+			re, str, err := regexArgs("split", args, 1)
+			if err != nil {
+				return err
+			}
+
+			n := -1
+			if len(args) == 3 {
+				limit, ok := args[2].(*Integer)
+				if !ok {
+					return newError("limit passed to `split` must be INTEGER, got %s", args[2].Type())
+				}
+				n = int(limit.Value)
+			}
+
+			return stringArray(re.Split(str, n))
+		},
+	},
+	"pattern": {
+		Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args)-1)
+			}
+			if args[0].Type() != REGEX_OBJ {
+				return newError("argument to `pattern` must be REGEX, got %s", args[0].Type())
+			}
+
+			return &String{Value: args[0].(*Regex).Value.String()}
+		},
+	},
+}
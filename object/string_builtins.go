@@ -98,4 +98,48 @@ var StringBuiltins = map[string]Builtin{
 			return splitArr
 		},
 	},
+	"matches": {
+		Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args)-1)
+			}
+			if args[0].Type() != STRING_OBJ {
+				return newError("argument to `matches` must be STRING, got %s", args[0].Type())
+			}
+			if args[1].Type() != REGEX_OBJ {
+				return newError("argument to `matches` must be REGEX, got %s", args[1].Type())
+			}
+
+			if !args[1].(*Regex).Value.MatchString(args[0].(*String).Value) {
+				return FALSE
+			}
+
+			return TRUE
+		},
+	},
+	"replace": {
+		Fn: func(args ...Object) Object {
+			if len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args)-1)
+			}
+			if args[0].Type() != STRING_OBJ {
+				return newError("argument to `replace` must be STRING, got %s", args[0].Type())
+			}
+
+			str := args[0].(*String)
+
+			switch pattern := args[1].(type) {
+			case *Regex:
+				return regexReplace(pattern.Value, str.Value, args[2])
+			case *String:
+				repl, ok := args[2].(*String)
+				if !ok {
+					return newError("replacement for a STRING pattern must be STRING, got %s", args[2].Type())
+				}
+				return &String{Value: strings.ReplaceAll(str.Value, pattern.Value, repl.Value)}
+			default:
+				return newError("pattern passed to `replace` must be STRING or REGEX, got %s", args[1].Type())
+			}
+		},
+	},
 }
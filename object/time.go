@@ -0,0 +1,92 @@
+package object
+
+import (
+	"time"
+)
+
+type Time struct {
+	Value time.Time
+}
+
+func (t *Time) Type() ObjectType { return TIME_OBJ }
+func (t *Time) Inspect() string  { return t.Value.Format(time.RFC3339Nano) }
+func (t *Time) HashKey() HashKey {
+	return HashKey{Type: t.Type(), Value: uint64(t.Value.UnixNano())}
+}
+
+func (t *Time) Methods(name string) (Object, bool) {
+	builtin, ok := TimeBuiltins[name]
+	if !ok {
+		return nil, false
+	}
+
+	return &builtin, true
+}
+
+type Duration struct {
+	Value time.Duration
+}
+
+func (d *Duration) Type() ObjectType { return DURATION_OBJ }
+func (d *Duration) Inspect() string  { return d.Value.String() }
+func (d *Duration) HashKey() HashKey {
+	return HashKey{Type: d.Type(), Value: uint64(d.Value)}
+}
+
+func (d *Duration) Methods(name string) (Object, bool) {
+	builtin, ok := DurationBuiltins[name]
+	if !ok {
+		return nil, false
+	}
+
+	return &builtin, true
+}
+
+func timeAccessor(name string, fn func(t time.Time) Object) Builtin {
+	return Builtin{
+		Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args)-1)
+			}
+			if args[0].Type() != TIME_OBJ {
+				return newError("argument to `%s` must be TIME, got %s", name, args[0].Type())
+			}
+
+			return fn(args[0].(*Time).Value)
+		},
+	}
+}
+
+func durationAccessor(name string, fn func(d time.Duration) Object) Builtin {
+	return Builtin{
+		Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args)-1)
+			}
+			if args[0].Type() != DURATION_OBJ {
+				return newError("argument to `%s` must be DURATION, got %s", name, args[0].Type())
+			}
+
+			return fn(args[0].(*Duration).Value)
+		},
+	}
+}
+
+var TimeBuiltins = map[string]Builtin{
+	"year":    timeAccessor("year", func(t time.Time) Object { return &Integer{Value: int64(t.Year())} }),
+	"month":   timeAccessor("month", func(t time.Time) Object { return &Integer{Value: int64(t.Month())} }),
+	"day":     timeAccessor("day", func(t time.Time) Object { return &Integer{Value: int64(t.Day())} }),
+	"hour":    timeAccessor("hour", func(t time.Time) Object { return &Integer{Value: int64(t.Hour())} }),
+	"minute":  timeAccessor("minute", func(t time.Time) Object { return &Integer{Value: int64(t.Minute())} }),
+	"second":  timeAccessor("second", func(t time.Time) Object { return &Integer{Value: int64(t.Second())} }),
+	"weekday": timeAccessor("weekday", func(t time.Time) Object { return &String{Value: t.Weekday().String()} }),
+	"zone":    timeAccessor("zone", func(t time.Time) Object { return &String{Value: t.Location().String()} }),
+	"unix":    timeAccessor("unix", func(t time.Time) Object { return &Integer{Value: t.Unix()} }),
+}
+
+var DurationBuiltins = map[string]Builtin{
+	"hours":   durationAccessor("hours", func(d time.Duration) Object { return &Float{Value: d.Hours()} }),
+	"minutes": durationAccessor("minutes", func(d time.Duration) Object { return &Float{Value: d.Minutes()} }),
+	"seconds": durationAccessor("seconds", func(d time.Duration) Object { return &Float{Value: d.Seconds()} }),
+	"ms":      durationAccessor("ms", func(d time.Duration) Object { return &Integer{Value: d.Milliseconds()} }),
+}
@@ -1,8 +1,12 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
+	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/joshuahenriques/cixac/ast"
 	"github.com/joshuahenriques/cixac/lexer"
@@ -17,6 +21,7 @@ const (
 	SUM         // + -
 	PRODUCT     // * /
 	PREFIX      // -X or !X
+	POWER       // X ** Y
 	POSTFIX     // i++ or i--
 	CALL        // myFunction(X)
 	INDEX       // array[index]
@@ -25,6 +30,7 @@ const (
 var precedences = map[token.TokenType]int{
 	token.EQ:       EQUALS,
 	token.NOT_EQ:   EQUALS,
+	token.IS:       EQUALS,
 	token.AND:      EQUALS,
 	token.OR:       EQUALS,
 	token.LT:       LESSGREATER,
@@ -36,6 +42,7 @@ var precedences = map[token.TokenType]int{
 	token.SLASH:    PRODUCT,
 	token.MOD:      PRODUCT,
 	token.ASTERISK: PRODUCT,
+	token.POW:      POWER,
 	token.PERIOD:   CALL,
 	token.LPAREN:   CALL,
 	token.LBRACKET: INDEX,
@@ -47,9 +54,45 @@ type (
 	postfixParseFn func(ast.Expression) ast.Expression
 )
 
+// Severity is how serious a diagnostic is. The parser only reports errors
+// for now, consumers should still look at it rather than assume one.
+type Severity int
+
+const (
+	SeverityError Severity = iota + 1
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Diagnostic is a problem found at the position of the offending token
+type Diagnostic struct {
+	Severity Severity
+	Message  string
+	Line     int
+	Column   int
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", d.Line, d.Column, d.Severity, d.Message)
+}
+
 type Parser struct {
-	l      *lexer.Lexer
-	errors []string
+	l           *lexer.Lexer
+	diagnostics []Diagnostic
+
+	// set after an error until the parser has skipped to the start of the
+	// next statement, errors in between are usually caused by the first one
+	panicking bool
+	depth     int // number of unclosed { up to and including curToken
+	// the first error was reported at the end of the input
+	incomplete bool
 
 	curToken  token.Token
 	peekToken token.Token
@@ -61,8 +104,8 @@ type Parser struct {
 
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
-		l:      l,
-		errors: []string{},
+		l:           l,
+		diagnostics: []Diagnostic{},
 	}
 
 	// Read two tokens, so curToken and peelToken are both set
@@ -73,6 +116,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
 	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.DECIMAL, p.parseDecimalLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
@@ -80,6 +124,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.NULL, p.parseNull)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
@@ -91,6 +136,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.MOD, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
+	p.registerInfix(token.POW, p.parseInfixExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.AND, p.parseInfixExpression)
@@ -99,6 +145,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
 	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.IS, p.parseIsExpression)
 	p.registerInfix(token.PERIOD, p.parseBuiltinExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
@@ -123,12 +170,116 @@ func (p *Parser) registerPostfix(tokenType token.TokenType, fn postfixParseFn) {
 }
 
 func (p *Parser) Errors() []string {
-	return p.errors
+	errors := []string{}
+	for _, d := range p.diagnostics {
+		if d.Severity == SeverityError {
+			errors = append(errors, d.Message)
+		}
+	}
+
+	return errors
+}
+
+func (p *Parser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// Incomplete reports whether the program failed to parse because the input
+// ended early, in an open block, string or comment or where an expression
+// was expected. More input may complete it.
+func (p *Parser) Incomplete() bool {
+	return p.incomplete || (len(p.diagnostics) == 0 && p.l.Unterminated())
+}
+
+func (p *Parser) errorAt(tok token.Token, msg string) {
+	if p.panicking {
+		return
+	}
+	p.panicking = true
+
+	if tok.Type == token.EOF && len(p.diagnostics) == 0 {
+		p.incomplete = true
+	}
+
+	d := Diagnostic{Severity: SeverityError, Message: msg, Line: tok.Line, Column: tok.Column}
+	for _, existing := range p.diagnostics {
+		if existing == d {
+			return
+		}
+	}
+
+	p.diagnostics = append(p.diagnostics, d)
+}
+
+// synchronize skips the rest of a statement that failed to parse, given the
+// brace depth the statement started at. It stops on the last token of the
+// statement: a semicolon, the end of the line or the token before a keyword
+// that starts a statement or before the } that closes the enclosing block.
+// It reports whether the error already consumed that }
+func (p *Parser) synchronize(depth int) bool {
+	p.panicking = false
+
+	for !p.curTokenIs(token.EOF) {
+		if p.depth < depth {
+			return true
+		}
+
+		if p.depth == depth {
+			switch {
+			case p.curTokenIs(token.SEMICOLON),
+				p.peekTokenIs(token.RBRACE),
+				p.peekTokenIs(token.EOF),
+				startsStatement(p.peekToken.Type),
+				p.peekToken.Line > p.curToken.Line && !p.peekTokenIs(token.LBRACE):
+				return false
+			}
+		}
+
+		p.nextToken()
+	}
+
+	return false
+}
+
+// statementDepth is the brace depth outside of the current token
+func (p *Parser) statementDepth() int {
+	if p.curTokenIs(token.LBRACE) {
+		return p.depth - 1
+	}
+	return p.depth
+}
+
+// isNilStatement also catches statements that failed to parse, which are
+// returned as nil pointers wrapped in the interface
+func isNilStatement(stmt ast.Statement) bool {
+	if stmt == nil {
+		return true
+	}
+	v := reflect.ValueOf(stmt)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+func startsStatement(t token.TokenType) bool {
+	switch t {
+	case token.LET, token.CONST, token.RETURN, token.FOR, token.WHILE,
+		token.BREAK, token.CONTINUE, token.DEFER:
+		return true
+	}
+	return false
 }
 
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+
+	switch p.curToken.Type {
+	case token.LBRACE:
+		p.depth++
+	case token.RBRACE:
+		if p.depth > 0 {
+			p.depth--
+		}
+	}
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
@@ -136,10 +287,14 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program.Statements = []ast.Statement{}
 
 	for !p.curTokenIs(token.EOF) {
+		depth := p.statementDepth()
 		stmt := p.parseStatement()
-		if stmt != nil {
+		if !isNilStatement(stmt) {
 			program.Statements = append(program.Statements, stmt)
 		}
+		if p.panicking {
+			p.synchronize(depth)
+		}
 		p.nextToken()
 	}
 
@@ -174,7 +329,9 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseContinueStatement()
 	case token.WHILE:
 		return p.parseWhileStatement()
-	case token.COMMENT, token.COMMENT_START, token.COMMENT_END:
+	case token.DEFER:
+		return p.parseDeferStatement()
+	case token.COMMENT, token.COMMENT_START, token.COMMENT_END, token.SEMICOLON:
 		return nil
 	default:
 		return p.parseExpressionStatement()
@@ -185,7 +342,7 @@ func (p *Parser) parseLetStatement(constant bool) *ast.LetStatement {
 	stmt := &ast.LetStatement{}
 
 	if constant {
-		stmt.Token = token.Token{Type: token.LET, Literal: "let"}
+		stmt.Token = token.Token{Type: token.LET, Literal: "let", Line: p.curToken.Line, Column: p.curToken.Column}
 	} else {
 		stmt.Token = p.curToken
 	}
@@ -199,6 +356,14 @@ func (p *Parser) parseLetStatement(constant bool) *ast.LetStatement {
 		stmt.Name.Const = true
 	}
 
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		p.nextToken()
+		if stmt.Type = p.parseType(); stmt.Type == nil {
+			return nil
+		}
+	}
+
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
@@ -222,9 +387,14 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if bigValue, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok {
+			return &ast.BigIntegerLiteral{Token: p.curToken, Value: bigValue}
+		}
+	}
 	if err != nil {
 		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.errorAt(p.curToken, msg)
 		return nil
 	}
 
@@ -239,7 +409,7 @@ func (p *Parser) parseFloatLiteral() ast.Expression {
 	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
 	if err != nil {
 		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.errorAt(p.curToken, msg)
 		return nil
 	}
 
@@ -248,6 +418,10 @@ func (p *Parser) parseFloatLiteral() ast.Expression {
 	return lit
 }
 
+func (p *Parser) parseDecimalLiteral() ast.Expression {
+	return &ast.DecimalLiteral{Token: p.curToken, Value: strings.TrimSuffix(p.curToken.Literal, "d")}
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
@@ -334,12 +508,29 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	}
 
 	precedence := p.curPrecedence()
+
+	// ** is right associative
+	if p.curTokenIs(token.POW) {
+		precedence--
+	}
+
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 
 	return expression
 }
 
+func (p *Parser) parseIsExpression(left ast.Expression) ast.Expression {
+	expression := &ast.IsExpression{Token: p.curToken, Left: left}
+
+	p.nextToken()
+	if expression.Type = p.parseType(); expression.Type == nil {
+		return nil
+	}
+
+	return expression
+}
+
 func (p *Parser) parsePostfixExpression(left ast.Expression) ast.Expression {
 	expression := &ast.PostfixExpression{
 		Token:    p.curToken,
@@ -420,6 +611,183 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{Token: p.curToken}
+
+	p.nextToken()
+	if expression.Value = p.parseExpression(LOWEST); expression.Value == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		arm, ok := p.parseMatchArm()
+		if !ok {
+			return nil
+		}
+		expression.Arms = append(expression.Arms, arm)
+
+		// an arm that isn't a block ends at the comma, as the next pattern
+		// could continue its expression
+		if p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+		} else if arm.Body.Token.Type != token.LBRACE && !p.peekTokenIs(token.RBRACE) {
+			p.peekError(token.COMMA)
+			return nil
+		}
+	}
+	p.nextToken()
+
+	return expression
+}
+
+func (p *Parser) parseMatchArm() (ast.MatchArm, bool) {
+	arm := ast.MatchArm{Pattern: p.parsePattern()}
+	if arm.Pattern == nil {
+		return arm, false
+	}
+
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		p.nextToken()
+		if arm.Guard = p.parseExpression(LOWEST); arm.Guard == nil {
+			return arm, false
+		}
+	}
+
+	if !p.expectPeek(token.FAT_ARROW) {
+		return arm, false
+	}
+	p.nextToken()
+
+	if p.curTokenIs(token.LBRACE) {
+		arm.Body = p.parseBlockStatement()
+		return arm, true
+	}
+
+	tok := p.curToken
+	value := p.parseExpression(LOWEST)
+	if value == nil {
+		return arm, false
+	}
+	arm.Body = &ast.BlockStatement{
+		Token:      tok,
+		Statements: []ast.Statement{&ast.ExpressionStatement{Token: tok, Expression: value}},
+		End:        p.curToken,
+	}
+
+	return arm, true
+}
+
+func (p *Parser) parsePattern() ast.Pattern {
+	switch p.curToken.Type {
+	case token.IDENT:
+		if p.curToken.Literal == "_" {
+			return &ast.WildcardPattern{Token: p.curToken}
+		}
+		return &ast.BindingPattern{Token: p.curToken, Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}}
+
+	case token.INT, token.FLOAT, token.DECIMAL, token.STRING, token.TRUE, token.FALSE, token.NULL:
+		tok := p.curToken
+		if value := p.prefixParseFns[tok.Type](); value != nil {
+			return &ast.LiteralPattern{Token: tok, Value: value}
+		}
+		return nil
+
+	case token.MINUS:
+		if p.peekTokenIs(token.INT) || p.peekTokenIs(token.FLOAT) || p.peekTokenIs(token.DECIMAL) {
+			tok := p.curToken
+			if value := p.parsePrefixExpression(); value != nil {
+				return &ast.LiteralPattern{Token: tok, Value: value}
+			}
+			return nil
+		}
+
+	case token.LBRACKET:
+		return p.parseArrayPattern()
+
+	case token.LBRACE:
+		return p.parseHashPattern()
+	}
+
+	p.errorAt(p.curToken, fmt.Sprintf("expected a pattern, got %s instead", p.curToken.Type))
+	return nil
+}
+
+func (p *Parser) parseArrayPattern() ast.Pattern {
+	pattern := &ast.ArrayPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+
+		// the rest element comes last, `...` alone ignores the rest
+		if p.curTokenIs(token.ELLIPSIS) {
+			pattern.Rest = &ast.WildcardPattern{Token: p.curToken}
+			if p.peekTokenIs(token.IDENT) {
+				p.nextToken()
+				if pattern.Rest = p.parsePattern(); pattern.Rest == nil {
+					return nil
+				}
+			}
+			if !p.expectPeek(token.RBRACKET) {
+				return nil
+			}
+			return pattern
+		}
+
+		element := p.parsePattern()
+		if element == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, element)
+
+		if !p.peekTokenIs(token.RBRACKET) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+
+	return pattern
+}
+
+func (p *Parser) parseHashPattern() ast.Pattern {
+	pattern := &ast.HashPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		key, ok := p.parsePattern().(*ast.LiteralPattern)
+		if !ok {
+			p.errorAt(p.curToken, fmt.Sprintf("expected a literal key in the hash pattern, got %s instead", p.curToken.Type))
+			return nil
+		}
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+
+		value := p.parsePattern()
+		if value == nil {
+			return nil
+		}
+		pattern.Keys = append(pattern.Keys, key.Value)
+		pattern.Values = append(pattern.Values, value)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+
+	return pattern
+}
+
 func (p *Parser) parseForStatement() ast.Statement {
 	tok := p.curToken
 
@@ -434,7 +802,7 @@ func (p *Parser) parseForStatement() ast.Statement {
 		return p.parseForInLoopStatement(tok)
 	default:
 		msg := fmt.Sprintf("could not parse %q for a for loop", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.errorAt(p.curToken, msg)
 		return nil
 	}
 }
@@ -524,6 +892,27 @@ func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
 	return &ast.ContinueStatement{Token: p.curToken}
 }
 
+func (p *Parser) parseDeferStatement() ast.Statement {
+	stmt := &ast.DeferStatement{Token: p.curToken}
+
+	p.nextToken()
+
+	switch call := p.parseExpression(LOWEST).(type) {
+	case *ast.CallExpression, *ast.BuiltinExpression:
+		stmt.Call = call
+	default:
+		msg := fmt.Sprintf("expression in defer must be function call, got %q", p.curToken.Literal)
+		p.errorAt(stmt.Token, msg)
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseWhileStatement() *ast.WhileStatement {
 	while := &ast.WhileStatement{Token: p.curToken}
 
@@ -554,13 +943,23 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	p.nextToken()
 
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		depth := p.statementDepth()
 		stmt := p.parseStatement()
-		if stmt != nil {
+		if !isNilStatement(stmt) {
 			block.Statements = append(block.Statements, stmt)
 		}
+		if p.panicking && p.synchronize(depth) {
+			break
+		}
 		p.nextToken()
 	}
 
+	if p.curTokenIs(token.EOF) {
+		p.errorAt(p.curToken, fmt.Sprintf("expected } to close the block opened at %d:%d, got EOF instead", block.Token.Line, block.Token.Column))
+	}
+
+	block.End = p.curToken
+
 	return block
 }
 
@@ -571,7 +970,9 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		return nil
 	}
 
-	lit.Parameters = p.parseFunctionParameters()
+	if !p.parseSignature(lit) {
+		return nil
+	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -582,43 +983,191 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return lit
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+// parseSignature parses the parameters of lit after the ( and the return
+// type that may follow them
+func (p *Parser) parseSignature(lit *ast.FunctionLiteral) bool {
+	lit.Parameters, lit.ParameterTypes = p.parseFunctionParameters()
+	if lit.Parameters == nil {
+		return false
+	}
 
-	if p.peekTokenIs(token.RPAREN) {
+	if p.peekTokenIs(token.ARROW) {
+		p.nextToken()
 		p.nextToken()
-		return identifiers
+		if lit.ReturnType = p.parseType(); lit.ReturnType == nil {
+			return false
+		}
 	}
 
-	p.nextToken()
+	return true
+}
 
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
+func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, []ast.TypeExpr) {
+	identifiers := []*ast.Identifier{}
+	var types []ast.TypeExpr
 
-	for p.peekTokenIs(token.COMMA) {
+	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
+		return identifiers, nil
+	}
+
+	for {
 		p.nextToken()
+
 		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 		identifiers = append(identifiers, ident)
+
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			p.nextToken()
+
+			typ := p.parseType()
+			if typ == nil {
+				return nil, nil
+			}
+			if types == nil {
+				types = make([]ast.TypeExpr, len(identifiers)-1)
+			}
+			types = append(types, typ)
+		} else if types != nil {
+			types = append(types, nil)
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
 	}
 
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		return nil, nil
 	}
 
-	return identifiers
+	if types != nil {
+		for len(types) < len(identifiers) {
+			types = append(types, nil)
+		}
+	}
+
+	return identifiers, types
 }
 
+// parseType parses the type annotation starting at the current token, a
+// union of the types separated by |
+func (p *Parser) parseType() ast.TypeExpr {
+	first := p.parseSingleType()
+	if first == nil || !p.peekTokenIs(token.PIPE) {
+		return first
+	}
+
+	union := &ast.UnionType{Token: p.peekToken, Types: []ast.TypeExpr{first}}
+	for p.peekTokenIs(token.PIPE) {
+		p.nextToken()
+		p.nextToken()
+
+		typ := p.parseSingleType()
+		if typ == nil {
+			return nil
+		}
+		union.Types = append(union.Types, typ)
+	}
+
+	return union
+}
+
+func (p *Parser) parseSingleType() ast.TypeExpr {
+	switch p.curToken.Type {
+	case token.IDENT, token.NULL:
+		return &ast.NamedType{Token: p.curToken, Name: p.curToken.Literal}
+
+	case token.LBRACKET:
+		typ := &ast.ArrayType{Token: p.curToken}
+		p.nextToken()
+		if typ.Element = p.parseType(); typ.Element == nil || !p.expectPeek(token.RBRACKET) {
+			return nil
+		}
+		return typ
+
+	case token.LBRACE:
+		typ := &ast.HashType{Token: p.curToken}
+		p.nextToken()
+		if typ.Key = p.parseType(); typ.Key == nil || !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		if typ.Value = p.parseType(); typ.Value == nil || !p.expectPeek(token.RBRACE) {
+			return nil
+		}
+		return typ
+
+	case token.FUNCTION:
+		// fn on its own is any function
+		if !p.peekTokenIs(token.LPAREN) {
+			return &ast.NamedType{Token: p.curToken, Name: p.curToken.Literal}
+		}
+
+		typ := &ast.FunctionType{Token: p.curToken, Parameters: []ast.TypeExpr{}}
+		p.nextToken()
+
+		for !p.peekTokenIs(token.RPAREN) {
+			p.nextToken()
+			param := p.parseType()
+			if param == nil {
+				return nil
+			}
+			typ.Parameters = append(typ.Parameters, param)
+
+			if !p.peekTokenIs(token.RPAREN) && !p.expectPeek(token.COMMA) {
+				return nil
+			}
+		}
+		p.nextToken()
+
+		if p.peekTokenIs(token.ARROW) {
+			p.nextToken()
+			p.nextToken()
+			if typ.Return = p.parseType(); typ.Return == nil {
+				return nil
+			}
+		}
+		return typ
+
+	case token.LPAREN:
+		p.nextToken()
+		typ := p.parseType()
+		if typ == nil || !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+		return typ
+	}
+
+	p.errorAt(p.curToken, fmt.Sprintf("expected a type, got %s instead", p.curToken.Type))
+	return nil
+}
+
+// parseBuiltinExpression reads the name after a dot, a method call when
+// arguments follow and a property otherwise, like os.args
 func (p *Parser) parseBuiltinExpression(left ast.Expression) ast.Expression {
-	exp := &ast.BuiltinExpression{Token: p.curToken, Left: left}
+	tok := p.curToken
 
-	p.nextToken()
-	callExp, ok := p.parseExpression(LOWEST).(*ast.CallExpression)
-	if !ok {
+	// match is a keyword, but still the name of the regex method
+	if p.peekTokenIs(token.MATCH) {
+		p.peekToken.Type = token.IDENT
+	}
+
+	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
 
-	exp.Builtin = callExp
+	name := p.parseIdentifier().(*ast.Identifier)
+
+	if !p.peekTokenIs(token.LPAREN) {
+		return &ast.PropertyExpression{Token: tok, Left: left, Property: name}
+	}
+	p.nextToken()
+
+	exp := &ast.BuiltinExpression{Token: tok, Left: left}
+	exp.Builtin = p.parseCallExpression(name).(*ast.CallExpression)
 
 	return exp
 }
@@ -671,7 +1220,9 @@ func (p *Parser) parseFunctionDeclaration() ast.Statement {
 		return nil
 	}
 
-	lit.Parameters = p.parseFunctionParameters()
+	if !p.parseSignature(lit) {
+		return nil
+	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -746,25 +1297,27 @@ func (p *Parser) parseReassignStatement() ast.Statement {
 	return reassign
 }
 
-func (p *Parser) peekPrecedence() int {
-	if p, ok := precedences[p.peekToken.Type]; ok {
+// Precedence is how tightly the operator t binds its operands, LOWEST for
+// tokens that aren't operators
+func Precedence(t token.TokenType) int {
+	if p, ok := precedences[t]; ok {
 		return p
 	}
 
 	return LOWEST
 }
 
-func (p *Parser) curPrecedence() int {
-	if p, ok := precedences[p.curToken.Type]; ok {
-		return p
-	}
+func (p *Parser) peekPrecedence() int {
+	return Precedence(p.peekToken.Type)
+}
 
-	return LOWEST
+func (p *Parser) curPrecedence() int {
+	return Precedence(p.curToken.Type)
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
 	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.errorAt(p.curToken, msg)
 }
 
 func (p *Parser) curTokenIs(t token.TokenType) bool {
@@ -788,5 +1341,5 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 func (p *Parser) peekError(t token.TokenType) {
 	msg := fmt.Sprintf("expected next token to be %s, got %s instead",
 		t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.errorAt(p.peekToken, msg)
 }
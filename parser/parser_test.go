@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/joshuahenriques/cixac/ast"
@@ -183,6 +184,33 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestBigIntegerLiteralExpression(t *testing.T) {
+	input := "123456789012345678901234567890;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program has not enough statements. got=%d",
+			len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	literal, ok := stmt.Expression.(*ast.BigIntegerLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.BigIntegerLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Value.String() != "123456789012345678901234567890" {
+		t.Errorf("literal.Value wrong. got=%s", literal.Value)
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input    string
@@ -356,6 +384,22 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"a * b / c",
 			"((a * b) / c)",
 		},
+		{
+			"a * b ** c",
+			"(a * (b ** c))",
+		},
+		{
+			"a ** b ** c",
+			"(a ** (b ** c))",
+		},
+		{
+			"-a ** b",
+			"(-(a ** b))",
+		},
+		{
+			"a ** -b",
+			"(a ** (-b))",
+		},
 		{
 			"a + b && c",
 			"((a + b) && c)",
@@ -990,6 +1034,30 @@ func TestParsingBuiltinExpressions(t *testing.T) {
 	testInfixExpression(t, exp.Arguments[0], 1, "+", 1)
 }
 
+func TestParsingPropertyExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"os.args", "(os.args)"},
+		{"os.args[0]", "((os.args)[0])"},
+		{"os.env(\"HOME\").upper()", "((os.env(HOME)).upper())"},
+		{"len(os.args) + 1", "(len((os.args)) + 1)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
 func TestParsingIndexExpressions(t *testing.T) {
 	input := "myArray[1 + 1]"
 
@@ -1381,6 +1449,46 @@ func TestContinueStatement(t *testing.T) {
 	}
 }
 
+func TestDeferStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`defer close(file)`, "defer close(file);"},
+		{`defer arr.push(1);`, "defer (arr.push(1));"},
+		{`defer fn() { x }()`, "defer fn() x();"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+				len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.DeferStatement)
+		if !ok {
+			t.Fatalf("stmt not *ast.DeferStatement. got=%T", program.Statements[0])
+		}
+
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() wrong. expected=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+
+	l := lexer.New(`defer 5`)
+	p := New(l)
+	p.ParseProgram()
+
+	if len(p.Errors()) != 1 {
+		t.Fatalf("parser has %d errors, want 1", len(p.Errors()))
+	}
+}
+
 func TestParseReassignStatement(t *testing.T) {
 	tests := []struct {
 		input              string
@@ -1675,3 +1783,284 @@ func checkParserErrors(t *testing.T, p *Parser) {
 	}
 	t.FailNow()
 }
+
+func TestParserDiagnosticPositions(t *testing.T) {
+	input := `let x = 1;
+let y 2;
+defer 5`
+
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	expected := []Diagnostic{
+		{Severity: SeverityError, Message: "expected next token to be =, got INT instead", Line: 2, Column: 7},
+		{Severity: SeverityError, Message: "expression in defer must be function call, got \"5\"", Line: 3, Column: 1},
+	}
+
+	diagnostics := p.Diagnostics()
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("wrong number of diagnostics. expected=%d, got=%d (%+v)", len(expected), len(diagnostics), diagnostics)
+	}
+
+	for i, d := range diagnostics {
+		if d != expected[i] {
+			t.Errorf("diagnostic %d wrong. expected=%+v, got=%+v", i, expected[i], d)
+		}
+	}
+}
+
+func TestParserErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedErrors     []string
+		expectedStatements []string
+	}{
+		{
+			"let x 5; let y = 10; let = 3; let z = y;",
+			[]string{
+				"1:7: error: expected next token to be =, got INT instead",
+				"1:26: error: expected next token to be IDENT, got = instead",
+			},
+			[]string{"let y = 10;", "let z = y;"},
+		},
+		{
+			"fn f() { let = 1; return 2 }\nlet ok = 3",
+			[]string{"1:14: error: expected next token to be IDENT, got = instead"},
+			[]string{"fn() return 2;", "let ok = 3;"},
+		},
+		{
+			"let a = (1 + ;\nlet b = 2\nlet c = * 3\nprint(b)",
+			[]string{
+				"1:14: error: no prefix parse function for ; found",
+				"3:9: error: no prefix parse function for * found",
+			},
+			[]string{"let a = ;", "let b = 2;", "let c = ;", "print(b)"},
+		},
+		{
+			"if (x) { let x = }\nlet after = 1",
+			[]string{"1:18: error: no prefix parse function for } found"},
+			[]string{"ifx let x = ;", "let after = 1;"},
+		},
+		{
+			`let h = {"a": 1,, "b": 2}` + "\nlet after = 1",
+			[]string{"1:17: error: no prefix parse function for , found"},
+			[]string{"let h = ;", "let after = 1;"},
+		},
+		{
+			"while (true) { break; ) ) ) }\nlet ok = 1",
+			[]string{"1:23: error: no prefix parse function for ) found"},
+			[]string{"while (true) {\nbreak\n}", "let ok = 1;"},
+		},
+		{
+			"@ @ @\n@\nlet ok = 1",
+			[]string{
+				"1:1: error: no prefix parse function for ILLEGAL found",
+				"2:1: error: no prefix parse function for ILLEGAL found",
+			},
+			[]string{"", "", "let ok = 1;"},
+		},
+	}
+
+	for i, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		diagnostics := []string{}
+		for _, d := range p.Diagnostics() {
+			diagnostics = append(diagnostics, d.String())
+		}
+		if strings.Join(diagnostics, "\n") != strings.Join(tt.expectedErrors, "\n") {
+			t.Errorf("[test: %d] wrong diagnostics.\nexpected=%q\ngot=%q", i, tt.expectedErrors, diagnostics)
+		}
+
+		statements := []string{}
+		for _, stmt := range program.Statements {
+			statements = append(statements, stmt.String())
+		}
+		if strings.Join(statements, "|") != strings.Join(tt.expectedStatements, "|") {
+			t.Errorf("[test: %d] wrong statements.\nexpected=%q\ngot=%q", i, tt.expectedStatements, statements)
+		}
+	}
+}
+
+func TestParserIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"let x = 1", false},
+		{"fn f() {\n\tlet x = 1", true},
+		{"if (x) { 1 } else {", true},
+		{"let a = [1, 2,", true},
+		{"print(1,\n2", true},
+		{"let x = 1 +", true},
+		{"x.", true},
+		{`let s = "open`, true},
+		{"/* open comment", true},
+		{"let = 1; fn f() {", false},
+		{"let x = 1 )", false},
+		{"fn f() { 1 }", false},
+		{"match x {\n\t1 => 2,", true},
+		{"match x { 1 => {", true},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if p.Incomplete() != tt.expected {
+			t.Errorf("Incomplete() for %q wrong. expected=%t, got=%t (%v)", tt.input, tt.expected, p.Incomplete(), p.Diagnostics())
+		}
+	}
+}
+
+func TestParserUnclosedBlock(t *testing.T) {
+	p := New(lexer.New("fn f() {\n\treturn 1"))
+	p.ParseProgram()
+
+	expected := "2:10: error: expected } to close the block opened at 1:8, got EOF instead"
+	if len(p.Diagnostics()) != 1 || p.Diagnostics()[0].String() != expected {
+		t.Errorf("wrong diagnostics. expected=%q, got=%v", expected, p.Diagnostics())
+	}
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x: int = 1", "let x: int = 1;"},
+		{"const names: [string] = []", "let names: [string] = [];"},
+		{"let h: {string: [int | null]} = {}", "let h: {string: [int | null]} = {};"},
+		{"let f: fn(int, string) -> bool | null = g", "let f: fn(int, string) -> bool | null = g;"},
+		{"let f: fn = g", "let f: fn = g;"},
+		{"let f: (fn() -> int) | null = g", "let f: fn() -> int | null = g;"},
+		{"let add = fn(a: int, b) -> int { a + b }", "let add = fn(a: int, b) -> int (a + b);"},
+		{"fn add(a, b: float) -> float { a + b }", "fn(a, b: float) -> float (a + b)"},
+		{"let f = fn(a, b) { a }", "let f = fn(a, b) a;"},
+		{"x is int", "(x is int)"},
+		{"x is [int] | null == true", "((x is [int] | null) == true)"},
+		{"!x is int && y", "(((!x) is int) && y)"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("String() of %q wrong. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestFunctionParameterTypes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"fn(a, b) {}", nil},
+		{"fn(a: int, b) {}", []string{"int", ""}},
+		{"fn(a, b: [int]) {}", []string{"", "[int]"}},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		fn := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+		if tt.expected == nil {
+			if fn.ParameterTypes != nil {
+				t.Errorf("%q: expected no parameter types, got %v", tt.input, fn.ParameterTypes)
+			}
+			continue
+		}
+
+		if len(fn.ParameterTypes) != len(tt.expected) {
+			t.Fatalf("%q: wrong number of parameter types. expected=%d, got=%d", tt.input, len(tt.expected), len(fn.ParameterTypes))
+		}
+		for i, typ := range fn.ParameterTypes {
+			got := ""
+			if typ != nil {
+				got = typ.String()
+			}
+			if got != tt.expected[i] {
+				t.Errorf("%q: parameter %d type wrong. expected=%q, got=%q", tt.input, i, tt.expected[i], got)
+			}
+		}
+	}
+}
+
+func TestTypeAnnotationErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x: = 1", "1:8: error: expected a type, got = instead"},
+		{"fn f(a: [int) {}", "1:13: error: expected next token to be ], got ) instead"},
+		{"let h: {string} = {}", "1:15: error: expected next token to be :, got } instead"},
+		{"x is 1", "1:6: error: expected a type, got INT instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if len(p.Diagnostics()) == 0 || p.Diagnostics()[0].String() != tt.expected {
+			t.Errorf("%q: wrong diagnostics. expected=%q, got=%v", tt.input, tt.expected, p.Diagnostics())
+		}
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match x { 0 => a, _ => b }", "match x { 0 => a, _ => b }"},
+		{"match x { -1 => a, 1.5 => b, 2d => c, \"s\" => d, true => e, null => f }", "match x { (-1) => a, 1.5 => b, 2d => c, s => d, true => e, null => f }"},
+		{"match x { [] => a, [first, ...rest] => b, [_, ...] => c, [y] => d }", "match x { [] => a, [first, ...rest] => b, [_, ...] => c, [y] => d }"},
+		{"match x { {\"type\": \"circle\", \"r\": r} => r }", "match x { {type: circle, r: r} => r }"},
+		{"match x { n if n > 10 => n, _ => 0 }", "match x { n if (n > 10) => n, _ => 0 }"},
+		{"match x {\n\t1 => {\n\t\tlet y = 2\n\t\ty\n\t}\n\t_ => 3\n}", "match x { 1 => let y = 2;y, _ => 3 }"},
+		{"let r = match f(x) { _ => 1, }", "let r = match f(x) { _ => 1 };"},
+		{"match x {}", "match x {}"},
+		{"re(\"a\").match(s)", "(re(a).match(s))"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("String() of %q wrong. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestMatchExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match x { 1 => a 2 => b }", "1:18: error: expected next token to be ,, got INT instead"},
+		{"match x { 1 a }", "1:13: error: expected next token to be =>, got IDENT instead"},
+		{"match x { f() => 1 }", "1:12: error: expected next token to be =>, got ( instead"},
+		{"match x { + => 1 }", "1:11: error: expected a pattern, got + instead"},
+		{"match x { [...a, b] => 1 }", "1:16: error: expected next token to be ], got , instead"},
+		{"match x { {k: v} => 1 }", "1:12: error: expected a literal key in the hash pattern, got IDENT instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if len(p.Diagnostics()) == 0 || p.Diagnostics()[0].String() != tt.expected {
+			t.Errorf("%q: wrong diagnostics. expected=%q, got=%v", tt.input, tt.expected, p.Diagnostics())
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package profile
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// The pprof format is a gzipped protocol buffer, see
+// https://github.com/google/pprof/blob/main/proto/profile.proto
+// Only the messages and fields written here are encoded.
+
+type valueType struct {
+	typ, unit string
+}
+
+type location struct {
+	function int
+	line     int
+}
+
+type function struct {
+	name      string
+	startLine int
+}
+
+type pprofSample struct {
+	locations []int // ids, innermost first
+	values    []int64
+}
+
+type pprof struct {
+	sampleTypes []valueType
+	periodType  valueType
+	period      int64
+	timeNanos   int64
+	duration    int64
+	filename    string
+
+	samples   []pprofSample
+	functions []function
+	locations []location
+
+	functionIDs map[function]int
+	locationIDs map[location]int
+	strings     []string
+	stringIDs   map[string]int
+}
+
+func newPprof(filename string) *pprof {
+	return &pprof{
+		filename:    filename,
+		functionIDs: map[function]int{},
+		locationIDs: map[location]int{},
+		strings:     []string{""},
+		stringIDs:   map[string]int{"": 0},
+	}
+}
+
+// add records a sample of the stack, innermost frame first
+func (p *pprof) add(stack []frame, values ...int64) {
+	ids := make([]int, len(stack))
+	for i, f := range stack {
+		ids[i] = p.location(f)
+	}
+	p.samples = append(p.samples, pprofSample{locations: ids, values: values})
+}
+
+func (p *pprof) location(f frame) int {
+	fn := function{name: f.name, startLine: f.startLine}
+	fnID, ok := p.functionIDs[fn]
+	if !ok {
+		p.functions = append(p.functions, fn)
+		fnID = len(p.functions)
+		p.functionIDs[fn] = fnID
+	}
+
+	loc := location{function: fnID, line: f.line}
+	id, ok := p.locationIDs[loc]
+	if !ok {
+		p.locations = append(p.locations, loc)
+		id = len(p.locations)
+		p.locationIDs[loc] = id
+	}
+
+	return id
+}
+
+func (p *pprof) str(s string) int64 {
+	id, ok := p.stringIDs[s]
+	if !ok {
+		p.strings = append(p.strings, s)
+		id = len(p.strings) - 1
+		p.stringIDs[s] = id
+	}
+	return int64(id)
+}
+
+func (p *pprof) write(w io.Writer) error {
+	var b buffer
+
+	for _, vt := range p.sampleTypes {
+		b.message(1, p.valueType(vt))
+	}
+
+	for _, s := range p.samples {
+		var sb buffer
+		sb.packed(1, intsToUint64(s.locations))
+		values := make([]uint64, len(s.values))
+		for i, v := range s.values {
+			values[i] = uint64(v)
+		}
+		sb.packed(2, values)
+		b.message(2, sb)
+	}
+
+	for i, loc := range p.locations {
+		var line buffer
+		line.varintField(1, uint64(loc.function))
+		line.varintField(2, uint64(loc.line))
+
+		var lb buffer
+		lb.varintField(1, uint64(i+1))
+		lb.message(4, line)
+		b.message(4, lb)
+	}
+
+	filename := p.str(p.filename)
+	for i, fn := range p.functions {
+		var fb buffer
+		fb.varintField(1, uint64(i+1))
+		fb.varintField(2, uint64(p.str(fn.name)))
+		fb.varintField(3, uint64(p.str(fn.name)))
+		fb.varintField(4, uint64(filename))
+		fb.varintField(5, uint64(fn.startLine))
+		b.message(5, fb)
+	}
+
+	period := p.valueType(p.periodType)
+
+	// strings are all interned by now
+	for _, s := range p.strings {
+		b.bytesField(6, []byte(s))
+	}
+
+	b.varintField(9, uint64(p.timeNanos))
+	b.varintField(10, uint64(p.duration))
+	b.message(11, period)
+	b.varintField(12, uint64(p.period))
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(b.data); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func (p *pprof) valueType(vt valueType) buffer {
+	var b buffer
+	b.varintField(1, uint64(p.str(vt.typ)))
+	b.varintField(2, uint64(p.str(vt.unit)))
+	return b
+}
+
+func intsToUint64(ints []int) []uint64 {
+	out := make([]uint64, len(ints))
+	for i, n := range ints {
+		out[i] = uint64(n)
+	}
+	return out
+}
+
+// buffer encodes protocol buffer fields
+type buffer struct {
+	data []byte
+}
+
+func (b *buffer) varint(x uint64) {
+	for x >= 0x80 {
+		b.data = append(b.data, byte(x)|0x80)
+		x >>= 7
+	}
+	b.data = append(b.data, byte(x))
+}
+
+func (b *buffer) key(field, wireType int) {
+	b.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (b *buffer) varintField(field int, x uint64) {
+	if x == 0 {
+		return
+	}
+	b.key(field, 0)
+	b.varint(x)
+}
+
+func (b *buffer) bytesField(field int, data []byte) {
+	b.key(field, 2)
+	b.varint(uint64(len(data)))
+	b.data = append(b.data, data...)
+}
+
+func (b *buffer) message(field int, m buffer) {
+	b.bytesField(field, m.data)
+}
+
+func (b *buffer) packed(field int, xs []uint64) {
+	if len(xs) == 0 {
+		return
+	}
+	var p buffer
+	for _, x := range xs {
+		p.varint(x)
+	}
+	b.bytesField(field, p.data)
+}
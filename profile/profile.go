@@ -0,0 +1,313 @@
+// Package profile samples the Cixac call stack of a running program and
+// writes pprof profiles and a table of calls per function.
+package profile
+
+import (
+	"fmt"
+	"io"
+	"runtime/metrics"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/evaluator"
+	"github.com/joshuahenriques/cixac/object"
+)
+
+// Period is the interval the call stack is sampled at
+var Period = 10 * time.Millisecond
+
+type frame struct {
+	name      string
+	startLine int
+	line      int
+}
+
+type sample struct {
+	stack []frame
+	count int64
+}
+
+type allocs struct {
+	stack   []frame
+	objects int64
+	bytes   int64
+}
+
+type stats struct {
+	calls  int
+	active int // calls on the stack, so recursion counts its time once
+	start  time.Time
+	cum    time.Duration
+}
+
+// Profiler is an evaluator.Hook, Start attaches it
+type Profiler struct {
+	filename string
+	start    time.Time
+	duration time.Duration
+
+	mu      sync.Mutex // guards stack and samples, the sampler reads them
+	stack   []frame
+	samples map[string]*sample
+
+	calls map[string]*stats
+	order []string // functions in the order they were first called
+
+	memory   bool
+	metrics  []metrics.Sample
+	lastObjs uint64
+	lastSize uint64
+	allocs   map[string]*allocs
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Start attaches a profiler to the evaluator and starts sampling. The
+// program is named filename in the profiles. With memory set it also
+// attributes heap allocations to the statements that make them.
+func Start(filename string, memory bool) *Profiler {
+	p := &Profiler{
+		filename: filename,
+		start:    time.Now(),
+		stack:    []frame{{name: "main", startLine: 1}},
+		samples:  map[string]*sample{},
+		calls:    map[string]*stats{"main": {calls: 1, active: 1, start: time.Now()}},
+		order:    []string{"main"},
+		memory:   memory,
+		allocs:   map[string]*allocs{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if memory {
+		p.metrics = []metrics.Sample{{Name: "/gc/heap/allocs:objects"}, {Name: "/gc/heap/allocs:bytes"}}
+		p.lastObjs, p.lastSize = p.readAllocs()
+	}
+
+	evaluator.SetHook(p)
+	go p.sample()
+
+	return p
+}
+
+// Stop detaches the profiler and ends sampling
+func (p *Profiler) Stop() {
+	evaluator.SetHook(nil)
+	close(p.stop)
+	<-p.done
+
+	p.duration = time.Since(p.start)
+	if p.memory {
+		p.recordAllocs()
+	}
+
+	main := p.calls["main"]
+	main.cum += time.Since(main.start)
+}
+
+func (p *Profiler) sample() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(Period)
+	defer ticker.Stop()
+
+	// ticks are dropped while the sampler waits to run, so a sample counts
+	// every period since the last one
+	last := p.start
+
+	for {
+		select {
+		case <-p.stop:
+			p.record(&last, time.Now())
+			return
+		case now := <-ticker.C:
+			p.record(&last, now)
+		}
+	}
+}
+
+func (p *Profiler) record(last *time.Time, now time.Time) {
+	n := int64(now.Sub(*last) / Period)
+	if n == 0 {
+		return
+	}
+	*last = last.Add(time.Duration(n) * Period)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := stackKey(p.stack)
+	s, ok := p.samples[key]
+	if !ok {
+		s = &sample{stack: reversed(p.stack)}
+		p.samples[key] = s
+	}
+	s.count += n
+}
+
+func stackKey(stack []frame) string {
+	var b strings.Builder
+	for _, f := range stack {
+		fmt.Fprintf(&b, "%s:%d:%d;", f.name, f.startLine, f.line)
+	}
+	return b.String()
+}
+
+// reversed copies the stack innermost first, as pprof wants it
+func reversed(stack []frame) []frame {
+	out := make([]frame, len(stack))
+	for i, f := range stack {
+		out[len(stack)-1-i] = f
+	}
+	return out
+}
+
+func (p *Profiler) Statement(stmt ast.Statement, env *object.Environment) {
+	if p.memory {
+		p.recordAllocs()
+	}
+
+	p.mu.Lock()
+	p.stack[len(p.stack)-1].line = ast.Start(stmt).Line
+	p.mu.Unlock()
+}
+
+func (p *Profiler) Call(fn *object.Function, env *object.Environment) {
+	if p.memory {
+		p.recordAllocs()
+	}
+
+	name := functionName(fn)
+
+	p.mu.Lock()
+	p.stack = append(p.stack, frame{name: name, startLine: fn.Body.Token.Line, line: fn.Body.Token.Line})
+	p.mu.Unlock()
+
+	s, ok := p.calls[name]
+	if !ok {
+		s = &stats{}
+		p.calls[name] = s
+		p.order = append(p.order, name)
+	}
+	s.calls++
+	if s.active == 0 {
+		s.start = time.Now()
+	}
+	s.active++
+}
+
+func (p *Profiler) Return(fn *object.Function) {
+	if p.memory {
+		p.recordAllocs()
+	}
+
+	p.mu.Lock()
+	p.stack = p.stack[:len(p.stack)-1]
+	p.mu.Unlock()
+
+	s := p.calls[functionName(fn)]
+	s.active--
+	if s.active == 0 {
+		s.cum += time.Since(s.start)
+	}
+}
+
+// functionName names anonymous functions after the line they start on
+func functionName(fn *object.Function) string {
+	if fn.Name != "" {
+		return fn.Name
+	}
+	return fmt.Sprintf("fn@%d", fn.Body.Token.Line)
+}
+
+func (p *Profiler) readAllocs() (uint64, uint64) {
+	metrics.Read(p.metrics)
+	return p.metrics[0].Value.Uint64(), p.metrics[1].Value.Uint64()
+}
+
+// recordAllocs attributes what was allocated since the last call to the
+// current statement
+func (p *Profiler) recordAllocs() {
+	objs, size := p.readAllocs()
+	if objs == p.lastObjs && size == p.lastSize {
+		return
+	}
+
+	key := stackKey(p.stack)
+	a, ok := p.allocs[key]
+	if !ok {
+		a = &allocs{stack: reversed(p.stack)}
+		p.allocs[key] = a
+	}
+	a.objects += int64(objs - p.lastObjs)
+	a.bytes += int64(size - p.lastSize)
+
+	p.lastObjs, p.lastSize = objs, size
+}
+
+// WriteCPU writes the sampled call stacks as a pprof CPU profile
+func (p *Profiler) WriteCPU(w io.Writer) error {
+	prof := newPprof(p.filename)
+	prof.sampleTypes = []valueType{{"samples", "count"}, {"cpu", "nanoseconds"}}
+	prof.periodType = valueType{"cpu", "nanoseconds"}
+	prof.period = Period.Nanoseconds()
+	prof.timeNanos = p.start.UnixNano()
+	prof.duration = p.duration.Nanoseconds()
+
+	for _, key := range sortedKeys(p.samples) {
+		s := p.samples[key]
+		prof.add(s.stack, s.count, s.count*Period.Nanoseconds())
+	}
+
+	return prof.write(w)
+}
+
+// WriteAllocs writes the allocations per statement as a pprof heap profile
+func (p *Profiler) WriteAllocs(w io.Writer) error {
+	prof := newPprof(p.filename)
+	prof.sampleTypes = []valueType{{"alloc_objects", "count"}, {"alloc_space", "bytes"}}
+	prof.periodType = valueType{"space", "bytes"}
+	prof.timeNanos = p.start.UnixNano()
+	prof.duration = p.duration.Nanoseconds()
+
+	for _, key := range sortedKeys(p.allocs) {
+		a := p.allocs[key]
+		prof.add(a.stack, a.objects, a.bytes)
+	}
+
+	return prof.write(w)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteTable writes the calls and cumulative time of each function, slowest
+// first
+func (p *Profiler) WriteTable(w io.Writer) {
+	names := append([]string{}, p.order...)
+	sort.SliceStable(names, func(i, j int) bool {
+		return p.calls[names[i]].cum > p.calls[names[j]].cum
+	})
+
+	fmt.Fprintf(w, "%8s %12s %7s  %s\n", "calls", "cumulative", "cum%", "function")
+
+	total := p.calls["main"].cum
+	for _, name := range names {
+		s := p.calls[name]
+		percent := 0.0
+		if total > 0 {
+			percent = 100 * float64(s.cum) / float64(total)
+		}
+		fmt.Fprintf(w, "%8d %12s %6.1f%%  %s\n", s.calls, s.cum.Round(time.Microsecond), percent, name)
+	}
+}
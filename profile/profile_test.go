@@ -0,0 +1,176 @@
+package profile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshuahenriques/cixac/evaluator"
+	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/object"
+	"github.com/joshuahenriques/cixac/parser"
+)
+
+const program = `fn fib(n) {
+	if (n < 2) { return n }
+	return fib(n - 1) + fib(n - 2)
+}
+let double = fn(x) { x * 2 }
+let total = 0
+for (let i = 0; i < 50; i++) { total += double(i) }
+fib(15)`
+
+func run(t *testing.T, memory bool) *Profiler {
+	t.Helper()
+
+	defer func(period time.Duration) { Period = period }(Period)
+	Period = time.Millisecond
+
+	p := parser.New(lexer.New(program))
+	prog := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parse errors: %v", p.Errors())
+	}
+
+	prof := Start("main.cx", memory)
+	result := evaluator.Eval(prog, object.NewEnvironment())
+	prof.Stop()
+
+	if result.Inspect() != "610" {
+		t.Fatalf("wrong result. got=%s", result.Inspect())
+	}
+
+	return prof
+}
+
+func TestTable(t *testing.T) {
+	prof := run(t, false)
+
+	var out bytes.Buffer
+	prof.WriteTable(&out)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("wrong number of lines. got=%q", out.String())
+	}
+
+	expected := map[string]string{"main": "1", "fib": "1973", "double": "50"}
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		name := fields[len(fields)-1]
+		if expected[name] != fields[0] {
+			t.Errorf("wrong calls for %s. expected=%s, got=%s", name, expected[name], fields[0])
+		}
+	}
+
+	if fields := strings.Fields(lines[1]); fields[len(fields)-1] != "main" || fields[2] != "100.0%" {
+		t.Errorf("main should come first with all the time. got=%q", lines[1])
+	}
+}
+
+func TestWriteCPU(t *testing.T) {
+	prof := run(t, false)
+
+	var out bytes.Buffer
+	if err := prof.WriteCPU(&out); err != nil {
+		t.Fatalf("WriteCPU error: %s", err)
+	}
+
+	fields := decode(t, out.Bytes())
+
+	for _, field := range []int{1, 4, 5, 6, 11, 12} {
+		if len(fields[field]) == 0 {
+			t.Errorf("profile is missing field %d", field)
+		}
+	}
+
+	strs := map[string]bool{}
+	for _, s := range fields[6] {
+		strs[string(s)] = true
+	}
+	for _, s := range []string{"", "samples", "count", "cpu", "nanoseconds", "main", "main.cx"} {
+		if !strs[s] {
+			t.Errorf("string table is missing %q", s)
+		}
+	}
+
+	if len(fields[2]) == 0 {
+		t.Errorf("profile has no samples")
+	}
+}
+
+func TestWriteAllocs(t *testing.T) {
+	prof := run(t, true)
+
+	var out bytes.Buffer
+	if err := prof.WriteAllocs(&out); err != nil {
+		t.Fatalf("WriteAllocs error: %s", err)
+	}
+
+	fields := decode(t, out.Bytes())
+
+	strs := map[string]bool{}
+	for _, s := range fields[6] {
+		strs[string(s)] = true
+	}
+	if !strs["alloc_objects"] || !strs["alloc_space"] {
+		t.Errorf("missing the allocation sample types")
+	}
+
+	if len(fields[2]) == 0 {
+		t.Errorf("profile has no samples")
+	}
+}
+
+// decode returns the length delimited fields of a gzipped protocol buffer
+// message by number, varint fields are skipped
+func decode(t *testing.T, data []byte) map[int][][]byte {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("not gzipped: %s", err)
+	}
+	raw, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("gzip error: %s", err)
+	}
+
+	varint := func() uint64 {
+		var x uint64
+		for shift := 0; ; shift += 7 {
+			if len(raw) == 0 {
+				t.Fatalf("truncated varint")
+			}
+			b := raw[0]
+			raw = raw[1:]
+			x |= uint64(b&0x7f) << shift
+			if b < 0x80 {
+				return x
+			}
+		}
+	}
+
+	fields := map[int][][]byte{}
+	for len(raw) > 0 {
+		key := varint()
+		field, wireType := int(key>>3), key&7
+
+		switch wireType {
+		case 0:
+			fields[field] = append(fields[field], nil)
+			varint()
+		case 2:
+			n := varint()
+			fields[field] = append(fields[field], raw[:n])
+			raw = raw[n:]
+		default:
+			t.Fatalf("unexpected wire type %d", wireType)
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,119 @@
+package repl
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/joshuahenriques/cixac/evaluator"
+	"github.com/joshuahenriques/cixac/object"
+	"github.com/joshuahenriques/cixac/token"
+)
+
+// methods are the builtin methods of each type, for completing after a dot
+var methods = map[object.ObjectType]map[string]object.Builtin{
+	object.ARRAY_OBJ:    object.ArrayBuiltins,
+	object.HASH_OBJ:     object.HashBuiltins,
+	object.STRING_OBJ:   object.StringBuiltins,
+	object.DECIMAL_OBJ:  object.DecimalBuiltins,
+	object.REGEX_OBJ:    object.RegexBuiltins,
+	object.TIME_OBJ:     object.TimeBuiltins,
+	object.DURATION_OBJ: object.DurationBuiltins,
+	object.RESPONSE_OBJ: object.ResponseBuiltins,
+	object.REQUEST_OBJ:  object.RequestBuiltins,
+}
+
+// completer completes commands, the names of the session and after a dot
+// the members of a module or the methods of a value
+type completer struct {
+	s *session
+}
+
+// Do implements readline.AutoCompleter, returning what each candidate adds
+// to the word before the cursor and the length of that word
+func (c *completer) Do(line []rune, pos int) ([][]rune, int) {
+	text := string(line[:pos])
+
+	var prefix string
+	var candidates []string
+
+	if strings.HasPrefix(text, ":") && !strings.Contains(text, " ") {
+		prefix, candidates = text, commands
+	} else {
+		start := len(text)
+		for start > 0 && isIdentRune(rune(text[start-1])) {
+			start--
+		}
+		prefix = text[start:]
+
+		if start > 0 && text[start-1] == '.' {
+			qualEnd := start - 1
+			qualStart := qualEnd
+			for qualStart > 0 && isIdentRune(rune(text[qualStart-1])) {
+				qualStart--
+			}
+			candidates = c.members(text[qualStart:qualEnd])
+		} else {
+			candidates = c.names()
+		}
+	}
+
+	var suffixes [][]rune
+	seen := map[string]bool{}
+	sort.Strings(candidates)
+
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) && candidate != prefix && !seen[candidate] {
+			seen[candidate] = true
+			suffixes = append(suffixes, []rune(candidate[len(prefix):]))
+		}
+	}
+
+	return suffixes, len([]rune(prefix))
+}
+
+func (c *completer) names() []string {
+	var names []string
+	for env := c.s.env; env != nil; env = env.Outer() {
+		for _, name := range env.Names() {
+			if !evaluator.IsInternalName(name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	names = append(names, evaluator.BuiltinNames()...)
+	names = append(names, evaluator.ModuleNames()...)
+	return append(names, token.Keywords()...)
+}
+
+// members completes after qualifier followed by a dot. When qualifier isn't
+// a variable or module, such as after a literal, any method may follow.
+func (c *completer) members(qualifier string) []string {
+	var names []string
+
+	if meta, ok := c.s.env.Get(qualifier); ok {
+		for name := range methods[meta.Object.Type()] {
+			names = append(names, name)
+		}
+		return names
+	}
+
+	if module, ok := evaluator.LookupModule(qualifier); ok {
+		for name := range module.Members {
+			names = append(names, name)
+		}
+		return names
+	}
+
+	for _, typ := range []object.ObjectType{object.ARRAY_OBJ, object.HASH_OBJ, object.STRING_OBJ} {
+		for name := range methods[typ] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,93 @@
+package repl
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+// width is how long a nested value may get before it is split over lines
+const width = 80
+
+// pretty formats a value the REPL prints. Arrays and hashes are formatted
+// with format, other values as they print in a program.
+func pretty(obj object.Object) string {
+	switch obj.(type) {
+	case *object.Array, *object.Hash:
+		return format(obj, 0, 0)
+	}
+	return obj.Inspect()
+}
+
+// format returns obj on one line when it fits the width from column,
+// otherwise with an element or pair per line indented a level deeper than
+// depth. Strings are quoted, hash keys sorted and functions left without
+// their bodies.
+func format(obj object.Object, depth, column int) string {
+	inline := formatInline(obj)
+	if column+len(inline) <= width {
+		return inline
+	}
+
+	indent := strings.Repeat("  ", depth)
+	pad := indent + "  "
+	var lines []string
+
+	switch obj := obj.(type) {
+	case *object.Array:
+		for _, elem := range obj.Elements {
+			lines = append(lines, pad+format(elem, depth+1, len(pad)))
+		}
+		return "[\n" + strings.Join(lines, ",\n") + "\n" + indent + "]"
+
+	case *object.Hash:
+		for _, pair := range sortedPairs(obj) {
+			key := formatInline(pair.Key) + ": "
+			lines = append(lines, pad+key+format(pair.Value, depth+1, len(pad)+len(key)))
+		}
+		return "{\n" + strings.Join(lines, ",\n") + "\n" + indent + "}"
+	}
+
+	return inline
+}
+
+func formatInline(obj object.Object) string {
+	switch obj := obj.(type) {
+	case *object.String:
+		return strconv.Quote(obj.Value)
+	case *object.Function:
+		params := make([]string, len(obj.Parameters))
+		for i, param := range obj.Parameters {
+			params[i] = param.Value
+		}
+		return "fn(" + strings.Join(params, ", ") + ")"
+	case *object.Array:
+		elements := make([]string, len(obj.Elements))
+		for i, elem := range obj.Elements {
+			elements[i] = formatInline(elem)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *object.Hash:
+		pairs := []string{}
+		for _, pair := range sortedPairs(obj) {
+			pairs = append(pairs, formatInline(pair.Key)+": "+formatInline(pair.Value))
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	}
+	return obj.Inspect()
+}
+
+func sortedPairs(hash *object.Hash) []object.HashPair {
+	pairs := make([]object.HashPair, 0, len(hash.Pairs))
+	for _, pair := range hash.Pairs {
+		pairs = append(pairs, pair)
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return formatInline(pairs[i].Key) < formatInline(pairs[j].Key)
+	})
+
+	return pairs
+}
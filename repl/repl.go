@@ -4,13 +4,11 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/chzyer/readline"
 	"github.com/joshuahenriques/cixac/evaluator"
-	"github.com/joshuahenriques/cixac/lexer"
-	"github.com/joshuahenriques/cixac/object"
-	"github.com/joshuahenriques/cixac/parser"
 )
 
 func filterInput(r rune) (rune, bool) {
@@ -22,10 +20,29 @@ func filterInput(r rune) (rune, bool) {
 	return r, true
 }
 
+// historyFile returns where the history is kept, in the user's config
+// directory. Without one the history isn't kept.
+func historyFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	dir = filepath.Join(dir, "cixac")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "history")
+}
+
 func Start(in io.Reader, out io.Writer) {
+	s := newSession(out)
+
 	l, err := readline.NewEx(&readline.Config{
 		Prompt:              "\033[31m»\033[0m ",
-		HistoryFile:         "/tmp/readline.tmp",
+		HistoryFile:         historyFile(),
+		AutoComplete:        &completer{s: s},
 		InterruptPrompt:     "^C",
 		EOFPrompt:           "exit",
 		HistorySearchFold:   true,
@@ -37,72 +54,69 @@ func Start(in io.Reader, out io.Writer) {
 	defer l.Close()
 	l.CaptureExitSignal()
 
-	env := object.NewEnvironment()
-
 	log.SetOutput(l.Stderr())
-
-	var multiLineBuffer strings.Builder
-	isMultiLine := false
+	evaluator.SetStdin(&readlineInput{l: l})
 
 	for {
-		var line string
-		var err error
-
-		if isMultiLine {
+		if s.pending() {
 			l.SetPrompt("... ")
 		} else {
 			l.SetPrompt("\033[31m»\033[0m ")
 		}
 
-		line, err = l.Readline()
-		line = strings.TrimSpace(line)
+		line, err := l.Readline()
 
 		if err == readline.ErrInterrupt {
-			if len(line) == 0 {
-				break
-			} else {
+			// ^C drops unfinished input and exits on an empty prompt
+			if s.pending() {
+				s.discard()
 				continue
 			}
+			if len(strings.TrimSpace(line)) == 0 {
+				break
+			}
+			continue
 		} else if err == io.EOF {
 			break
 		}
 
-		switch {
-		case line == "quit()":
+		if strings.TrimSpace(line) == "quit()" && !s.pending() {
 			os.Exit(0)
-		case strings.HasSuffix(line, `\`):
-			multiLineBuffer.WriteString(strings.TrimSuffix(line, `\`) + "\n")
-			isMultiLine = true
-			continue
-		}
-
-		if isMultiLine {
-			multiLineBuffer.WriteString(line + "\n")
-			line = multiLineBuffer.String()
-			multiLineBuffer.Reset()
-			isMultiLine = false
 		}
 
-		l := lexer.New(line)
-		p := parser.New(l)
+		s.input(line)
+	}
+}
 
-		program := p.ParseProgram()
+// readlineInput serves input() and the stdin module from the REPL's own
+// readline instance. An interrupt ends the input like EOF does.
+type readlineInput struct {
+	l *readline.Instance
+}
 
-		evaluated := evaluator.Eval(program, env)
-		if evaluated != nil && evaluated.Type() != object.EMPTY_OBJ {
-			io.WriteString(out, evaluated.Inspect())
-			io.WriteString(out, "\n")
-		}
+func (r *readlineInput) ReadLine(prompt string) (string, error) {
+	defer r.l.SetPrompt(r.l.Config.Prompt)
+	r.l.SetPrompt(prompt)
 
-		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
-			continue
-		}
+	line, err := r.l.Readline()
+	if err == readline.ErrInterrupt {
+		return "", io.EOF
 	}
+
+	return line, err
 }
 
-func printParserErrors(out io.Writer, errors []string) {
-	for _, msg := range errors {
-		io.WriteString(out, "\t"+msg+"\n")
+func (r *readlineInput) ReadAll() (string, error) {
+	var all strings.Builder
+
+	for {
+		line, err := r.ReadLine("")
+		if err == io.EOF {
+			return all.String(), nil
+		} else if err != nil {
+			return all.String(), err
+		}
+
+		all.WriteString(line + "\n")
 	}
 }
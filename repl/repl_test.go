@@ -0,0 +1,170 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/joshuahenriques/cixac/object"
+)
+
+func TestSession(t *testing.T) {
+	dir := t.TempDir()
+	lib := filepath.Join(dir, "lib.cx")
+	os.WriteFile(lib, []byte("fn double(x) { x * 2 }\nconst limit = 10"), 0644)
+	broken := filepath.Join(dir, "broken.cx")
+	os.WriteFile(broken, []byte("let = 1"), 0644)
+
+	tests := []struct {
+		lines    []string
+		expected string
+	}{
+		{[]string{"1 + 2"}, "3\n"},
+		{[]string{`"text"`}, "text\n"},
+		{[]string{"let a = 1", ":type a", ":type [a]", ":type 1 / 0"}, "INTEGER\nARRAY\nERROR: division by zero\n"},
+		{[]string{"let a = [1, \"b\"]", "const c = fn(x, y) { x }", ":env"}, "let a = [1, \"b\"]\nconst c = fn(x, y)\n"},
+		{[]string{":load " + lib, "double(limit)"}, "20\n"},
+		{[]string{":load " + broken}, "\t" + broken + ":1:5: error: expected next token to be IDENT, got = instead\n"},
+		{[]string{"let a = 1", ":reset", ":env", "a"}, "ERROR: Identifier not found: a\n"},
+		{[]string{":ast 1"}, "Program\n  Statements[0]: ExpressionStatement (1:1)\n    Expression: IntegerLiteral Value=1 (1:1)\n"},
+		{[]string{":ast"}, "usage: :ast EXPR\n"},
+		{[]string{":nope"}, "unknown command :nope, try :help\n"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		s := newSession(&out)
+
+		for _, line := range tt.lines {
+			if strings.HasPrefix(line, ":") {
+				s.command(line)
+			} else {
+				s.eval(line)
+			}
+		}
+
+		if out.String() != tt.expected {
+			t.Errorf("%q wrong output.\nexpected=%q\ngot=     %q", tt.lines, tt.expected, out.String())
+		}
+	}
+}
+
+func TestTime(t *testing.T) {
+	var out bytes.Buffer
+	newSession(&out).command(":time 2 * 21")
+
+	lines := strings.Split(out.String(), "\n")
+	if lines[0] != "42" || !strings.HasPrefix(lines[1], "took ") {
+		t.Errorf("wrong output. got=%q", out.String())
+	}
+}
+
+func TestPretty(t *testing.T) {
+	long := strings.Repeat("x", 70)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[1, "a", [true, null]]`, `[1, "a", [true, null]]`},
+		{`{"b": 2, "a": {"c": [1]}}`, `{"a": {"c": [1]}, "b": 2}`},
+		{`[fn(a) { a }]`, `[fn(a)]`},
+		{`[{}, []]`, `[{}, []]`},
+		{`{"name": "` + long + `", "tags": [1, 2]}`, `{
+  "name": "` + long + `",
+  "tags": [1, 2]
+}`},
+		{`[["` + long + `", 1], 2]`, `[
+  ["` + long + `", 1],
+  2
+]`},
+		{`[["` + long + long + `", 1], 2]`, `[
+  [
+    "` + long + long + `",
+    1
+  ],
+  2
+]`},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		newSession(&out).eval(tt.input)
+
+		if got := strings.TrimSuffix(out.String(), "\n"); got != tt.expected {
+			t.Errorf("%s wrong.\nexpected=%s\ngot=%s", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	s := newSession(&bytes.Buffer{})
+	s.eval(`let names = ["a"]; let nameCount = 1; let text = "abc"`)
+	c := &completer{s: s}
+
+	tests := []struct {
+		line     string
+		expected []string
+		length   int
+	}{
+		{"nam", []string{"eCount", "es"}, 3},
+		{"print(name", []string{"Count", "s"}, 4},
+		{":l", []string{"oad"}, 2},
+		{"names.pu", []string{"sh", "shleft"}, 2},
+		{"text.upp", []string{"er"}, 3},
+		{"math.sq", []string{"rt"}, 2},
+		{"names.sqr", nil, 3},
+		{"whi", []string{"le"}, 3},
+	}
+
+	for _, tt := range tests {
+		suffixes, length := c.Do([]rune(tt.line), len(tt.line))
+
+		var got []string
+		for _, suffix := range suffixes {
+			got = append(got, string(suffix))
+		}
+
+		if !reflect.DeepEqual(got, tt.expected) || length != tt.length {
+			t.Errorf("Do(%q) wrong. expected=%q %d, got=%q %d", tt.line, tt.expected, tt.length, got, length)
+		}
+	}
+
+	s.env = object.NewEnvironment()
+	if suffixes, _ := c.Do([]rune("nam"), 3); len(suffixes) != 0 {
+		t.Errorf("completed names after a reset")
+	}
+}
+
+func TestInput(t *testing.T) {
+	tests := []struct {
+		lines    []string
+		expected string
+		pending  bool
+	}{
+		{[]string{"fn add(a, b) {", "\treturn a + b", "}", "add(1, 2)"}, "3\n", false},
+		{[]string{"let total = 1 +", "2", "total"}, "3\n", false},
+		{[]string{"[1,", "2]"}, "[1, 2]\n", false},
+		{[]string{`let s = "a`, `b"`, "len(s)"}, "3\n", false},
+		{[]string{"1 + \\", "1"}, "2\n", false},
+		{[]string{"if (true) {", "let x = 1"}, "", true},
+		{[]string{"let = 1 {"}, "\t1:5: error: expected next token to be IDENT, got = instead\n", false},
+		{[]string{"fn f() {", "", "  1"}, "", true},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		s := newSession(&out)
+
+		for _, line := range tt.lines {
+			s.input(line)
+		}
+
+		if out.String() != tt.expected || s.pending() != tt.pending {
+			t.Errorf("%q wrong.\nexpected=%q pending=%t\ngot=     %q pending=%t", tt.lines, tt.expected, tt.pending, out.String(), s.pending())
+		}
+	}
+}
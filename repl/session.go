@@ -0,0 +1,201 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/evaluator"
+	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/object"
+	"github.com/joshuahenriques/cixac/parser"
+)
+
+const help = `Commands:
+  :help         print this help
+  :load FILE    run FILE in the session, keeping what it defines
+  :reset        forget everything defined in the session
+  :env          list the variables of the session
+  :type EXPR    print the type of the value of EXPR
+  :time EXPR    evaluate EXPR and print how long it took
+  :ast EXPR     print the syntax tree of EXPR
+Type quit() or press Ctrl-D to exit.
+`
+
+var commands = []string{":help", ":load", ":reset", ":env", ":type", ":time", ":ast"}
+
+// session holds the state of the REPL apart from the terminal
+type session struct {
+	env *object.Environment
+	out io.Writer
+
+	buffer strings.Builder // lines of input that don't parse on their own yet
+}
+
+func newSession(out io.Writer) *session {
+	return &session{env: object.NewEnvironment(), out: out}
+}
+
+// parse prints the diagnostics of code prefixed by name when it doesn't parse
+func (s *session) parse(name, code string) (*ast.Program, bool) {
+	p := parser.New(lexer.New(code))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		for _, d := range p.Diagnostics() {
+			io.WriteString(s.out, "\t"+name+d.String()+"\n")
+		}
+		return nil, false
+	}
+
+	return program, true
+}
+
+// input takes a line typed at the prompt. Lines are collected until they
+// form a complete program, which then runs. A line ending in a backslash
+// always asks for another.
+func (s *session) input(line string) {
+	trimmed := strings.TrimSpace(line)
+
+	if !s.pending() && strings.HasPrefix(trimmed, ":") {
+		s.command(trimmed)
+		return
+	}
+
+	if strings.HasSuffix(trimmed, `\`) {
+		s.buffer.WriteString(strings.TrimSuffix(trimmed, `\`) + "\n")
+		return
+	}
+
+	s.buffer.WriteString(line + "\n")
+	code := s.buffer.String()
+
+	p := parser.New(lexer.New(code))
+	p.ParseProgram()
+	if p.Incomplete() {
+		return
+	}
+
+	s.buffer.Reset()
+	s.eval(code)
+}
+
+// pending reports whether input is waiting for more lines
+func (s *session) pending() bool {
+	return s.buffer.Len() > 0
+}
+
+func (s *session) discard() {
+	s.buffer.Reset()
+}
+
+// eval runs code and prints its value
+func (s *session) eval(code string) {
+	program, ok := s.parse("", code)
+	if !ok {
+		return
+	}
+
+	s.print(evaluator.Eval(program, s.env))
+}
+
+func (s *session) print(obj object.Object) {
+	if obj != nil && obj.Type() != object.EMPTY_OBJ {
+		io.WriteString(s.out, pretty(obj)+"\n")
+	}
+}
+
+// command runs a line starting with a colon
+func (s *session) command(line string) {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	needsArg := name == ":load" || name == ":type" || name == ":time" || name == ":ast"
+	if needsArg && arg == "" {
+		usage := "EXPR"
+		if name == ":load" {
+			usage = "FILE"
+		}
+		fmt.Fprintf(s.out, "usage: %s %s\n", name, usage)
+		return
+	}
+
+	switch name {
+	case ":help":
+		io.WriteString(s.out, help)
+
+	case ":load":
+		src, err := os.ReadFile(arg)
+		if err != nil {
+			fmt.Fprintln(s.out, err)
+			return
+		}
+
+		program, ok := s.parse(arg+":", string(src))
+		if !ok {
+			return
+		}
+
+		result := evaluator.Eval(program, s.env)
+		if result != nil && result.Type() == object.ERROR_OBJ {
+			s.print(result)
+		}
+
+	case ":reset":
+		s.env = object.NewEnvironment()
+
+	case ":env":
+		for _, name := range s.env.Names() {
+			if evaluator.IsInternalName(name) {
+				continue
+			}
+
+			meta, _ := s.env.Get(name)
+			decl := "let"
+			if meta.Const {
+				decl = "const"
+			}
+			prefix := fmt.Sprintf("%s %s = ", decl, name)
+			fmt.Fprintln(s.out, prefix+format(meta.Object, 0, len(prefix)))
+		}
+
+	case ":type":
+		program, ok := s.parse("", arg)
+		if !ok {
+			return
+		}
+
+		result := evaluator.Eval(program, s.env)
+		if result == nil || result.Type() == object.ERROR_OBJ {
+			s.print(result)
+			return
+		}
+		fmt.Fprintln(s.out, result.Type())
+
+	case ":time":
+		program, ok := s.parse("", arg)
+		if !ok {
+			return
+		}
+
+		start := time.Now()
+		result := evaluator.Eval(program, s.env)
+		elapsed := time.Since(start)
+
+		s.print(result)
+		fmt.Fprintf(s.out, "took %s\n", elapsed.Round(time.Microsecond))
+
+	case ":ast":
+		program, ok := s.parse("", arg)
+		if !ok {
+			return
+		}
+		ast.Fprint(s.out, program)
+
+	default:
+		fmt.Fprintf(s.out, "unknown command %s, try :help\n", name)
+	}
+}
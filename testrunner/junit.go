@@ -0,0 +1,106 @@
+package testrunner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the suites as JUnit XML for CI servers. A file that
+// doesn't run is reported as a test case with an error named after the file.
+func WriteJUnit(w io.Writer, suites []Suite) error {
+	report := junitTestSuites{}
+
+	var total time.Duration
+	for _, suite := range suites {
+		js := junitTestSuite{Name: suite.File, Time: seconds(suite.Duration)}
+
+		if suite.Err != "" {
+			js.Cases = append(js.Cases, junitTestCase{
+				Name:      suite.File,
+				ClassName: suite.File,
+				Time:      seconds(suite.Duration),
+				Error:     &junitProblem{Message: suite.Err, Text: Position(suite.File, suite.Line, suite.Column) + suite.Err},
+			})
+			js.Errors++
+		}
+
+		for _, r := range suite.Results {
+			tc := junitTestCase{Name: r.Name, ClassName: suite.File, Time: seconds(r.Duration)}
+
+			problem := &junitProblem{Message: r.Message, Text: Position(suite.File, r.Line, r.Column) + r.Message}
+			switch r.Status {
+			case Fail:
+				tc.Failure = problem
+				js.Failures++
+			case Error:
+				tc.Error = problem
+				js.Errors++
+			}
+
+			js.Cases = append(js.Cases, tc)
+		}
+
+		js.Tests = len(js.Cases)
+		report.Tests += js.Tests
+		report.Failures += js.Failures
+		report.Errors += js.Errors
+		total += suite.Duration
+
+		report.Suites = append(report.Suites, js)
+	}
+	report.Time = seconds(total)
+
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, out)
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// Position prefixes a message with file:line:column, or just the file when
+// the line isn't known
+func Position(file string, line, column int) string {
+	if line == 0 {
+		return file + ": "
+	}
+	return fmt.Sprintf("%s:%d:%d: ", file, line, column)
+}
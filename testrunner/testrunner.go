@@ -0,0 +1,198 @@
+// Package testrunner runs the test_* functions of Cixac test files and
+// reports the results, as text or JUnit XML.
+package testrunner
+
+import (
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/evaluator"
+	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/object"
+	"github.com/joshuahenriques/cixac/parser"
+)
+
+const (
+	FileSuffix = "_test.cx"
+	TestPrefix = "test_"
+)
+
+type Status string
+
+const (
+	Pass Status = "PASS"
+	// Fail is a failed assertion
+	Fail Status = "FAIL"
+	// Error is any other error the test ran into
+	Error Status = "ERROR"
+)
+
+type Result struct {
+	Name     string
+	Status   Status
+	Message  string
+	Line     int // of the statement in the test function that failed
+	Column   int
+	Duration time.Duration
+}
+
+// Suite holds the results of the tests of a file. Err is set instead when the
+// file doesn't parse or its top level fails, then no test runs.
+type Suite struct {
+	File     string
+	Results  []Result
+	Err      string
+	Line     int
+	Column   int
+	Duration time.Duration
+}
+
+func (s *Suite) Failed() bool {
+	if s.Err != "" {
+		return true
+	}
+	for _, r := range s.Results {
+		if r.Status != Pass {
+			return true
+		}
+	}
+	return false
+}
+
+// Discover returns the test files under dir in lexical order
+func Discover(dir string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), FileSuffix) {
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	return files, err
+}
+
+// Run evaluates the top level of a test file once and then calls its test
+// functions in the order they are declared, so tests share the globals of
+// the file. Only tests whose name matches filter run, a nil filter runs all.
+func Run(file, src string, filter *regexp.Regexp) Suite {
+	start := time.Now()
+	suite := Suite{File: file}
+
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		d := p.Diagnostics()[0]
+		suite.Err, suite.Line, suite.Column = d.Message, d.Line, d.Column
+		suite.Duration = time.Since(start)
+		return suite
+	}
+
+	tracker := &positionTracker{}
+	evaluator.SetHook(tracker)
+	defer evaluator.SetHook(nil)
+
+	env := object.NewEnvironment()
+	if result := evaluator.Eval(program, env); result != nil && result.Type() == object.ERROR_OBJ {
+		suite.Err = result.(*object.Error).Message
+		suite.Line, suite.Column = tracker.line, tracker.column
+		suite.Duration = time.Since(start)
+		return suite
+	}
+
+	for _, name := range testNames(program) {
+		if filter != nil && !filter.MatchString(name) {
+			continue
+		}
+		suite.Results = append(suite.Results, runTest(name, env, tracker))
+	}
+
+	suite.Duration = time.Since(start)
+	return suite
+}
+
+func runTest(name string, env *object.Environment, tracker *positionTracker) Result {
+	start := time.Now()
+	result := Result{Name: name, Status: Pass}
+
+	if obj, ok := env.Get(name); ok {
+		if fn, ok := obj.Object.(*object.Function); ok && len(fn.Parameters) != 0 {
+			result.Status = Error
+			result.Message = "test functions take no arguments"
+			return result
+		}
+	}
+
+	// the hook follows the test function itself, so a failure is reported
+	// at the statement of the test that failed, not inside a helper
+	*tracker = positionTracker{depth: -1}
+
+	call := &ast.CallExpression{Function: &ast.Identifier{Value: name}}
+	evaluated := evaluator.Eval(call, env)
+	result.Duration = time.Since(start)
+
+	if err, ok := evaluated.(*object.Error); ok {
+		result.Status = Error
+		if evaluator.IsAssertionError(err) {
+			result.Status = Fail
+		}
+		result.Message = err.Message
+		result.Line, result.Column = tracker.line, tracker.column
+	}
+
+	return result
+}
+
+// testNames returns the test functions declared at the top level
+func testNames(program *ast.Program) []string {
+	var names []string
+
+	for _, stmt := range program.Statements {
+		var name string
+
+		switch stmt := stmt.(type) {
+		case *ast.FunctionDeclaration:
+			name = stmt.Name.Value
+		case *ast.LetStatement:
+			if _, ok := stmt.Value.(*ast.FunctionLiteral); ok {
+				name = stmt.Name.Value
+			}
+		}
+
+		if strings.HasPrefix(name, TestPrefix) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// positionTracker remembers the last statement run at depth 0, the top level
+// of the program or the function being tested
+type positionTracker struct {
+	depth  int
+	line   int
+	column int
+}
+
+func (t *positionTracker) Statement(stmt ast.Statement, env *object.Environment) {
+	if t.depth == 0 {
+		tok := ast.Start(stmt)
+		t.line, t.column = tok.Line, tok.Column
+	}
+}
+
+func (t *positionTracker) Call(fn *object.Function, env *object.Environment) { t.depth++ }
+func (t *positionTracker) Return(fn *object.Function)                        { t.depth-- }
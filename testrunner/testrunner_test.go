@@ -0,0 +1,152 @@
+package testrunner
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+const source = `fn double(x) { x * 2 }
+
+fn check(x) {
+	assert.equal(x, 1)
+}
+
+fn test_pass() {
+	assert.equal(double(2), 4)
+}
+
+fn test_fail() {
+	assert.equal(double(1), 2)
+	if (true) {
+		assert.equal(double(2), 5)
+	}
+}
+
+fn test_helper() {
+	check(2)
+}
+
+let test_error = fn() {
+	let x = 1 / 0
+}
+
+fn helper_not_a_test() { 1 / 0 }`
+
+func TestRun(t *testing.T) {
+	suite := Run("double_test.cx", source, nil)
+	if suite.Err != "" {
+		t.Fatalf("unexpected file error: %s", suite.Err)
+	}
+
+	expected := []Result{
+		{Name: "test_pass", Status: Pass},
+		{Name: "test_fail", Status: Fail, Message: "assert.equal failed: expected 5, got 4", Line: 14, Column: 3},
+		{Name: "test_helper", Status: Fail, Message: "assert.equal failed: expected 1, got 2", Line: 19, Column: 2},
+		{Name: "test_error", Status: Error, Message: "division by zero", Line: 23, Column: 2},
+	}
+
+	if len(suite.Results) != len(expected) {
+		t.Fatalf("wrong number of results. expected=%d, got=%+v", len(expected), suite.Results)
+	}
+
+	for i, r := range suite.Results {
+		r.Duration = 0
+		if !reflect.DeepEqual(r, expected[i]) {
+			t.Errorf("result %d wrong.\nexpected=%+v\ngot=     %+v", i, expected[i], r)
+		}
+	}
+
+	if !suite.Failed() {
+		t.Errorf("suite should have failed")
+	}
+}
+
+func TestRunFilter(t *testing.T) {
+	suite := Run("double_test.cx", source, regexp.MustCompile("pass|helper"))
+
+	var names []string
+	for _, r := range suite.Results {
+		names = append(names, r.Name)
+	}
+
+	if !reflect.DeepEqual(names, []string{"test_pass", "test_helper"}) {
+		t.Errorf("wrong tests ran. got=%v", names)
+	}
+}
+
+func TestRunFileErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+		line    int
+	}{
+		{"fn test_a() {\n\tlet x = (1\n}", "expected next token to be ), got } instead", 3},
+		{"let a = 1\nlet b = a + null\nfn test_a() {}", "type mismatch: INTEGER + NULL", 2},
+	}
+
+	for _, tt := range tests {
+		suite := Run("bad_test.cx", tt.input, nil)
+
+		if suite.Err != tt.message || suite.Line != tt.line {
+			t.Errorf("wrong file error. expected=%d: %q, got=%d: %q", tt.line, tt.message, suite.Line, suite.Err)
+		}
+		if len(suite.Results) != 0 || !suite.Failed() {
+			t.Errorf("tests ran after a file error")
+		}
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"a_test.cx", "a.cx", "sub/b_test.cx", ".git/c_test.cx"} {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+
+	files, err := Discover(dir)
+	if err != nil {
+		t.Fatalf("Discover error: %s", err)
+	}
+
+	expected := []string{filepath.Join(dir, "a_test.cx"), filepath.Join(dir, "sub/b_test.cx")}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("wrong files.\nexpected=%v\ngot=     %v", expected, files)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	suites := []Suite{
+		Run("double_test.cx", source, nil),
+		Run("bad_test.cx", "let x = (1", nil),
+	}
+
+	var out bytes.Buffer
+	if err := WriteJUnit(&out, suites); err != nil {
+		t.Fatalf("WriteJUnit error: %s", err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid XML: %s\n%s", err, out.String())
+	}
+
+	if report.Tests != 5 || report.Failures != 2 || report.Errors != 2 {
+		t.Errorf("wrong totals. got tests=%d failures=%d errors=%d", report.Tests, report.Failures, report.Errors)
+	}
+
+	failure := report.Suites[0].Cases[1].Failure
+	if failure == nil || failure.Text != "double_test.cx:14:3: assert.equal failed: expected 5, got 4" {
+		t.Errorf("wrong failure. got=%+v", failure)
+	}
+
+	if report.Suites[1].Cases[0].Error == nil {
+		t.Errorf("file that doesn't parse is not an error")
+	}
+}
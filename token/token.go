@@ -1,10 +1,22 @@
 package token
 
+import "sort"
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Line    int // 1-based line of the first character
+	Column  int // 1-based byte column of the first character
+}
+
+// Comment is trivia the lexer records next to the COMMENT and COMMENT_START
+// tokens, which carry only the comment markers
+type Comment struct {
+	Text   string // including the markers
+	Line   int
+	Column int
 }
 
 const (
@@ -12,10 +24,11 @@ const (
 	EOF     = "EOF"
 
 	// Identifiers + literals
-	IDENT  = "IDENT"
-	INT    = "INT"
-	FLOAT  = "FLOAT"
-	STRING = "STRING"
+	IDENT   = "IDENT"
+	INT     = "INT"
+	FLOAT   = "FLOAT"
+	DECIMAL = "DECIMAL"
+	STRING  = "STRING"
 
 	// Operators
 	ASSIGN     = "="
@@ -28,6 +41,7 @@ const (
 	MINUS    = "-"
 	BANG     = "!"
 	ASTERISK = "*"
+	POW      = "**"
 	SLASH    = "/"
 	MOD      = "%"
 
@@ -37,6 +51,10 @@ const (
 	AND = "&&"
 	OR  = "||"
 
+	ARROW     = "->"
+	FAT_ARROW = "=>"
+	PIPE      = "|"
+
 	LT     = "<"
 	LT_EQ  = "<="
 	GT     = ">"
@@ -46,6 +64,7 @@ const (
 
 	// Delimiters
 	PERIOD        = "."
+	ELLIPSIS      = "..."
 	COMMA         = ","
 	SEMICOLON     = ";"
 	COLON         = ":"
@@ -75,6 +94,9 @@ const (
 	CONTINUE = "CONTINUE"
 	WHILE    = "WHILE"
 	IN       = "IN"
+	DEFER    = "DEFER"
+	IS       = "IS"
+	MATCH    = "MATCH"
 )
 
 var keywords = map[string]TokenType{
@@ -92,6 +114,19 @@ var keywords = map[string]TokenType{
 	"continue": CONTINUE,
 	"while":    WHILE,
 	"in":       IN,
+	"defer":    DEFER,
+	"is":       IS,
+	"match":    MATCH,
+}
+
+func Keywords() []string {
+	names := make([]string, 0, len(keywords))
+	for name := range keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
 }
 
 func LookupIdent(ident string) TokenType {
@@ -0,0 +1,582 @@
+// Package typecheck checks the optional type annotations of Cixac programs.
+//
+// Checking is local: the type of an expression is inferred from literals,
+// operators, bindings and calls to functions with annotations, everything
+// else is any and fits anywhere. An unannotated variable keeps the type of
+// its value until it is assigned one of another type, so unannotated code
+// stays dynamically typed.
+package typecheck
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/joshuahenriques/cixac/ast"
+	"github.com/joshuahenriques/cixac/lexer"
+	"github.com/joshuahenriques/cixac/parser"
+	"github.com/joshuahenriques/cixac/token"
+)
+
+type Issue struct {
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%d:%d: %s", i.Line, i.Column, i.Message)
+}
+
+// Source checks a program, returning the mismatches sorted by position
+func Source(src string) ([]Issue, error) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+
+	if diagnostics := p.Diagnostics(); len(diagnostics) != 0 {
+		msgs := make([]string, len(diagnostics))
+		for i, d := range diagnostics {
+			msgs[i] = d.String()
+		}
+		return nil, errors.New(strings.Join(msgs, "\n"))
+	}
+
+	c := &checker{}
+	c.open()
+	c.statements(program.Statements)
+
+	sort.SliceStable(c.issues, func(i, j int) bool {
+		if c.issues[i].Line != c.issues[j].Line {
+			return c.issues[i].Line < c.issues[j].Line
+		}
+		return c.issues[i].Column < c.issues[j].Column
+	})
+
+	return c.issues, nil
+}
+
+type binding struct {
+	typ      *Type
+	declared bool // annotated, every assignment must fit the type
+	constant bool
+	depth    int // the number of functions around the declaration
+}
+
+// checker scopes follow the evaluator, where only functions, for loops and
+// match arms get their own environment
+type checker struct {
+	scopes  []map[string]*binding
+	depth   int
+	results []*Type // the annotated result of each enclosing function or nil
+	issues  []Issue
+}
+
+func (c *checker) report(tok token.Token, format string, a ...any) {
+	c.issues = append(c.issues, Issue{Message: fmt.Sprintf(format, a...), Line: tok.Line, Column: tok.Column})
+}
+
+func (c *checker) open() {
+	c.scopes = append(c.scopes, map[string]*binding{})
+}
+
+func (c *checker) close() {
+	c.scopes = c.scopes[:len(c.scopes)-1]
+}
+
+func (c *checker) declare(name *ast.Identifier, b *binding) {
+	if name == nil {
+		return
+	}
+	b.depth = c.depth
+	c.scopes[len(c.scopes)-1][name.Value] = b
+}
+
+func (c *checker) binding(name string) *binding {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if b, ok := c.scopes[i][name]; ok {
+			return b
+		}
+	}
+	return nil
+}
+
+// lookup returns the type of a variable. A function may run after an
+// unannotated variable around it is assigned anything, so there it is any.
+func (c *checker) lookup(name string) *Type {
+	b := c.binding(name)
+	if b == nil || (b.depth < c.depth && !b.declared && !b.constant) {
+		return Any
+	}
+	return b.typ
+}
+
+// assign reports a value of type from used where to is expected
+func (c *checker) assign(tok token.Token, to, from *Type, context string) {
+	if !assignable(to, from) {
+		c.report(tok, "cannot use %s as %s in %s", from, to, context)
+	}
+}
+
+func (c *checker) statements(stmts []ast.Statement) {
+	// functions may call the ones declared after them
+	for _, stmt := range stmts {
+		if decl, ok := stmt.(*ast.FunctionDeclaration); ok && decl.Function != nil {
+			c.declare(decl.Name, &binding{typ: c.signature(decl.Function), constant: true})
+		}
+	}
+
+	for _, stmt := range stmts {
+		c.statement(stmt)
+	}
+}
+
+func isNil(node ast.Node) bool {
+	if node == nil {
+		return true
+	}
+	v := reflect.ValueOf(node)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+func (c *checker) statement(stmt ast.Statement) {
+	if isNil(stmt) {
+		return
+	}
+
+	switch s := stmt.(type) {
+	case *ast.LetStatement:
+		value := c.expr(s.Value)
+		if s.Type == nil {
+			c.declare(s.Name, &binding{typ: value, constant: s.Name.Const})
+			return
+		}
+
+		typ := c.typeOf(s.Type)
+		c.assign(start(s.Value, s.Token), typ, value, "the value of "+s.Name.Value)
+		c.declare(s.Name, &binding{typ: typ, declared: true, constant: s.Name.Const})
+
+	case *ast.ReassignStatement:
+		value := c.expr(s.Value)
+		b := c.binding(s.Name.Value)
+		if b == nil {
+			return
+		}
+
+		if s.Token.Type != token.ASSIGN {
+			operator := strings.TrimSuffix(s.Token.Literal, "=")
+			value = c.binary(s.Token, operator, c.lookup(s.Name.Value), value)
+		}
+
+		if b.declared {
+			c.assign(start(s.Value, s.Token), b.typ, value, "the assignment to "+s.Name.Value)
+		} else if b.typ.String() != value.String() {
+			b.typ = Any
+		}
+
+	case *ast.ReturnStatement:
+		value := c.expr(s.ReturnValue)
+		if n := len(c.results); n != 0 && c.results[n-1] != nil {
+			c.assign(start(s.ReturnValue, s.Token), c.results[n-1], value, "the return value")
+		}
+
+	case *ast.ExpressionStatement:
+		c.expr(s.Expression)
+
+	case *ast.FunctionDeclaration:
+		c.function(s.Function)
+
+	case *ast.DeferStatement:
+		c.expr(s.Call)
+
+	case *ast.WhileStatement:
+		c.expr(s.Condition)
+		c.block(s.Body)
+
+	case *ast.ForLoopStatement:
+		c.open()
+		c.statement(s.Initialization)
+		c.expr(s.Condition)
+		switch update := s.Update.(type) {
+		case ast.Statement:
+			c.statement(update)
+		case ast.Expression:
+			c.expr(update)
+		}
+		c.block(s.Body)
+		c.close()
+
+	case *ast.ForInLoopStatement:
+		key, value := Any, Any
+		switch iterable := c.expr(s.Iterable); iterable.Name {
+		case "array":
+			key, value = Int, iterable.Elem
+		case "hash":
+			key, value = iterable.Key, iterable.Elem
+		case "string":
+			key, value = Int, String
+		}
+
+		c.open()
+		c.declare(s.KeyIndex, &binding{typ: key})
+		c.declare(s.ValueElement, &binding{typ: value})
+		c.block(s.Body)
+		c.close()
+
+	case *ast.BlockStatement:
+		c.block(s)
+	}
+}
+
+func (c *checker) block(block *ast.BlockStatement) {
+	if block != nil {
+		c.statements(block.Statements)
+	}
+}
+
+// start is the token a mismatch in expr is reported at
+func start(expr ast.Expression, fallback token.Token) token.Token {
+	if isNil(expr) {
+		return fallback
+	}
+
+	switch e := expr.(type) {
+	case *ast.InfixExpression:
+		return start(e.Left, e.Token)
+	case *ast.PostfixExpression:
+		return start(e.Left, e.Token)
+	case *ast.CallExpression:
+		return start(e.Function, e.Token)
+	case *ast.IndexExpression:
+		return start(e.Left, e.Token)
+	case *ast.BuiltinExpression:
+		return start(e.Left, e.Token)
+	case *ast.PropertyExpression:
+		return start(e.Left, e.Token)
+	}
+
+	field := reflect.Indirect(reflect.ValueOf(expr)).FieldByName("Token")
+	if !field.IsValid() {
+		return fallback
+	}
+	if tok, ok := field.Interface().(token.Token); ok {
+		return tok
+	}
+	return fallback
+}
+
+// signature is the type of a function literal. Only a function with an
+// annotation has a known signature, its unannotated parameters are any.
+// Unknown type names are left for function to report with the body.
+func (c *checker) signature(fn *ast.FunctionLiteral) *Type {
+	if fn.ParameterTypes == nil && fn.ReturnType == nil {
+		return &Type{Name: "fn"}
+	}
+
+	issues := len(c.issues)
+	defer func() { c.issues = c.issues[:issues] }()
+
+	typ := &Type{Name: "fn", Params: []*Type{}, Result: Any}
+	for i := range fn.Parameters {
+		param := Any
+		if i < len(fn.ParameterTypes) && fn.ParameterTypes[i] != nil {
+			param = c.typeOf(fn.ParameterTypes[i])
+		}
+		typ.Params = append(typ.Params, param)
+	}
+	if fn.ReturnType != nil {
+		typ.Result = c.typeOf(fn.ReturnType)
+	}
+
+	return typ
+}
+
+// function checks the body of fn and returns its type
+func (c *checker) function(fn *ast.FunctionLiteral) *Type {
+	typ := c.signature(fn)
+
+	c.depth++
+	c.open()
+
+	for i, param := range fn.Parameters {
+		b := &binding{typ: Any}
+		if i < len(fn.ParameterTypes) && fn.ParameterTypes[i] != nil {
+			b = &binding{typ: c.typeOf(fn.ParameterTypes[i]), declared: true}
+		}
+		c.declare(param, b)
+	}
+
+	var result *Type
+	if fn.ReturnType != nil {
+		result = c.typeOf(fn.ReturnType)
+	}
+	c.results = append(c.results, result)
+
+	if fn.Body != nil {
+		c.statements(fn.Body.Statements)
+
+		// the value of the last expression is returned too
+		if n := len(fn.Body.Statements); result != nil && n != 0 {
+			if last, ok := fn.Body.Statements[n-1].(*ast.ExpressionStatement); ok {
+				c.assign(start(last.Expression, last.Token), result, c.quiet(last.Expression), "the return value")
+			}
+		}
+	}
+
+	c.results = c.results[:len(c.results)-1]
+	c.close()
+	c.depth--
+
+	return typ
+}
+
+// quiet infers the type of an expression that was already checked
+func (c *checker) quiet(expr ast.Expression) *Type {
+	issues := len(c.issues)
+	typ := c.expr(expr)
+	c.issues = c.issues[:issues]
+	return typ
+}
+
+func (c *checker) expr(expr ast.Expression) *Type {
+	if isNil(expr) {
+		return Any
+	}
+
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral, *ast.BigIntegerLiteral:
+		return Int
+	case *ast.FloatLiteral:
+		return Float
+	case *ast.DecimalLiteral:
+		return Decimal
+	case *ast.StringLiteral:
+		return String
+	case *ast.Boolean:
+		return Bool
+	case *ast.Null:
+		return Null
+
+	case *ast.Identifier:
+		return c.lookup(e.Value)
+
+	case *ast.ArrayLiteral:
+		elements := make([]*Type, len(e.Elements))
+		for i, elem := range e.Elements {
+			elements[i] = c.expr(elem)
+		}
+		return &Type{Name: "array", Elem: join(elements)}
+
+	case *ast.HashLiteral:
+		var keys, values []*Type
+		for key, value := range e.Pairs {
+			keys = append(keys, c.expr(key))
+			values = append(values, c.expr(value))
+		}
+		return &Type{Name: "hash", Key: join(keys), Elem: join(values)}
+
+	case *ast.FunctionLiteral:
+		return c.function(e)
+
+	case *ast.PrefixExpression:
+		right := c.expr(e.Right)
+		if e.Operator == "!" {
+			return Bool
+		}
+		if right.numeric() || right.Name == "decimal" || !right.known() {
+			return right
+		}
+		c.report(e.Token, "invalid operation: %s%s", e.Operator, right)
+		return Any
+
+	case *ast.PostfixExpression:
+		left := c.expr(e.Left)
+		if left.numeric() || !left.known() {
+			return left
+		}
+		c.report(e.Token, "invalid operation: %s%s", left, e.Operator)
+		return Any
+
+	case *ast.InfixExpression:
+		return c.binary(e.Token, e.Operator, c.expr(e.Left), c.expr(e.Right))
+
+	case *ast.IsExpression:
+		c.expr(e.Left)
+		c.typeOf(e.Type)
+		return Bool
+
+	case *ast.IfExpression:
+		for _, cond := range e.Conditions {
+			c.expr(cond.Condition)
+			c.block(cond.Consequence)
+		}
+		c.block(e.Alternative)
+		return Any
+
+	case *ast.MatchExpression:
+		value := c.expr(e.Value)
+		for _, arm := range e.Arms {
+			c.open()
+			c.pattern(arm.Pattern, value)
+			c.expr(arm.Guard)
+			c.block(arm.Body)
+			c.close()
+		}
+		return Any
+
+	case *ast.CallExpression:
+		return c.call(e)
+
+	case *ast.IndexExpression:
+		left := c.expr(e.Left)
+		c.expr(e.Index)
+		switch left.Name {
+		case "array", "hash":
+			return left.Elem
+		case "string":
+			return String
+		}
+		return Any
+
+	case *ast.BuiltinExpression:
+		c.expr(e.Left)
+		if e.Builtin != nil {
+			for _, arg := range e.Builtin.Arguments {
+				c.expr(arg)
+			}
+		}
+		return Any
+
+	case *ast.PropertyExpression:
+		c.expr(e.Left)
+		return Any
+	}
+
+	return Any
+}
+
+// pattern declares the names a match pattern binds, typed by the type of
+// the value the pattern matches
+func (c *checker) pattern(pattern ast.Pattern, typ *Type) {
+	switch p := pattern.(type) {
+	case *ast.BindingPattern:
+		c.declare(p.Name, &binding{typ: typ})
+
+	case *ast.ArrayPattern:
+		elem := Any
+		if typ.Name == "array" {
+			elem = typ.Elem
+		}
+		for _, element := range p.Elements {
+			c.pattern(element, elem)
+		}
+		if p.Rest != nil {
+			c.pattern(p.Rest, &Type{Name: "array", Elem: elem})
+		}
+
+	case *ast.HashPattern:
+		value := Any
+		if typ.Name == "hash" {
+			value = typ.Elem
+		}
+		for _, v := range p.Values {
+			c.pattern(v, value)
+		}
+	}
+}
+
+// conversions are the results of the builtins converting between types
+var conversions = map[string]*Type{
+	"typeof": String,
+	"int":    Int,
+	"float":  Float,
+	"str":    String,
+	"bool":   Bool,
+	"array":  {Name: "array", Elem: Any},
+}
+
+func (c *checker) call(e *ast.CallExpression) *Type {
+	fn := c.expr(e.Function)
+
+	args := make([]*Type, len(e.Arguments))
+	for i, arg := range e.Arguments {
+		args[i] = c.expr(arg)
+	}
+
+	if ident, ok := e.Function.(*ast.Identifier); ok && c.binding(ident.Value) == nil {
+		if result, ok := conversions[ident.Value]; ok {
+			return result
+		}
+	}
+
+	if fn.Name != "fn" || fn.Params == nil {
+		return Any
+	}
+
+	name := e.Function.String()
+	if len(args) != len(fn.Params) {
+		c.report(e.Token, "%s takes %d arguments, got %d", name, len(fn.Params), len(args))
+		return fn.Result
+	}
+
+	for i, arg := range args {
+		c.assign(start(e.Arguments[i], e.Token), fn.Params[i], arg, fmt.Sprintf("argument %d of %s", i+1, name))
+	}
+
+	return fn.Result
+}
+
+// binary returns the type of left operator right, reporting operators that
+// fail on any values of the types
+func (c *checker) binary(tok token.Token, operator string, left, right *Type) *Type {
+	mixed := (left.Name == "decimal" && right.Name == "float") || (left.Name == "float" && right.Name == "decimal")
+
+	switch operator {
+	case "==", "!=", "&&", "||":
+		if mixed {
+			c.report(tok, "invalid operation: %s %s %s", left, operator, right)
+		}
+		return Bool
+	}
+
+	comparison := operator == "<" || operator == "<=" || operator == ">" || operator == ">="
+	result := func(t *Type) *Type {
+		if comparison {
+			return Bool
+		}
+		return t
+	}
+
+	if !left.known() || !right.known() {
+		return result(Any)
+	}
+
+	switch {
+	case left.Name == "int" && right.Name == "int":
+		// a negative power is a float
+		if operator == "**" {
+			return Any
+		}
+		return result(Int)
+	case left.numeric() && right.numeric():
+		return result(Float)
+	case !mixed && (left.Name == "decimal" || right.Name == "decimal") &&
+		(left.Name == "decimal" || left.Name == "int") && (right.Name == "decimal" || right.Name == "int"):
+		return result(Decimal)
+	case operator == "+" && (left.Name == "string" && concatenates(right) || right.Name == "string" && concatenates(left)):
+		return String
+	}
+
+	c.report(tok, "invalid operation: %s %s %s", left, operator, right)
+	return Any
+}
+
+// concatenates reports whether + joins a string and a value of t
+func concatenates(t *Type) bool {
+	switch t.Name {
+	case "string", "int", "float", "decimal", "bool", "array", "hash":
+		return true
+	}
+	return false
+}
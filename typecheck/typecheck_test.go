@@ -0,0 +1,83 @@
+package typecheck
+
+import "testing"
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x: int = 1", nil},
+		{"let x: int = \"one\"", []string{"1:14: cannot use string as int in the value of x"}},
+		{"let x: float = 1", nil},
+		{"let x: int = 1.5", []string{"1:14: cannot use float as int in the value of x"}},
+		{"let x: int | null = null", nil},
+		{"let x: bool | null = 1", []string{"1:22: cannot use int as bool | null in the value of x"}},
+		{"let xs: [string] = [\"a\", \"b\"]", nil},
+		{"let xs: [string] = [\"a\", 2]", []string{"1:20: cannot use [int | string] as [string] in the value of xs"}},
+		{"let xs: [string] = []", nil},
+		{"let h: {string: int} = {\"a\": 1}", nil},
+		{"let h: {string: int} = {\"a\": true}", []string{"1:24: cannot use {string: bool} as {string: int} in the value of h"}},
+		{"let x: int = 1; x = \"s\"", []string{"1:21: cannot use string as int in the assignment to x"}},
+		{"let x: int = 1; x += 1.5", []string{"1:22: cannot use float as int in the assignment to x"}},
+		{"let x = 1; x = \"s\"; let y: int = x", nil},
+		{"let x = \"s\"; let y: int = x", []string{"1:27: cannot use string as int in the value of y"}},
+		{"let x = \"s\"; let f = fn() { let y: int = x }", nil},
+		{"fn add(a: int, b: int) -> int { a + b }\nadd(1, \"2\")", []string{"2:8: cannot use string as int in argument 2 of add"}},
+		{"fn add(a: int, b: int) -> int { a + b }\nadd(1)", []string{"2:4: add takes 2 arguments, got 1"}},
+		{"fn add(a: int, b: int) -> int { a + b }\nlet s: string = add(1, 2)", []string{"2:17: cannot use int as string in the value of s"}},
+		{"let s: string = add(1, 2)\nfn add(a: int, b: int) -> int { a + b }", []string{"1:17: cannot use int as string in the value of s"}},
+		{"fn f(a, b) { a }\nf(1, 2, 3)", nil},
+		{"fn f() -> int { \"s\" }", []string{"1:17: cannot use string as int in the return value"}},
+		{"fn f(a) -> int { if (a) { return \"s\" }; 1 }", []string{"1:34: cannot use string as int in the return value"}},
+		{"let f: fn(int) -> string = fn(n: int) -> int { n }", []string{"1:28: cannot use fn(int) -> int as fn(int) -> string in the value of f"}},
+		{"let f: fn(int) -> int = fn(n: int) -> int { n }", nil},
+		{"let f: fn = fn(n) { n }", nil},
+		{"fn f(xs: [int]) { for (i, x in xs) { let s: string = x } }", []string{"1:54: cannot use int as string in the value of s"}},
+		{"let x: bogus = 1", []string{"1:8: unknown type bogus"}},
+		{"fn f(a: bogus) -> bogus { a }", []string{"1:9: unknown type bogus", "1:19: unknown type bogus"}},
+		{"1 + true", []string{"1:3: invalid operation: int + bool"}},
+		{"\"a\" + 1", nil},
+		{"\"a\" - 1", []string{"1:5: invalid operation: string - int"}},
+		{"1.5d + 1.5", []string{"1:6: invalid operation: decimal + float"}},
+		{"1.5d + 1", nil},
+		{"-\"a\"", []string{"1:1: invalid operation: -string"}},
+		{"fn f(a) { a + true }", nil},
+		{"let x: int = 1 + 2 * 3", nil},
+		{"let x: bool = 1 < 2.5", nil},
+		{"let b: bool = 1 is int | string", nil},
+		{"1 is bogus", []string{"1:6: unknown type bogus"}},
+		{"let n: int = int(\"12\"); let s: string = str(n); let t: string = typeof(n)", nil},
+		{"let n: int = str(1)", []string{"1:14: cannot use string as int in the value of n"}},
+		{"let xs: [int] = array(\"ab\")", nil},
+		{"let int = fn() { \"s\" }; let n: int = int()", nil},
+		{"let xs = [1, 2]; match xs { [first, ...rest] => { let s: string = first; let r: [int] = rest } }", []string{"1:67: cannot use int as string in the value of s"}},
+		{"let h = {\"a\": 1}; match h { {\"a\": a} if a > 1 => { let s: string = a } }", []string{"1:68: cannot use int as string in the value of s"}},
+		{"fn f(v) { match v { n => { let s: string = n } } }", nil},
+	}
+
+	for _, tt := range tests {
+		issues, err := Source(tt.input)
+		if err != nil {
+			t.Errorf("Source(%q) error: %s", tt.input, err)
+			continue
+		}
+
+		if len(issues) != len(tt.expected) {
+			t.Errorf("Source(%q) wrong number of issues. expected=%q, got=%v", tt.input, tt.expected, issues)
+			continue
+		}
+
+		for i, issue := range issues {
+			if issue.String() != tt.expected[i] {
+				t.Errorf("Source(%q) issue %d wrong. expected=%q, got=%q", tt.input, i, tt.expected[i], issue)
+			}
+		}
+	}
+}
+
+func TestSourceParseError(t *testing.T) {
+	if _, err := Source("let x: = 1"); err == nil {
+		t.Errorf("expected a parse error")
+	}
+}
@@ -0,0 +1,198 @@
+package typecheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/joshuahenriques/cixac/ast"
+)
+
+// Type is the static type of a value. Names are the ones used in
+// annotations, plus union for `a | b`.
+type Type struct {
+	Name   string
+	Elem   *Type   // the elements of an array, the values of a hash
+	Key    *Type   // the keys of a hash
+	Params []*Type // nil for a function whose signature isn't known
+	Result *Type   // the result of a function with a known signature
+	Types  []*Type // the members of a union
+}
+
+var (
+	Any     = &Type{Name: "any"}
+	Int     = &Type{Name: "int"}
+	Float   = &Type{Name: "float"}
+	Decimal = &Type{Name: "decimal"}
+	String  = &Type{Name: "string"}
+	Bool    = &Type{Name: "bool"}
+	Null    = &Type{Name: "null"}
+)
+
+// names are the types an annotation can name. array, hash and fn hold
+// anything.
+var names = map[string]*Type{
+	"any":      Any,
+	"int":      Int,
+	"float":    Float,
+	"decimal":  Decimal,
+	"string":   String,
+	"bool":     Bool,
+	"null":     Null,
+	"array":    {Name: "array", Elem: Any},
+	"hash":     {Name: "hash", Key: Any, Elem: Any},
+	"fn":       {Name: "fn"},
+	"time":     {Name: "time"},
+	"duration": {Name: "duration"},
+	"regex":    {Name: "regex"},
+	"module":   {Name: "module"},
+	"iterator": {Name: "iterator"},
+	"response": {Name: "response"},
+	"request":  {Name: "request"},
+}
+
+func (t *Type) String() string {
+	switch t.Name {
+	case "array":
+		return "[" + t.Elem.String() + "]"
+	case "hash":
+		return "{" + t.Key.String() + ": " + t.Elem.String() + "}"
+	case "fn":
+		if t.Params == nil {
+			return "fn"
+		}
+		params := make([]string, len(t.Params))
+		for i, param := range t.Params {
+			params[i] = param.String()
+		}
+		return fmt.Sprintf("fn(%s) -> %s", strings.Join(params, ", "), t.Result)
+	case "union":
+		types := make([]string, len(t.Types))
+		for i, member := range t.Types {
+			types[i] = member.String()
+		}
+		return strings.Join(types, " | ")
+	}
+	return t.Name
+}
+
+// known reports whether the checker knows which operators work on t
+func (t *Type) known() bool {
+	switch t.Name {
+	case "any", "union", "time", "duration", "regex", "module", "iterator", "response", "request":
+		return false
+	}
+	return true
+}
+
+func (t *Type) numeric() bool {
+	return t.Name == "int" || t.Name == "float"
+}
+
+// assignable reports whether a value of type from may be used where to is
+// expected. An int may be used as a float, and any goes both ways.
+func assignable(to, from *Type) bool {
+	switch {
+	case to.Name == "any" || from.Name == "any":
+		return true
+	case from.Name == "union":
+		for _, member := range from.Types {
+			if !assignable(to, member) {
+				return false
+			}
+		}
+		return true
+	case to.Name == "union":
+		for _, member := range to.Types {
+			if assignable(member, from) {
+				return true
+			}
+		}
+		return false
+	case to.Name == "float" && from.Name == "int":
+		return true
+	case to.Name != from.Name:
+		return false
+	}
+
+	switch to.Name {
+	case "array":
+		return assignable(to.Elem, from.Elem)
+	case "hash":
+		return assignable(to.Key, from.Key) && assignable(to.Elem, from.Elem)
+	case "fn":
+		if to.Params == nil || from.Params == nil {
+			return true
+		}
+		if len(to.Params) != len(from.Params) {
+			return false
+		}
+		for i := range to.Params {
+			if !assignable(from.Params[i], to.Params[i]) {
+				return false
+			}
+		}
+		return assignable(to.Result, from.Result)
+	}
+
+	return true
+}
+
+// join returns the type of a value of any of types, a union when they
+// differ and any when there are none
+func join(types []*Type) *Type {
+	var members []*Type
+	seen := map[string]bool{}
+
+	for _, t := range types {
+		if t.Name == "any" {
+			return Any
+		}
+		if !seen[t.String()] {
+			seen[t.String()] = true
+			members = append(members, t)
+		}
+	}
+
+	switch len(members) {
+	case 0:
+		return Any
+	case 1:
+		return members[0]
+	}
+
+	sort.Slice(members, func(i, j int) bool { return members[i].String() < members[j].String() })
+	return &Type{Name: "union", Types: members}
+}
+
+// typeOf converts an annotation, reporting names that aren't types
+func (c *checker) typeOf(expr ast.TypeExpr) *Type {
+	switch t := expr.(type) {
+	case *ast.NamedType:
+		if typ, ok := names[t.Name]; ok {
+			return typ
+		}
+		c.report(t.Token, "unknown type %s", t.Name)
+		return Any
+	case *ast.ArrayType:
+		return &Type{Name: "array", Elem: c.typeOf(t.Element)}
+	case *ast.HashType:
+		return &Type{Name: "hash", Key: c.typeOf(t.Key), Elem: c.typeOf(t.Value)}
+	case *ast.FunctionType:
+		fn := &Type{Name: "fn", Params: []*Type{}, Result: Any}
+		for _, param := range t.Parameters {
+			fn.Params = append(fn.Params, c.typeOf(param))
+		}
+		if t.Return != nil {
+			fn.Result = c.typeOf(t.Return)
+		}
+		return fn
+	case *ast.UnionType:
+		union := &Type{Name: "union"}
+		for _, member := range t.Types {
+			union.Types = append(union.Types, c.typeOf(member))
+		}
+		return union
+	}
+	return Any
+}